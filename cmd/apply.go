@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -8,12 +10,17 @@ import (
 
 	"github.com/Mirantis/launchpad/pkg/analytics"
 	"github.com/Mirantis/launchpad/pkg/config"
+	"github.com/Mirantis/launchpad/pkg/eventstream"
+	lpproduct "github.com/Mirantis/launchpad/pkg/product"
+	"github.com/Mirantis/launchpad/pkg/reconcile"
+	"github.com/Mirantis/launchpad/pkg/tracing"
 	"github.com/Mirantis/launchpad/pkg/util/logo"
 	"github.com/Mirantis/launchpad/version"
 	"github.com/mattn/go-isatty"
 	event "github.com/segmentio/analytics-go/v3"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var errInvalidArguments = errors.New("invalid arguments")
@@ -27,6 +34,14 @@ func NewApplyCommand() *cli.Command {
 			configFlag,
 			confirmFlag,
 			redactFlag,
+			outputFlag,
+			eventLogFlag,
+			phasesFlag,
+			skipPhasesFlag,
+			allowUnsafeSelectionFlag,
+			timeoutFlag,
+			traceEndpointFlag,
+			traceFileFlag,
 			&cli.IntFlag{
 				Name:  "concurrency",
 				Usage: "Worker upgrade concurrency (number of simultaneous nodes)",
@@ -49,14 +64,29 @@ func NewApplyCommand() *cli.Command {
 				Usage: "Disable printing of the Mirantis logo",
 				Value: false,
 			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only gather facts and print the actions apply would perform without changing anything",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "keep-going",
+				Usage: "Drop the hosts a phase fails on and carry on with the rest, then fail with a summary of the failed hosts",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "resume",
+				Usage: "Skip the phases that were completed by a previous failed run using the same configuration",
+				Value: false,
+			},
 			&cli.BoolFlag{
 				Name:  "force-upgrade",
 				Usage: "force upgrade to run on compatible components, even if it doesn't look necessary",
 				Value: false,
 			},
 		}...),
-		Before: actions(initLogger, startUpgradeCheck, initAnalytics, checkLicense, initExec),
-		After:  actions(closeAnalytics, upgradeCheckResult),
+		Before: actions(initLogger, startUpgradeCheck, initAnalytics, checkLicense, initExec, initEventStream, initTracing),
+		After:  actions(closeTracing, closeEventStream, closeAnalytics, upgradeCheckResult),
 		Action: func(ctx *cli.Context) (err error) {
 			if ctx.Int("concurrency") < 1 {
 				return fmt.Errorf("%w: invalid --concurrency %d (must be 1 or more)", errInvalidArguments, ctx.Int("concurrency"))
@@ -67,7 +97,7 @@ func NewApplyCommand() *cli.Command {
 			start := time.Now()
 			analytics.TrackEvent("Cluster Apply Started", nil)
 
-			product, err := config.ProductFromFile(ctx.String("config"))
+			product, err := config.ProductFromFiles(ctx.StringSlice("config"))
 			if err != nil {
 				return fmt.Errorf("failed to load product config: %w", err)
 			}
@@ -84,14 +114,48 @@ func NewApplyCommand() *cli.Command {
 				return fmt.Errorf("failed to add file logger: %w", err)
 			}
 
-			if isatty.IsTerminal(os.Stdout.Fd()) {
+			if isatty.IsTerminal(os.Stdout.Fd()) && ctx.String("output") != outputJSON {
 				if !ctx.Bool("disable-logo") {
 					os.Stdout.WriteString(logo.Logo)
 				}
 				fmt.Fprintf(os.Stdout, "   Mirantis Launchpad (c) 2024 Mirantis, Inc.                          %s\n\n", version.Version)
 			}
 
-			err = product.Apply(ctx.Bool("disable-cleanup"), ctx.Bool("force"), ctx.Int("concurrency"), ctx.Bool("force-upgrade"))
+			opts := lpproduct.ApplyOptions{
+				DisableCleanup:       ctx.Bool("disable-cleanup"),
+				Force:                ctx.Bool("force"),
+				Concurrency:          ctx.Int("concurrency"),
+				ForceUpgrade:         ctx.Bool("force-upgrade"),
+				Resume:               ctx.Bool("resume"),
+				Phases:               listFlag(ctx, "phases"),
+				SkipPhases:           listFlag(ctx, "skip-phases"),
+				KeepGoing:            ctx.Bool("keep-going"),
+				AllowUnsafeSelection: ctx.Bool("allow-unsafe-selection"),
+			}
+
+			runCtx, cancel := runContext(ctx)
+			defer cancel()
+
+			runCtx, span := tracing.Start(runCtx, "apply", attribute.String("cluster", product.ClusterName()))
+			defer func() { tracing.End(span, err) }()
+
+			if ctx.Bool("dry-run") {
+				return printPlan(runCtx, product, opts, ctx.String("output"))
+			}
+
+			lock, err := lockCluster(product.ClusterName())
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := lock.Release(); err != nil {
+					log.Warnf("%s", err)
+				}
+			}()
+
+			eventstream.Emit(eventstream.Event{Type: eventstream.TypeRunStart, Command: "apply"})
+			err = product.Apply(runCtx, opts)
+			eventstream.Emit(eventstream.Result(eventstream.Event{Type: eventstream.TypeRunFinish, Command: "apply", Duration: time.Since(start).Seconds()}, err))
 			if err != nil {
 				analytics.TrackEvent("Cluster Apply Failed", nil)
 				return fmt.Errorf("failed to apply cluster: %w", err)
@@ -107,3 +171,45 @@ func NewApplyCommand() *cli.Command {
 		},
 	}
 }
+
+func printPlan(ctx context.Context, product lpproduct.Product, opts lpproduct.ApplyOptions, output string) error {
+	plan, err := product.Plan(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to plan cluster apply: %w", err)
+	}
+
+	if output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(plan); err != nil {
+			return fmt.Errorf("failed to encode plan: %w", err)
+		}
+		return nil
+	}
+
+	if plan.Empty() {
+		fmt.Fprintln(os.Stdout, "No changes. The cluster matches the configuration.")
+		return nil
+	}
+
+	fmt.Fprintln(os.Stdout)
+	if err := plan.WriteTable(os.Stdout); err != nil {
+		return fmt.Errorf("failed to print plan: %w", err)
+	}
+	return nil
+}
+
+// lockCluster takes the lock of the cluster that reconcile holds, so that an apply
+// doesn't run at the same time as a reconcile that can apply the configuration, or as
+// another apply.
+func lockCluster(clusterName string) (*reconcile.Lock, error) {
+	path, err := reconcile.DefaultLockPath(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve lock path: %w", err)
+	}
+	lock, err := reconcile.AcquireLock(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock: %w", err)
+	}
+	return lock, nil
+}
@@ -35,7 +35,7 @@ func NewClientConfigCommand() *cli.Command {
 		Before: actions(initLogger, startUpgradeCheck, initAnalytics, checkLicense, initExec, deprecateUserPass),
 		After:  actions(closeAnalytics, upgradeCheckResult),
 		Action: func(ctx *cli.Context) error {
-			product, err := config.ProductFromFile(ctx.String("config"))
+			product, err := config.ProductFromFiles(ctx.StringSlice("config"))
 			if err != nil {
 				return fmt.Errorf("failed to read product configuration: %w", err)
 			}
@@ -1,15 +1,23 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Mirantis/launchpad/pkg/analytics"
 	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/eventstream"
 	mcclog "github.com/Mirantis/launchpad/pkg/log"
 	"github.com/Mirantis/launchpad/pkg/product/mke/phase"
+	"github.com/Mirantis/launchpad/pkg/tracing"
 	"github.com/Mirantis/launchpad/version"
 	"github.com/k0sproject/rig"
 	"github.com/k0sproject/rig/exec"
@@ -18,6 +26,11 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
 var (
 	debugFlag = &cli.BoolFlag{
 		Name:    "debug",
@@ -54,11 +67,11 @@ var (
 		EnvVars: []string{"DISABLE_UPGRADE_CHECK"},
 	}
 
-	configFlag = &cli.StringFlag{
+	configFlag = &cli.StringSliceFlag{
 		Name:      "config",
-		Usage:     "Path to cluster config yaml. Use '-' to read from stdin.",
+		Usage:     "Path to cluster config yaml. Use '-' to read from stdin. Can be given multiple times to merge overlays on top of the first file.",
 		Aliases:   []string{"c"},
-		Value:     "launchpad.yaml",
+		Value:     cli.NewStringSlice("launchpad.yaml"),
 		TakesFile: true,
 	}
 
@@ -74,6 +87,56 @@ var (
 		Value: false,
 	}
 
+	outputFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "Output format (text, json). With json, a stream of JSON events is written to stdout instead of the log output",
+		Value: outputText,
+		Action: func(_ *cli.Context, v string) error {
+			if v != outputText && v != outputJSON {
+				return fmt.Errorf("%w: invalid --output %q (must be %s or %s)", errInvalidArguments, v, outputText, outputJSON)
+			}
+			return nil
+		},
+	}
+
+	phasesFlag = &cli.StringSliceFlag{
+		Name:  "phases",
+		Usage: "Only run the phases with the given identifiers (for example LabelNodes)",
+	}
+
+	skipPhasesFlag = &cli.StringSliceFlag{
+		Name:  "skip-phases",
+		Usage: "Do not run the phases with the given identifiers (for example UpgradeMCR)",
+	}
+
+	allowUnsafeSelectionFlag = &cli.BoolFlag{
+		Name:  "allow-unsafe-selection",
+		Usage: "Allow a --phases or --skip-phases selection that leaves out phases the selected phases require",
+	}
+
+	timeoutFlag = &cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "Abort the run and clean up if it takes longer than the given duration (for example 1h30m)",
+	}
+
+	eventLogFlag = &cli.StringFlag{
+		Name:      "event-log",
+		Usage:     "Write a stream of JSON events to a file",
+		TakesFile: true,
+	}
+
+	traceEndpointFlag = &cli.StringFlag{
+		Name:    "trace-endpoint",
+		Usage:   "Send OpenTelemetry traces of the run to an OTLP/HTTP collector (for example http://localhost:4318)",
+		EnvVars: []string{"OTEL_EXPORTER_OTLP_ENDPOINT"},
+	}
+
+	traceFileFlag = &cli.StringFlag{
+		Name:      "trace-file",
+		Usage:     "Write OpenTelemetry traces of the run to a file as JSON",
+		TakesFile: true,
+	}
+
 	// GlobalFlags is a set of flags to be included in most commands.
 	GlobalFlags = []cli.Flag{
 		debugFlag,
@@ -84,8 +147,28 @@ var (
 	}
 
 	upgradeChan = make(chan *version.LaunchpadRelease)
+
+	eventLogFile *os.File
+
+	traceFile     *os.File
+	traceShutdown func(context.Context) error
 )
 
+// listFlag returns the values of a string slice flag split on commas. The app disables
+// the comma splitting of the slice flags so that a --config path can contain commas,
+// the flags that take lists of names split them here.
+func listFlag(ctx *cli.Context, name string) []string {
+	var values []string
+	for _, value := range ctx.StringSlice(name) {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
 // actions can be used to chain action functions (for urfave/cli's Before, After, etc).
 func actions(funcs ...func(*cli.Context) error) func(*cli.Context) error {
 	return func(ctx *cli.Context) error {
@@ -113,6 +196,19 @@ func startUpgradeCheck(ctx *cli.Context) error {
 }
 
 func initLogger(ctx *cli.Context) error {
+	if ctx.String("output") == outputJSON {
+		// keep stdout clean for the machine-readable output
+		return setupLogger(ctx, os.Stderr)
+	}
+	return setupLogger(ctx, os.Stdout)
+}
+
+// initStderrLogger is initLogger for the commands that write their result to stdout.
+func initStderrLogger(ctx *cli.Context) error {
+	return setupLogger(ctx, os.Stderr)
+}
+
+func setupLogger(ctx *cli.Context, out io.Writer) error {
 	mcclog.Debug = ctx.Bool("debug") || ctx.Bool("trace")
 	mcclog.Trace = ctx.Bool("trace")
 	log.SetLevel(log.TraceLevel)
@@ -121,24 +217,147 @@ func initLogger(ctx *cli.Context) error {
 	// Send logs with level >= INFO to stdout.
 
 	// stdout hook on by default of course.
-	log.AddHook(mcclog.NewStdoutHook())
+	stdoutHook := mcclog.NewStdoutHook()
+	stdoutHook.Writer = out
+	log.AddHook(stdoutHook)
 	rig.SetLogger(log.StandardLogger())
 
 	return nil
 }
 
+var (
+	errSignalReceived = errors.New("interrupted")
+	errTimeout        = errors.New("timeout exceeded")
+)
+
+// runContext returns a context for a run that is cancelled on SIGINT or SIGTERM or when
+// the --timeout has passed. A second signal terminates the process immediately.
+func runContext(ctx *cli.Context) (context.Context, context.CancelFunc) {
+	runCtx, cancel := context.WithCancelCause(ctx.Context)
+
+	cancelTimeout := context.CancelFunc(func() {})
+	if timeout := ctx.Duration("timeout"); timeout > 0 {
+		runCtx, cancelTimeout = context.WithTimeoutCause(runCtx, timeout, fmt.Errorf("%w: %s", errTimeout, timeout))
+	}
+
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-sigs:
+			// restore the default behavior so that another signal terminates the process
+			signal.Stop(sigs)
+			log.Warnf("received %s, stopping after cleaning up (repeat to exit immediately)", sig)
+			cancel(fmt.Errorf("%w: received %s", errSignalReceived, sig))
+		case <-done:
+		}
+	}()
+
+	return runCtx, func() {
+		signal.Stop(sigs)
+		close(done)
+		cancelTimeout()
+		cancel(nil)
+	}
+}
+
+func initEventStream(ctx *cli.Context) error {
+	var writers []io.Writer
+
+	// with --dry-run the stdout is reserved for the plan
+	if ctx.String("output") == outputJSON && !ctx.Bool("dry-run") {
+		writers = append(writers, os.Stdout)
+	}
+
+	if path := ctx.String("event-log"); path != "" {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open event log %s: %w", path, err)
+		}
+		eventLogFile = f
+		writers = append(writers, f)
+	}
+
+	if len(writers) > 0 {
+		eventstream.SetOutput(io.MultiWriter(writers...))
+	}
+	return nil
+}
+
+func closeEventStream(_ *cli.Context) error {
+	eventstream.SetOutput(nil)
+	if eventLogFile != nil {
+		if err := eventLogFile.Close(); err != nil {
+			log.Debugf("failed to close event log: %v", err)
+		}
+		eventLogFile = nil
+	}
+	return nil
+}
+
+func initTracing(ctx *cli.Context) error {
+	opts := tracing.Options{Endpoint: ctx.String("trace-endpoint")}
+
+	if path := ctx.String("trace-file"); path != "" {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open trace file %s: %w", path, err)
+		}
+		traceFile = f
+		opts.Writer = f
+	}
+
+	if opts.Endpoint == "" && opts.Writer == nil {
+		return nil
+	}
+
+	shutdown, err := tracing.Init(ctx.Context, opts)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	traceShutdown = shutdown
+	return nil
+}
+
+func closeTracing(_ *cli.Context) error {
+	if traceShutdown != nil {
+		// a fresh context so that the spans of an interrupted run are still exported
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := traceShutdown(shutdownCtx); err != nil {
+			log.Warnf("failed to export traces: %v", err)
+		}
+		traceShutdown = nil
+	}
+	if traceFile != nil {
+		if err := traceFile.Close(); err != nil {
+			log.Debugf("failed to close trace file: %v", err)
+		}
+		traceFile = nil
+	}
+	return nil
+}
+
 func initAnalytics(ctx *cli.Context) error {
 	if ctx.Bool("disable-telemetry") {
 		analytics.Enabled(false)
+		return nil
+	}
+	cfg, err := analytics.LoadSinkConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load telemetry settings: %w", err)
+	}
+	if err := analytics.Configure(cfg); err != nil {
+		return fmt.Errorf("failed to configure telemetry: %w", err)
 	}
 	return nil
 }
 
-func closeAnalytics(ctx *cli.Context) error {
-	if !ctx.Bool("disable-telemetry") {
-		if err := analytics.Close(); err != nil {
-			log.Debugf("Error while closing analytics client: %v", err)
-		}
+func closeAnalytics(_ *cli.Context) error {
+	if err := analytics.Close(); err != nil {
+		log.Debugf("Error while closing analytics client: %v", err)
 	}
 	return nil
 }
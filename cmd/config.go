@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Mirantis/launchpad/pkg/config"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	errInvalidConfig = errors.New("the configuration is not valid")
+	errBackupExists  = errors.New("backup file already exists")
+)
+
+// execInventoryFlag lets the offline config commands run the exec inventory sources.
+var execInventoryFlag = &cli.BoolFlag{
+	Name:  "exec-inventory",
+	Usage: "Run the commands of the exec sources in spec.inventory to read their hosts",
+	Value: false,
+}
+
+// NewConfigCommand creates the config command to be called from cli.
+func NewConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Work with cluster configuration files",
+		Subcommands: []*cli.Command{
+			newConfigValidateCommand(),
+			newConfigSchemaCommand(),
+			newConfigMigrateCommand(),
+			newConfigRenderCommand(),
+		},
+	}
+}
+
+func newConfigValidateCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "validate",
+		Usage:       "Validate a cluster configuration without connecting to the hosts",
+		Description: "Runs the configuration migrations, environment variable substitution and the validations that don't require a connection to the hosts. The fromCommand secret references are not run, neither are the exec inventory sources unless --exec-inventory is given.",
+		Flags:       []cli.Flag{configFlag, debugFlag, traceFlag, execInventoryFlag},
+		Before:      initLogger,
+		Action: func(ctx *cli.Context) error {
+			files := ctx.StringSlice("config")
+			file := strings.Join(files, "+")
+			err := config.ValidateFiles(files, config.OfflineOptions{ExecInventory: ctx.Bool("exec-inventory")})
+			if err == nil {
+				fmt.Fprintf(os.Stdout, "%s: the configuration is valid\n", file)
+				return nil
+			}
+
+			var validationErr *config.ValidationError
+			if !errors.As(err, &validationErr) {
+				return fmt.Errorf("failed to validate configuration: %w", err)
+			}
+
+			for _, p := range validationErr.Problems {
+				if p.Line > 0 {
+					fmt.Fprintf(os.Stdout, "%s:%d: ", file, p.Line)
+				} else {
+					fmt.Fprintf(os.Stdout, "%s: ", file)
+				}
+				if p.Path != "" {
+					fmt.Fprintf(os.Stdout, "%s: ", p.Path)
+				}
+				fmt.Fprintln(os.Stdout, p.Message)
+			}
+			return fmt.Errorf("%w: %d problem(s) found", errInvalidConfig, len(validationErr.Problems))
+		},
+	}
+}
+
+func newConfigSchemaCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "schema",
+		Usage:       "Print the JSON Schema of the cluster configuration",
+		Description: "Prints the JSON Schema of the current configuration apiVersion. Editors with YAML language support can use it for completion and inline validation of launchpad.yaml.",
+		Action: func(_ *cli.Context) error {
+			if _, err := os.Stdout.Write(mkeconfig.SchemaJSON()); err != nil {
+				return fmt.Errorf("failed to write schema: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+func newConfigMigrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "migrate",
+		Usage:       "Rewrite a cluster configuration in the current apiVersion format",
+		Description: "Runs the configuration migrations and prints the migrated configuration to stdout, or with --in-place, writes it back to the file and keeps the original as <file>.bak. Environment variables are not substituted. The key order and comments are kept for the parts of the configuration the migrations didn't replace.",
+		Flags: []cli.Flag{
+			configFlag,
+			debugFlag,
+			traceFlag,
+			&cli.BoolFlag{
+				Name:    "in-place",
+				Aliases: []string{"i"},
+				Usage:   "Write the migrated configuration back to the file, keeping a backup of the original",
+			},
+		},
+		Before: initStderrLogger,
+		Action: func(ctx *cli.Context) error {
+			files := ctx.StringSlice("config")
+			if len(files) != 1 {
+				return fmt.Errorf("%w: config migrate takes a single --config file", errInvalidArguments)
+			}
+			file := files[0]
+			inPlace := ctx.Bool("in-place")
+			if inPlace && file == "-" {
+				return fmt.Errorf("%w: --in-place can't be used when reading the configuration from stdin", errInvalidArguments)
+			}
+
+			result, err := config.MigrateFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to migrate configuration: %w", err)
+			}
+
+			summary := os.Stderr
+			if inPlace {
+				summary = os.Stdout
+			}
+
+			if len(result.Steps) == 0 {
+				fmt.Fprintf(summary, "%s: the configuration already is in the current format\n", file)
+			}
+			for _, step := range result.Steps {
+				fmt.Fprintf(summary, "%s -> %s:\n", step.From, step.To)
+				if len(step.Changes) == 0 {
+					fmt.Fprintln(summary, "  no changes")
+				}
+				for _, change := range step.Changes {
+					fmt.Fprintf(summary, "  %s\n", change)
+				}
+			}
+
+			if !inPlace {
+				if _, err := os.Stdout.Write(result.YAML); err != nil {
+					return fmt.Errorf("failed to write configuration: %w", err)
+				}
+				return nil
+			}
+
+			if len(result.Steps) == 0 {
+				return nil
+			}
+			return writeWithBackup(file, result.YAML)
+		},
+	}
+}
+
+// writeWithBackup renames the file to file.bak and writes the data to the file.
+func writeWithBackup(file string, data []byte) error {
+	stat, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", file, err)
+	}
+
+	backup := file + ".bak"
+	if _, err := os.Stat(backup); err == nil {
+		return fmt.Errorf("%w: %s", errBackupExists, backup)
+	}
+	if err := os.Rename(file, backup); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", file, err)
+	}
+	if err := os.WriteFile(file, data, stat.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	fmt.Fprintf(os.Stdout, "wrote %s, the original configuration was saved to %s\n", file, backup)
+	return nil
+}
+
+func newConfigRenderCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "render",
+		Usage:       "Print the cluster configuration as launchpad sees it",
+		Description: "Merges the --config files and the files they extend, runs the configuration migrations, substitutes the environment variables and prints the result with the credentials redacted. The secret references are printed as they are, without running their commands, and so are the exec inventory sources unless --exec-inventory is given.",
+		Flags:       []cli.Flag{configFlag, debugFlag, traceFlag, redactFlag, execInventoryFlag},
+		Before:      actions(initStderrLogger, initExec),
+		Action: func(ctx *cli.Context) error {
+			data, err := config.RenderFiles(ctx.StringSlice("config"), config.OfflineOptions{ExecInventory: ctx.Bool("exec-inventory")})
+			if err != nil {
+				return fmt.Errorf("failed to render configuration: %w", err)
+			}
+			if _, err := os.Stdout.Write(data); err != nil {
+				return fmt.Errorf("failed to write configuration: %w", err)
+			}
+			return nil
+		},
+	}
+}
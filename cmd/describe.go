@@ -55,7 +55,7 @@ func NewDescribeCommand() *cli.Command {
 			start := time.Now()
 			analytics.TrackEvent("Cluster Describe Started", nil)
 
-			product, err := config.ProductFromFile(ctx.String("config"))
+			product, err := config.ProductFromFiles(ctx.StringSlice("config"))
 			if err != nil {
 				return fmt.Errorf("failed to load product config: %w", err)
 			}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Mirantis/launchpad/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+// driftExitCode is the exit code of diff when the cluster doesn't match the configuration.
+const driftExitCode = 2
+
+// NewDiffCommand creates the diff command to be called from cli.
+func NewDiffCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "diff",
+		Usage:       "Show the differences between the configuration and the cluster",
+		Description: fmt.Sprintf("Gathers the facts of the hosts and the swarm without changing anything and lists what differs from the configuration: the MKE, MSR and MCR versions, the mcrConfig of the hosts and their daemon.json, the swarm roles and launchpad labels of the nodes, and the swarm nodes that are not in the configuration. Exits with %d when there are differences.", driftExitCode),
+		Flags: append(GlobalFlags, []cli.Flag{
+			configFlag,
+			redactFlag,
+			timeoutFlag,
+			&cli.StringFlag{
+				Name:   "output",
+				Usage:  "Output format of the differences (text, json)",
+				Value:  outputText,
+				Action: outputFlag.Action,
+			},
+		}...),
+		Before: actions(initStderrLogger, initExec),
+		Action: func(ctx *cli.Context) error {
+			product, err := config.ProductFromFiles(ctx.StringSlice("config"))
+			if err != nil {
+				return fmt.Errorf("failed to load product config: %w", err)
+			}
+
+			runCtx, cancel := runContext(ctx)
+			defer cancel()
+
+			report, err := product.Diff(runCtx)
+			if err != nil {
+				return fmt.Errorf("failed to diff cluster: %w", err)
+			}
+
+			if ctx.String("output") == outputJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return fmt.Errorf("failed to encode drift report: %w", err)
+				}
+			} else if report.Empty() {
+				fmt.Fprintln(os.Stdout, "No differences. The cluster matches the configuration.")
+			} else if err := report.WriteTable(os.Stdout); err != nil {
+				return fmt.Errorf("failed to print drift report: %w", err)
+			}
+
+			if !report.Empty() {
+				return cli.Exit(fmt.Sprintf("found %d differences between the configuration and the cluster", len(report.Drift)), driftExitCode)
+			}
+			return nil
+		},
+	}
+}
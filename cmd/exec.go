@@ -56,14 +56,14 @@ func NewExecCommand() *cli.Command {
 		}...),
 		Before: actions(initLogger, checkLicense, initExec),
 		Action: func(ctx *cli.Context) error {
-			product, err := config.ProductFromFile(ctx.String("config"))
+			product, err := config.ProductFromFiles(ctx.StringSlice("config"))
 			if err != nil {
 				return fmt.Errorf("failed to load product configuration: %w", err)
 			}
 
 			args := ctx.Args().Slice()
 
-			err = product.Exec(ctx.StringSlice("target"), ctx.Bool("interactive"), ctx.Bool("first"), ctx.Bool("all"), ctx.Bool("parallel"), ctx.String("role"), ctx.String("os"), shellquote.Join(args...))
+			err = product.Exec(listFlag(ctx, "target"), ctx.Bool("interactive"), ctx.Bool("first"), ctx.Bool("all"), ctx.Bool("parallel"), ctx.String("role"), ctx.String("os"), shellquote.Join(args...))
 			if err != nil {
 				return fmt.Errorf("failed to execute command: %w", err)
 			}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Mirantis/launchpad/pkg/cmd/importcluster"
+	"github.com/Mirantis/launchpad/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+var errMissingFrom = errors.New("the address of a manager is required, use --from")
+
+// NewImportCommand creates the import command to be called from cli.
+func NewImportCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "import",
+		Usage:       "Generate a configuration for an existing cluster",
+		Description: "Connects to a manager of a cluster that was not installed with launchpad and prints a configuration that reproduces it: the hosts and their roles from the swarm, the MKE, MSR and MCR versions, and the MKE --san install flags. The other Linux nodes are connected with the same SSH settings to find the MSR replicas and to read their daemon.json. Redirect stdout to save the configuration, for example: launchpad import --from 10.0.0.1 > launchpad.yaml",
+		Flags: append(GlobalFlags, []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Address of a manager of the cluster",
+			},
+			&cli.StringFlag{
+				Name:  "name",
+				Usage: "Name of the cluster in the configuration",
+				Value: "imported-mke-cluster",
+			},
+			&cli.StringFlag{
+				Name:  "ssh-user",
+				Usage: "SSH user for the hosts",
+				Value: "root",
+			},
+			&cli.IntFlag{
+				Name:  "ssh-port",
+				Usage: "SSH port of the hosts",
+				Value: 22,
+			},
+			&cli.StringFlag{
+				Name:  "ssh-key",
+				Usage: "SSH private key path, the SSH agent and the default keys are used when not given",
+			},
+		}...),
+		Before: actions(initStderrLogger, initExec),
+		Action: func(ctx *cli.Context) error {
+			if ctx.String("from") == "" {
+				return errMissingFrom
+			}
+
+			cfg, err := importcluster.Import(importcluster.Options{
+				Address:    ctx.String("from"),
+				Name:       ctx.String("name"),
+				SSHUser:    ctx.String("ssh-user"),
+				SSHPort:    ctx.Int("ssh-port"),
+				SSHKeyPath: ctx.String("ssh-key"),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to import cluster: %w", err)
+			}
+
+			data, err := yaml.Marshal(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to marshal configuration: %w", err)
+			}
+			if err := config.Validate(data); err != nil {
+				log.Warnf("the generated configuration needs to be completed by hand: %s", err)
+			}
+			log.Warn("set spec.mke.adminUsername and spec.mke.adminPassword before running apply")
+
+			if _, err := os.Stdout.Write(data); err != nil {
+				return fmt.Errorf("failed to write configuration: %w", err)
+			}
+			return nil
+		},
+	}
+}
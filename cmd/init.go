@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Mirantis/launchpad/pkg/cmd/initconfig"
+	"github.com/Mirantis/launchpad/pkg/config"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// NewInitCommand creates the init command to be called from cli.
+func NewInitCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "init",
+		Usage:       "Print a starter cluster configuration",
+		Description: "Prints an example configuration for the given kind. With --interactive, asks for the hosts, versions and credentials, checks that the versions exist and prints a configuration that passes validation. The questions are written to stderr, redirect stdout to save the configuration, for example: launchpad init -i > launchpad.yaml",
+		Flags: []cli.Flag{
+			debugFlag,
+			traceFlag,
+			&cli.StringFlag{
+				Name:  "kind",
+				Usage: "Cluster kind (mke or mke+msr), asked in interactive mode when not given",
+			},
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "Ask for the configuration values",
+			},
+		},
+		Before: initStderrLogger,
+		Action: func(ctx *cli.Context) error {
+			kind := ctx.String("kind")
+			if kind != "" && kind != "mke" && kind != "mke+msr" {
+				return fmt.Errorf("%w: invalid --kind %q (must be mke or mke+msr)", errInvalidArguments, kind)
+			}
+
+			if ctx.Bool("interactive") {
+				data, err := initconfig.Run(kind)
+				if err != nil {
+					return fmt.Errorf("failed to create configuration: %w", err)
+				}
+				if _, err := os.Stdout.Write(data); err != nil {
+					return fmt.Errorf("failed to write configuration: %w", err)
+				}
+				return nil
+			}
+
+			if kind == "" {
+				kind = "mke"
+			}
+			cfg, err := config.Init(kind)
+			if err != nil {
+				return fmt.Errorf("failed to create configuration: %w", err)
+			}
+			if err := yaml.NewEncoder(os.Stdout).Encode(cfg); err != nil {
+				return fmt.Errorf("failed to encode configuration: %w", err)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/analytics"
+	"github.com/Mirantis/launchpad/pkg/config"
+	"github.com/Mirantis/launchpad/pkg/phase"
+	lpproduct "github.com/Mirantis/launchpad/pkg/product"
+	event "github.com/segmentio/analytics-go/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// NewOSUpdateCommand creates the os-update command to be called from cli.
+func NewOSUpdateCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "os-update",
+		Usage:       "Upgrade the OS packages of the hosts of a cluster one by one",
+		Description: "Drains the swarm node of each host, upgrades the OS packages with apt, yum, zypper or Windows Update, reboots the host when the OS requires it, waits for the container runtime and activates the node again. The MCR packages are held back unless --include-mcr is given. The hosts are updated in the same order as with reboot: the managers one at a time with the swarm managers and MKE healthy before the next one, the MSR replicas one at a time and the workers in batches. Prints the changed packages of each host and whether it was rebooted.",
+		Flags: append(GlobalFlags, []cli.Flag{
+			configFlag,
+			confirmFlag,
+			redactFlag,
+			timeoutFlag,
+			&cli.StringFlag{
+				Name:   "output",
+				Usage:  "Output format of the update report (text, json)",
+				Value:  outputText,
+				Action: outputFlag.Action,
+			},
+			&cli.StringSliceFlag{
+				Name:    "role",
+				Usage:   "Update only the hosts with this role (manager, worker, msr) (can be given multiple times)",
+				Aliases: []string{"r"},
+			},
+			&cli.StringSliceFlag{
+				Name:  "hosts",
+				Usage: "Update only the hosts with these addresses (can be given multiple times)",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Number of worker nodes updated at a time",
+				Value: 5,
+			},
+			&cli.BoolFlag{
+				Name:  "include-mcr",
+				Usage: "Upgrade the MCR packages along with the other packages",
+				Value: false,
+			},
+		}...),
+		Before: actions(initLogger, initAnalytics, checkLicense, initExec),
+		After:  actions(closeAnalytics),
+		Action: func(ctx *cli.Context) error {
+			if ctx.Int("concurrency") < 1 {
+				return fmt.Errorf("%w: invalid --concurrency %d (must be 1 or more)", errInvalidArguments, ctx.Int("concurrency"))
+			}
+			for _, role := range listFlag(ctx, "role") {
+				if role != "manager" && role != "worker" && role != "msr" {
+					return fmt.Errorf("%w: invalid --role %s (must be manager, worker or msr)", errInvalidArguments, role)
+				}
+			}
+
+			start := time.Now()
+			analytics.TrackEvent("Cluster OS Update Started", nil)
+			product, err := config.ProductFromFiles(ctx.StringSlice("config"))
+			if err != nil {
+				return fmt.Errorf("failed to load product config: %w", err)
+			}
+
+			runCtx, cancel := runContext(ctx)
+			defer cancel()
+
+			report, err := product.OSUpdate(runCtx, lpproduct.OSUpdateOptions{
+				Roles:       listFlag(ctx, "role"),
+				Hosts:       listFlag(ctx, "hosts"),
+				Concurrency: ctx.Int("concurrency"),
+				IncludeMCR:  ctx.Bool("include-mcr"),
+			})
+			if report != nil {
+				if printErr := printOSUpdateReport(ctx.String("output"), report); printErr != nil {
+					return printErr
+				}
+			}
+			if err != nil {
+				analytics.TrackEvent("Cluster OS Update Failed", nil)
+				return fmt.Errorf("failed to update OS packages: %w", err)
+			}
+
+			duration := time.Since(start)
+			analytics.TrackEvent("Cluster OS Update Completed", event.Properties{"duration": duration.Seconds()})
+			log.Infof("OS packages updated in %s", duration.Round(time.Second))
+			return nil
+		},
+	}
+}
+
+func printOSUpdateReport(output string, report *phase.OSUpdateReport) error {
+	if output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode os update report: %w", err)
+		}
+		return nil
+	}
+	if err := report.WriteTable(os.Stdout); err != nil {
+		return fmt.Errorf("failed to print os update report: %w", err)
+	}
+	return nil
+}
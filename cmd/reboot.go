@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/analytics"
+	"github.com/Mirantis/launchpad/pkg/config"
+	lpproduct "github.com/Mirantis/launchpad/pkg/product"
+	event "github.com/segmentio/analytics-go/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// NewRebootCommand creates the reboot command to be called from cli.
+func NewRebootCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "reboot",
+		Usage:       "Reboot the hosts of a cluster one by one",
+		Description: "Drains the swarm node of each host, reboots the host, waits for the container runtime and activates the node again. The managers are rebooted one at a time and the swarm managers and MKE must be healthy before the next one, the MSR replicas are rebooted one at a time and the workers in batches.",
+		Flags: append(GlobalFlags, []cli.Flag{
+			configFlag,
+			confirmFlag,
+			redactFlag,
+			timeoutFlag,
+			&cli.StringSliceFlag{
+				Name:    "role",
+				Usage:   "Reboot only the hosts with this role (manager, worker, msr) (can be given multiple times)",
+				Aliases: []string{"r"},
+			},
+			&cli.StringSliceFlag{
+				Name:  "hosts",
+				Usage: "Reboot only the hosts with these addresses (can be given multiple times)",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Number of worker nodes rebooted at a time",
+				Value: 5,
+			},
+		}...),
+		Before: actions(initLogger, initAnalytics, checkLicense, initExec),
+		After:  actions(closeAnalytics),
+		Action: func(ctx *cli.Context) error {
+			if ctx.Int("concurrency") < 1 {
+				return fmt.Errorf("%w: invalid --concurrency %d (must be 1 or more)", errInvalidArguments, ctx.Int("concurrency"))
+			}
+			for _, role := range listFlag(ctx, "role") {
+				if role != "manager" && role != "worker" && role != "msr" {
+					return fmt.Errorf("%w: invalid --role %s (must be manager, worker or msr)", errInvalidArguments, role)
+				}
+			}
+
+			start := time.Now()
+			analytics.TrackEvent("Cluster Reboot Started", nil)
+			product, err := config.ProductFromFiles(ctx.StringSlice("config"))
+			if err != nil {
+				return fmt.Errorf("failed to load product config: %w", err)
+			}
+
+			runCtx, cancel := runContext(ctx)
+			defer cancel()
+
+			err = product.Reboot(runCtx, lpproduct.RebootOptions{
+				Roles:       listFlag(ctx, "role"),
+				Hosts:       listFlag(ctx, "hosts"),
+				Concurrency: ctx.Int("concurrency"),
+			})
+			if err != nil {
+				analytics.TrackEvent("Cluster Reboot Failed", nil)
+				return fmt.Errorf("failed to reboot cluster: %w", err)
+			}
+
+			duration := time.Since(start)
+			analytics.TrackEvent("Cluster Reboot Completed", event.Properties{"duration": duration.Seconds()})
+			log.Infof("hosts rebooted in %s", duration.Round(time.Second))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/config"
+	lpproduct "github.com/Mirantis/launchpad/pkg/product"
+	"github.com/Mirantis/launchpad/pkg/reconcile"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// NewReconcileCommand creates the reconcile command to be called from cli.
+func NewReconcileCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "reconcile",
+		Usage:       "Keep a cluster in line with its configuration",
+		Description: "Runs until interrupted. The --config files are merged like with apply, a directory stands for its .yaml and .yml files in the order of their names, and they are loaded again for each run. On each interval, and when the configuration files change, the cluster is compared to the configuration like launchpad diff does. The differences are only reported unless --auto-apply is given, in which case the configuration is applied when the differences are within the --allow-roles, --max-hosts and --allow-cluster-changes limits and the configuration has only changed within the same limits since the cluster was last in sync with it. The result of the last run is served as JSON on --status-address.",
+		Flags: append(GlobalFlags, []cli.Flag{
+			configFlag,
+			redactFlag,
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Time between the runs",
+				Value: 10 * time.Minute,
+			},
+			&cli.DurationFlag{
+				Name:  "poll-interval",
+				Usage: "How often the configuration files are checked for changes, 0 to only run on the interval",
+				Value: 10 * time.Second,
+			},
+			&cli.DurationFlag{
+				Name:  "max-backoff",
+				Usage: "Longest time between the runs after failures, the interval is doubled after each failed run",
+				Value: time.Hour,
+			},
+			&cli.BoolFlag{
+				Name:  "auto-apply",
+				Usage: "Apply the configuration when the differences are within the limits",
+			},
+			&cli.StringSliceFlag{
+				Name:  "allow-roles",
+				Usage: "Roles of the hosts whose differences can be applied",
+				Value: cli.NewStringSlice("worker"),
+			},
+			&cli.IntFlag{
+				Name:  "max-hosts",
+				Usage: "Largest number of hosts with differences that are applied at once, 0 for no limit",
+			},
+			&cli.BoolFlag{
+				Name:  "allow-cluster-changes",
+				Usage: "Apply differences that are not specific to a host, such as the MKE version",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Worker upgrade concurrency (number of simultaneous nodes)",
+				Value: 5,
+			},
+			&cli.StringFlag{
+				Name:  "status-address",
+				Usage: "Address of the HTTP status endpoint, empty to disable it",
+				Value: "127.0.0.1:9090",
+			},
+			&cli.StringFlag{
+				Name:  "lock-file",
+				Usage: "Lock file that prevents running two reconciles, or a reconcile and an apply, for the cluster (default: ~/.mirantis-launchpad/cluster/<name>/reconcile.lock)",
+			},
+		}...),
+		Before: actions(initLogger, initAnalytics, checkLicense, initExec),
+		After:  actions(closeAnalytics),
+		Action: func(ctx *cli.Context) error {
+			if ctx.Duration("interval") <= 0 {
+				return fmt.Errorf("%w: invalid --interval %s (must be more than 0)", errInvalidArguments, ctx.Duration("interval"))
+			}
+			if ctx.Int("concurrency") < 1 {
+				return fmt.Errorf("%w: invalid --concurrency %d (must be 1 or more)", errInvalidArguments, ctx.Int("concurrency"))
+			}
+
+			r := &reconcile.Reconciler{
+				Paths:        ctx.StringSlice("config"),
+				Load:         config.ProductFromFiles,
+				Render:       mergeConfigFiles,
+				Interval:     ctx.Duration("interval"),
+				PollInterval: ctx.Duration("poll-interval"),
+				MaxBackoff:   ctx.Duration("max-backoff"),
+				AutoApply:    ctx.Bool("auto-apply"),
+				Limits: reconcile.Limits{
+					Roles:          listFlag(ctx, "allow-roles"),
+					MaxHosts:       ctx.Int("max-hosts"),
+					ClusterChanges: ctx.Bool("allow-cluster-changes"),
+				},
+				ApplyOptions: lpproduct.ApplyOptions{Concurrency: ctx.Int("concurrency")},
+			}
+
+			lockPath := ctx.String("lock-file")
+			if lockPath == "" {
+				// the lock is per cluster, so the configuration has to load at start
+				name, err := reconcileClusterName(r)
+				if err != nil {
+					return err
+				}
+				if lockPath, err = reconcile.DefaultLockPath(name); err != nil {
+					return fmt.Errorf("failed to resolve lock path: %w", err)
+				}
+			} else {
+				log.Warnf("apply takes the default lock of the cluster, with --lock-file it is not kept from running during the reconcile")
+			}
+			lock, err := reconcile.AcquireLock(lockPath)
+			if err != nil {
+				return fmt.Errorf("failed to lock: %w", err)
+			}
+			defer func() {
+				if err := lock.Release(); err != nil {
+					log.Warnf("%s", err)
+				}
+			}()
+
+			runCtx, cancel := runContext(ctx)
+			defer cancel()
+
+			errs := make(chan error, 1)
+			if address := ctx.String("status-address"); address != "" {
+				listener, err := net.Listen("tcp", address)
+				if err != nil {
+					return fmt.Errorf("failed to listen on %s: %w", address, err)
+				}
+				go func() { errs <- r.Serve(runCtx, listener) }()
+			}
+
+			if err := r.Run(runCtx); err != nil {
+				return fmt.Errorf("reconcile failed: %w", err)
+			}
+			if ctx.String("status-address") != "" {
+				return <-errs
+			}
+			return nil
+		},
+	}
+}
+
+// mergeConfigFiles merges the configuration files for the reconciler without running
+// the secret reference and inventory commands.
+func mergeConfigFiles(paths []string) ([]byte, error) {
+	return config.MergeFiles(paths, config.OfflineOptions{}) //nolint:wrapcheck // wrapped by the reconciler
+}
+
+// reconcileClusterName loads the configuration to find the name of the cluster.
+func reconcileClusterName(r *reconcile.Reconciler) (string, error) {
+	files, _, err := r.ConfigFiles()
+	if err != nil {
+		return "", err //nolint:wrapcheck // already wrapped
+	}
+	product, err := r.Load(files)
+	if err != nil {
+		return "", fmt.Errorf("failed to load product config: %w", err)
+	}
+	return product.ClusterName(), nil
+}
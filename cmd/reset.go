@@ -9,9 +9,13 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/Mirantis/launchpad/pkg/analytics"
 	"github.com/Mirantis/launchpad/pkg/config"
+	"github.com/Mirantis/launchpad/pkg/eventstream"
+	lpproduct "github.com/Mirantis/launchpad/pkg/product"
+	"github.com/Mirantis/launchpad/pkg/tracing"
 	"github.com/mattn/go-isatty"
 	event "github.com/segmentio/analytics-go/v3"
 	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // NewResetCommand creates new reset command to be called from cli.
@@ -23,23 +27,43 @@ func NewResetCommand() *cli.Command {
 			configFlag,
 			confirmFlag,
 			redactFlag,
+			outputFlag,
+			eventLogFlag,
+			phasesFlag,
+			skipPhasesFlag,
+			allowUnsafeSelectionFlag,
+			timeoutFlag,
+			traceEndpointFlag,
+			traceFileFlag,
 			&cli.BoolFlag{
 				Name:    "force",
 				Usage:   "Don't ask for confirmation",
 				Aliases: []string{"f"},
 			},
 		}...),
-		Before: actions(initLogger, initAnalytics, checkLicense, initExec, requireForce),
-		After:  actions(closeAnalytics),
-		Action: func(ctx *cli.Context) error {
+		Before: actions(initLogger, initAnalytics, checkLicense, initExec, requireForce, initEventStream, initTracing),
+		After:  actions(closeTracing, closeEventStream, closeAnalytics),
+		Action: func(ctx *cli.Context) (err error) {
 			start := time.Now()
 			analytics.TrackEvent("Cluster Reset Started", nil)
-			product, err := config.ProductFromFile(ctx.String("config"))
+			product, err := config.ProductFromFiles(ctx.StringSlice("config"))
 			if err != nil {
 				return fmt.Errorf("failed to load product config: %w", err)
 			}
 
-			err = product.Reset()
+			runCtx, cancel := runContext(ctx)
+			defer cancel()
+
+			runCtx, span := tracing.Start(runCtx, "reset", attribute.String("cluster", product.ClusterName()))
+			defer func() { tracing.End(span, err) }()
+
+			eventstream.Emit(eventstream.Event{Type: eventstream.TypeRunStart, Command: "reset"})
+			err = product.Reset(runCtx, lpproduct.ResetOptions{
+				Phases:               listFlag(ctx, "phases"),
+				SkipPhases:           listFlag(ctx, "skip-phases"),
+				AllowUnsafeSelection: ctx.Bool("allow-unsafe-selection"),
+			})
+			eventstream.Emit(eventstream.Result(eventstream.Event{Type: eventstream.TypeRunFinish, Command: "reset", Duration: time.Since(start).Seconds()}, err))
 			if err != nil {
 				analytics.TrackEvent("Cluster Reset Failed", nil)
 				return fmt.Errorf("failed to reset cluster: %w", err)
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Mirantis/launchpad/pkg/analytics"
+	"github.com/urfave/cli/v2"
+)
+
+// NewTelemetryCommand creates the telemetry command to be called from cli.
+func NewTelemetryCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "telemetry",
+		Usage: "Inspect the telemetry (usage data) launchpad collects",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "show",
+				Usage:  "Print the telemetry messages of the last run",
+				Flags:  []cli.Flag{debugFlag, traceFlag},
+				Before: initLogger,
+				Action: func(_ *cli.Context) error {
+					lastRun, err := analytics.ReadLastRun()
+					if err != nil {
+						if errors.Is(err, analytics.ErrNoLastRun) {
+							fmt.Fprintln(os.Stdout, "No telemetry has been recorded yet.")
+							return nil
+						}
+						return fmt.Errorf("failed to read telemetry: %w", err)
+					}
+
+					if lastRun.Enabled {
+						fmt.Fprintf(os.Stdout, "The last run (%s) sent %d messages to the %s sink:\n", lastRun.Time.Format("2006-01-02 15:04:05"), len(lastRun.Messages), lastRun.Sink)
+					} else {
+						fmt.Fprintf(os.Stdout, "Telemetry was disabled for the last run (%s). These %d messages would have been sent:\n", lastRun.Time.Format("2006-01-02 15:04:05"), len(lastRun.Messages))
+					}
+
+					payload, err := lastRun.Payload()
+					if err != nil {
+						return fmt.Errorf("failed to build telemetry payload: %w", err)
+					}
+					if lastRun.Sink == analytics.SinkFile {
+						// the file sink writes a compact message per line
+						fmt.Fprintf(os.Stdout, "\n%s", payload)
+						return nil
+					}
+					var buf bytes.Buffer
+					if err := json.Indent(&buf, payload, "", "  "); err != nil {
+						return fmt.Errorf("failed to format telemetry payload: %w", err)
+					}
+					fmt.Fprintf(os.Stdout, "\n%s\n", buf.String())
+					return nil
+				},
+			},
+		},
+	}
+}
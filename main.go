@@ -81,14 +81,24 @@ SUPPORT:
 		Usage: "Mirantis Launchpad",
 
 		EnableBashCompletion: true,
+		// --config paths can contain commas, the flags taking lists split them with listFlag
+		DisableSliceFlagSeparator: true,
 		Commands: []*cli.Command{
+			cmd.NewInitCommand(),
+			cmd.NewImportCommand(),
 			cmd.NewApplyCommand(),
+			cmd.NewDiffCommand(),
+			cmd.NewReconcileCommand(),
 			cmd.RegisterCommand(),
 			cmd.NewDescribeCommand(),
 			cmd.NewClientConfigCommand(),
 			cmd.NewExecCommand(),
 			cmd.NewResetCommand(),
+			cmd.NewRebootCommand(),
+			cmd.NewOSUpdateCommand(),
 			cmd.NewDownloadLaunchpadCommand(),
+			cmd.NewConfigCommand(),
+			cmd.NewTelemetryCommand(),
 			completionCmd,
 			versionCmd,
 		},
@@ -1,14 +1,22 @@
 package analytics
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	logger "log"
+	"os"
+	"path/filepath"
 	"runtime"
+	"sync"
+	"time"
 
 	"github.com/Mirantis/launchpad/pkg/config/user"
 	"github.com/Mirantis/launchpad/version"
 	"github.com/denisbrodbeck/machineid"
+	"github.com/google/uuid"
 	analytics "github.com/segmentio/analytics-go/v3"
 	log "github.com/sirupsen/logrus"
 )
@@ -27,6 +35,47 @@ type Analytics interface {
 type Client struct {
 	IsEnabled       bool
 	AnalyticsClient Analytics
+	// Sink is the name of the sink AnalyticsClient sends to.
+	Sink string
+
+	mu       sync.Mutex
+	recorded []json.RawMessage
+}
+
+// LastRun records the analytics messages of the last launchpad run, whether or not
+// they were sent, so that they can be reviewed with `launchpad telemetry show`.
+type LastRun struct {
+	Time     time.Time         `json:"time"`
+	Sink     string            `json:"sink"`
+	Enabled  bool              `json:"enabled"`
+	Messages []json.RawMessage `json:"messages"`
+}
+
+const lastRunFile = "telemetry-last-run.json"
+
+// Payload returns the request body the sink of the run receives for the recorded
+// messages: a Segment batch, the {"batch": [...]} of the webhook sink or the lines
+// of the file sink. The Segment batch is also shown when no sink was used, as it
+// is the default.
+func (r *LastRun) Payload() ([]byte, error) {
+	switch r.Sink {
+	case SinkFile:
+		var buf bytes.Buffer
+		for _, msg := range r.Messages {
+			buf.Write(msg)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	case SinkWebhook:
+		return marshalPayload(map[string][]json.RawMessage{"batch": r.Messages})
+	default:
+		return marshalPayload(segmentBatch{
+			MessageID: uuid.NewString(),
+			SentAt:    r.Time,
+			Messages:  r.Messages,
+			Context:   segmentContext(),
+		})
+	}
 }
 
 var defaultClient Client
@@ -43,6 +92,7 @@ func init() {
 	}
 
 	defaultClient.AnalyticsClient = ac
+	defaultClient.Sink = SinkSegment
 	defaultClient.IsEnabled = true
 }
 
@@ -60,13 +110,9 @@ func NewSegmentClient(segmentToken string) (Analytics, error) { //nolint:ireturn
 	return client, nil
 }
 
-// TrackEvent uploads the given event to segment if analytics tracking
+// TrackEvent sends the given event to the analytics sink if analytics tracking
 // is enabled.
 func (c *Client) TrackEvent(event string, properties analytics.Properties) error {
-	if !c.IsEnabled {
-		return nil
-	}
-
 	if properties == nil {
 		properties = analytics.NewProperties()
 	}
@@ -75,38 +121,63 @@ func (c *Client) TrackEvent(event string, properties analytics.Properties) error
 	properties["os"] = runtime.GOOS
 	properties["version"] = version.Version
 
-	if err := c.AnalyticsClient.Enqueue(analytics.Track{
+	return c.enqueue(analytics.Track{
 		UserId:      UserID(),
 		AnonymousId: MachineID(),
 		Event:       event,
+		Timestamp:   time.Now(),
 		Properties:  properties,
-	}); err != nil {
-		return fmt.Errorf("failed to enqueue analytics message: %w", err)
-	}
-	return nil
+	})
 }
 
 // IdentifyUser identifies user on analytics service if analytics
 // is enabled.
 func (c *Client) IdentifyUser(userConfig *user.Config) error {
-	if !c.IsEnabled {
-		return nil
-	}
 	msg := analytics.Identify{
 		AnonymousId: MachineID(),
 		UserId:      userConfig.Email,
+		Timestamp:   time.Now(),
 		Traits: analytics.NewTraits().
 			SetName(userConfig.Name).
 			SetEmail(userConfig.Email).
 			Set("company", userConfig.Company),
 	}
 	log.Debugf("identified analytics user %+v", msg)
+	return c.enqueue(msg)
+}
+
+// enqueue records the message for the last run and sends it if analytics is enabled.
+// The message id and timestamp are set before, the Segment client keeps them, so the
+// recorded message is the one the sink receives.
+func (c *Client) enqueue(msg analytics.Message) error {
+	msg = stampMessage(msg)
+	if data, err := marshalMessage(msg); err == nil {
+		c.mu.Lock()
+		c.recorded = append(c.recorded, data)
+		c.mu.Unlock()
+	}
+
+	if !c.IsEnabled || c.AnalyticsClient == nil {
+		return nil
+	}
 	if err := c.AnalyticsClient.Enqueue(msg); err != nil {
 		return fmt.Errorf("failed to enqueue analytics message: %w", err)
 	}
 	return nil
 }
 
+// LastRun returns the messages recorded by the client.
+func (c *Client) LastRun() *LastRun {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &LastRun{
+		Time:     time.Now(),
+		Sink:     c.Sink,
+		Enabled:  c.IsEnabled && c.AnalyticsClient != nil,
+		Messages: append([]json.RawMessage(nil), c.recorded...),
+	}
+}
+
 // TrackEvent uses the default analytics client to track an event.
 func TrackEvent(event string, properties map[string]interface{}) {
 	if err := defaultClient.TrackEvent(event, properties); err != nil {
@@ -124,21 +195,89 @@ func RequireRegisteredUser() error {
 	return defaultClient.RequireRegisteredUser()
 }
 
-// Close closes the default analytics client.
-func Close() error {
+// Configure replaces the sink of the default client.
+func Configure(cfg SinkConfig) error {
+	if cfg.Sink == "" {
+		cfg.Sink = SinkSegment
+	}
+	client, err := NewSink(cfg)
+	if err != nil {
+		return err
+	}
 	if defaultClient.AnalyticsClient != nil {
 		if err := defaultClient.AnalyticsClient.Close(); err != nil {
-			return fmt.Errorf("failed to close analytics client: %w", err)
+			log.Debugf("failed to close analytics client: %v", err)
 		}
 	}
+	defaultClient.AnalyticsClient = client
+	defaultClient.Sink = cfg.Sink
+	defaultClient.IsEnabled = client != nil
 	return nil
 }
 
+// Close saves the messages of the run for `launchpad telemetry show` and closes the
+// default analytics client.
+func Close() error {
+	var errs []error
+	if lastRun := defaultClient.LastRun(); len(lastRun.Messages) > 0 {
+		if err := saveLastRun(lastRun); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if defaultClient.AnalyticsClient != nil {
+		if err := defaultClient.AnalyticsClient.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close analytics client: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Enabled enables the default client.
 func Enabled(enabled bool) {
 	defaultClient.IsEnabled = enabled
 }
 
+func saveLastRun(lastRun *LastRun) error {
+	path, err := stateFile(lastRunFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(lastRun, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics messages: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save analytics messages: %w", err)
+	}
+	return nil
+}
+
+// ErrNoLastRun is returned by ReadLastRun when no run has recorded analytics messages.
+var ErrNoLastRun = errors.New("no analytics messages have been recorded yet")
+
+// ReadLastRun returns the analytics messages of the last launchpad run.
+func ReadLastRun() (*LastRun, error) {
+	path, err := stateFile(lastRunFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoLastRun
+		}
+		return nil, fmt.Errorf("failed to read analytics messages: %w", err)
+	}
+	lastRun := &LastRun{}
+	if err := json.Unmarshal(data, lastRun); err != nil {
+		return nil, fmt.Errorf("failed to decode analytics messages: %w", err)
+	}
+	return lastRun, nil
+}
+
 // MachineID hashes a machine id as an anonymized identifier for our
 // analytics events.
 func MachineID() string {
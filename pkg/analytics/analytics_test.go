@@ -1,6 +1,7 @@
 package analytics
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/Mirantis/launchpad/pkg/config/user"
@@ -113,3 +114,66 @@ func TestDefaultClient(t *testing.T) {
 		RequireRegisteredUser()
 	})
 }
+
+func TestLastRun(t *testing.T) {
+	client := &mockClient{}
+	analyticsClient := Client{
+		AnalyticsClient: client,
+		Sink:            SinkFile,
+	}
+
+	analyticsClient.TrackEvent("test", nil)
+	lastRun := analyticsClient.LastRun()
+	require.False(t, lastRun.Enabled)
+	require.Equal(t, SinkFile, lastRun.Sink)
+	require.Len(t, lastRun.Messages, 1)
+	require.Nil(t, client.lastMessage)
+	require.Contains(t, string(lastRun.Messages[0]), `"event":"test"`)
+}
+
+func TestLastRunPayload(t *testing.T) {
+	client := &mockClient{}
+	analyticsClient := Client{
+		IsEnabled:       true,
+		AnalyticsClient: client,
+		Sink:            SinkSegment,
+	}
+
+	analyticsClient.TrackEvent("test", nil)
+	sent, ok := client.lastMessage.(analytics.Track)
+	require.True(t, ok)
+	require.NotEmpty(t, sent.MessageId)
+
+	lastRun := analyticsClient.LastRun()
+	payload, err := lastRun.Payload()
+	require.NoError(t, err)
+
+	var batch struct {
+		MessageID string            `json:"messageId"`
+		SentAt    string            `json:"sentAt"`
+		Batch     []json.RawMessage `json:"batch"`
+		Context   struct {
+			Library struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"library"`
+		} `json:"context"`
+	}
+	require.NoError(t, json.Unmarshal(payload, &batch))
+	require.NotEmpty(t, batch.MessageID)
+	require.NotEmpty(t, batch.SentAt)
+	require.Equal(t, "analytics-go", batch.Context.Library.Name)
+	require.Equal(t, analytics.Version, batch.Context.Library.Version)
+	require.Len(t, batch.Batch, 1)
+	require.Contains(t, string(batch.Batch[0]), `"messageId":"`+sent.MessageId+`"`)
+
+	lastRun.Sink = SinkWebhook
+	payload, err = lastRun.Payload()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"batch": [`+string(lastRun.Messages[0])+`]}`, string(payload))
+
+	lastRun.Sink = SinkFile
+	payload, err = lastRun.Payload()
+	require.NoError(t, err)
+	require.Equal(t, string(lastRun.Messages[0])+"\n", string(payload))
+}
@@ -0,0 +1,302 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/google/uuid"
+	"github.com/mitchellh/go-homedir"
+	analytics "github.com/segmentio/analytics-go/v3"
+	"gopkg.in/yaml.v2"
+)
+
+// Sink types.
+const (
+	SinkSegment = "segment"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+	SinkNone    = "none"
+)
+
+// SinkConfig selects where the analytics events are sent. It is read from
+// ~/.mirantis-launchpad/telemetry.yaml and the LAUNCHPAD_TELEMETRY_* environment
+// variables, which take precedence.
+type SinkConfig struct {
+	// Sink is one of segment (the default), file, webhook or none.
+	Sink string `yaml:"sink"`
+	// Path is the file the events are appended to with the file sink.
+	Path string `yaml:"path,omitempty"`
+	// URL is the address the events are posted to with the webhook sink.
+	URL string `yaml:"url,omitempty"`
+}
+
+const (
+	sinkConfigFile = "telemetry.yaml"
+
+	envSink = "LAUNCHPAD_TELEMETRY_SINK"
+	envPath = "LAUNCHPAD_TELEMETRY_FILE"
+	envURL  = "LAUNCHPAD_TELEMETRY_URL"
+
+	webhookTimeout = 10 * time.Second
+)
+
+var (
+	errInvalidSink = errors.New("invalid analytics sink")
+	errWebhook     = errors.New("analytics webhook failed")
+)
+
+// LoadSinkConfig reads the sink configuration from the config file and environment.
+func LoadSinkConfig() (SinkConfig, error) {
+	cfg := SinkConfig{Sink: SinkSegment}
+
+	path, err := stateFile(sinkConfigFile)
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return cfg, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if v := os.Getenv(envSink); v != "" {
+		cfg.Sink = v
+	}
+	if v := os.Getenv(envPath); v != "" {
+		cfg.Path = v
+	}
+	if v := os.Getenv(envURL); v != "" {
+		cfg.URL = v
+	}
+
+	return cfg, nil
+}
+
+// NewSink returns the analytics client for the sink configuration. It returns nil for
+// the none sink and for the segment sink when no Segment token was compiled in.
+func NewSink(cfg SinkConfig) (Analytics, error) { //nolint:ireturn
+	switch cfg.Sink {
+	case "", SinkSegment:
+		if SegmentToken == "" {
+			return nil, nil
+		}
+		return NewSegmentClient(SegmentToken)
+	case SinkFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("%w: the file sink requires a path", errInvalidSink)
+		}
+		path, err := homedir.Expand(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand analytics file path: %w", err)
+		}
+		return NewFileSink(path), nil
+	case SinkWebhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("%w: the webhook sink requires a url", errInvalidSink)
+		}
+		return NewWebhookSink(cfg.URL), nil
+	case SinkNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: %q (must be %s, %s, %s or %s)", errInvalidSink, cfg.Sink, SinkSegment, SinkFile, SinkWebhook, SinkNone)
+	}
+}
+
+// FileSink appends the analytics messages to a local file, one JSON message per line.
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink returns a sink that appends to the file at path. The file is created on
+// the first message.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Enqueue writes the message to the file.
+func (s *FileSink) Enqueue(msg analytics.Message) error {
+	data, err := marshalMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+			return fmt.Errorf("failed to create analytics file directory: %w", err)
+		}
+		f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open analytics file: %w", err)
+		}
+		s.file = f
+	}
+
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write analytics file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close analytics file: %w", err)
+	}
+	return nil
+}
+
+// WebhookSink posts the analytics messages to an HTTP endpoint. Like the Segment
+// client, it batches the messages and sends them as {"batch": [...]} when closed.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+	mu     sync.Mutex
+	batch  []json.RawMessage
+}
+
+// NewWebhookSink returns a sink that posts to url.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Enqueue adds the message to the batch.
+func (s *WebhookSink) Enqueue(msg analytics.Message) error {
+	data, err := marshalMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batch = append(s.batch, data)
+	return nil
+}
+
+// Close posts the batch.
+func (s *WebhookSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string][]json.RawMessage{"batch": s.batch})
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics batch: %w", err)
+	}
+	s.batch = nil
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body)) //nolint:noctx
+	if err != nil {
+		return fmt.Errorf("%w: %w", errWebhook, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s responded with %s", errWebhook, s.url, resp.Status)
+	}
+	return nil
+}
+
+// marshalMessage encodes a message the way it is delivered by the sinks.
+func marshalMessage(msg analytics.Message) ([]byte, error) {
+	switch m := msg.(type) {
+	case analytics.Track:
+		m.Type = "track"
+		msg = m
+	case analytics.Identify:
+		m.Type = "identify"
+		msg = m
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode analytics message: %w", err)
+	}
+	return data, nil
+}
+
+// stampMessage sets the message id and timestamp of a message the way the Segment
+// client does when they are not set.
+func stampMessage(msg analytics.Message) analytics.Message { //nolint:ireturn
+	switch m := msg.(type) {
+	case analytics.Track:
+		if m.MessageId == "" {
+			m.MessageId = uuid.NewString()
+		}
+		if m.Timestamp.IsZero() {
+			m.Timestamp = time.Now()
+		}
+		return m
+	case analytics.Identify:
+		if m.MessageId == "" {
+			m.MessageId = uuid.NewString()
+		}
+		if m.Timestamp.IsZero() {
+			m.Timestamp = time.Now()
+		}
+		return m
+	}
+	return msg
+}
+
+// segmentBatch is the request body of the Segment client, which does not export its
+// own type.
+type segmentBatch struct {
+	MessageID string             `json:"messageId"`
+	SentAt    time.Time          `json:"sentAt"`
+	Messages  []json.RawMessage  `json:"batch"`
+	Context   *analytics.Context `json:"context"`
+}
+
+// segmentContext returns the context the Segment client sends with each batch.
+func segmentContext() *analytics.Context {
+	return &analytics.Context{
+		Library: analytics.LibraryInfo{
+			Name:    "analytics-go",
+			Version: analytics.Version,
+		},
+	}
+}
+
+func marshalPayload(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode analytics payload: %w", err)
+	}
+	return data, nil
+}
+
+// stateFile returns the path of a file in the launchpad state directory.
+func stateFile(name string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, constant.StateBaseDir, name), nil
+}
@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/segmentio/analytics-go/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "analytics.jsonl")
+
+	for _, event := range []string{"first", "second"} {
+		sink := NewFileSink(path)
+		require.NoError(t, sink.Enqueue(analytics.Track{Event: event}))
+		require.NoError(t, sink.Close())
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var msg analytics.Track
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		require.Equal(t, "track", msg.Type)
+		events = append(events, msg.Event)
+	}
+	require.Equal(t, []string{"first", "second"}, events)
+}
+
+func TestWebhookSink(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	require.NoError(t, sink.Enqueue(analytics.Track{Event: "test"}))
+	require.NoError(t, sink.Enqueue(analytics.Identify{UserId: "user"}))
+	require.NoError(t, sink.Close())
+
+	var payload struct {
+		Batch []map[string]interface{} `json:"batch"`
+	}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Len(t, payload.Batch, 2)
+	require.Equal(t, "track", payload.Batch[0]["type"])
+	require.Equal(t, "identify", payload.Batch[1]["type"])
+}
+
+func TestWebhookSinkFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	require.NoError(t, sink.Enqueue(analytics.Track{Event: "test"}))
+	require.ErrorIs(t, sink.Close(), errWebhook)
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := NewSink(SinkConfig{Sink: SinkFile})
+	require.ErrorIs(t, err, errInvalidSink)
+	_, err = NewSink(SinkConfig{Sink: SinkWebhook})
+	require.ErrorIs(t, err, errInvalidSink)
+	_, err = NewSink(SinkConfig{Sink: "kafka"})
+	require.ErrorIs(t, err, errInvalidSink)
+
+	sink, err := NewSink(SinkConfig{Sink: SinkNone})
+	require.NoError(t, err)
+	require.Nil(t, sink)
+
+	sink, err = NewSink(SinkConfig{Sink: SinkWebhook, URL: "http://localhost"})
+	require.NoError(t, err)
+	require.IsType(t, &WebhookSink{}, sink)
+}
+
+func TestLoadSinkConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	homedir.DisableCache = true
+	defer func() { homedir.DisableCache = false }()
+
+	cfg, err := LoadSinkConfig()
+	require.NoError(t, err)
+	require.Equal(t, SinkSegment, cfg.Sink)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".mirantis-launchpad"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".mirantis-launchpad", "telemetry.yaml"), []byte("sink: webhook\nurl: https://telemetry.example.com\n"), 0o600))
+
+	cfg, err = LoadSinkConfig()
+	require.NoError(t, err)
+	require.Equal(t, SinkConfig{Sink: SinkWebhook, URL: "https://telemetry.example.com"}, cfg)
+
+	t.Setenv("LAUNCHPAD_TELEMETRY_SINK", "file")
+	t.Setenv("LAUNCHPAD_TELEMETRY_FILE", "/var/log/launchpad.jsonl")
+	cfg, err = LoadSinkConfig()
+	require.NoError(t, err)
+	require.Equal(t, SinkConfig{Sink: SinkFile, Path: "/var/log/launchpad.jsonl", URL: "https://telemetry.example.com"}, cfg)
+}
@@ -0,0 +1,348 @@
+package importcluster
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	// needed to load the build func in package init.
+	_ "github.com/Mirantis/launchpad/pkg/configurer/centos"
+	// needed to load the build func in package init.
+	_ "github.com/Mirantis/launchpad/pkg/configurer/enterpriselinux"
+	// needed to load the build func in package init.
+	_ "github.com/Mirantis/launchpad/pkg/configurer/oracle"
+	// needed to load the build func in package init.
+	_ "github.com/Mirantis/launchpad/pkg/configurer/sles"
+	// needed to load the build func in package init.
+	_ "github.com/Mirantis/launchpad/pkg/configurer/ubuntu"
+	// needed to load the build func in package init.
+	_ "github.com/Mirantis/launchpad/pkg/configurer/windows"
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/mke"
+	"github.com/Mirantis/launchpad/pkg/msr"
+	common "github.com/Mirantis/launchpad/pkg/product/common/config"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	"github.com/k0sproject/dig"
+	"github.com/k0sproject/rig"
+	log "github.com/sirupsen/logrus"
+)
+
+// mkeServerCertPath is the MKE controller server certificate on the managers, the
+// --san addresses are read from it.
+const mkeServerCertPath = "/var/lib/docker/volumes/ucp-controller-server-certs/_data/cert.pem"
+
+var (
+	errNotManager      = errors.New("the host is not a swarm manager")
+	errMKENotInstalled = errors.New("MKE is not installed")
+)
+
+// Options are the settings of the import.
+type Options struct {
+	// Address of a manager of the cluster.
+	Address string
+	// Name of the cluster in the generated configuration.
+	Name string
+	// SSHUser, SSHPort and SSHKeyPath are used for connecting to the manager and to
+	// the other Linux nodes of the cluster.
+	SSHUser    string
+	SSHPort    int
+	SSHKeyPath string
+}
+
+// facts are what the import found out about the cluster.
+type facts struct {
+	nodes []swarm.Node
+	// fromNodeID is the node ID of the manager the import connected to
+	fromNodeID string
+	mke        mkeconfig.MKEMetadata
+	// msr has the MSR details of the nodes that run an MSR replica, by node ID
+	msr map[string]*mkeconfig.MSRMetadata
+	// daemonConfig has the daemon.json of the nodes that could be connected, by node ID
+	daemonConfig map[string]dig.Mapping
+	sans         []string
+}
+
+// Import connects to the manager, finds out the nodes of the swarm, the MKE, MSR and
+// MCR versions and returns a configuration that reproduces the cluster. The other
+// Linux nodes are connected with the same SSH settings to look for MSR replicas and
+// to read their daemon.json, the ones that can't be connected are left as they are in
+// the swarm.
+func Import(opts Options) (*mkeconfig.ClusterConfig, error) {
+	manager := newHost(opts, opts.Address)
+	if err := connect(manager); err != nil {
+		return nil, err
+	}
+	defer manager.Disconnect()
+
+	f := &facts{msr: make(map[string]*mkeconfig.MSRMetadata), daemonConfig: make(map[string]dig.Mapping)}
+
+	control, err := manager.ExecOutput(manager.Configurer.DockerCommandf(`info --format "{{ .Swarm.ControlAvailable }}"`))
+	if err != nil || control != "true" {
+		return nil, fmt.Errorf("%s: %w", manager, errNotManager)
+	}
+	f.fromNodeID, err = manager.ExecOutput(manager.Configurer.DockerCommandf(`info --format "{{ .Swarm.NodeID }}"`))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get the node ID: %w", manager, err)
+	}
+
+	f.nodes, err = swarm.Nodes(manager)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("%s: found %d nodes in the swarm", manager, len(f.nodes))
+
+	if err := mke.CollectFacts(manager, &f.mke); err != nil {
+		return nil, fmt.Errorf("%s: failed to collect MKE details: %w", manager, err)
+	}
+	if !f.mke.Installed {
+		return nil, fmt.Errorf("%s: %w", manager, errMKENotInstalled)
+	}
+	log.Infof("%s: MKE has version %s", manager, f.mke.InstalledVersion)
+
+	f.sans = mkeSANs(manager, f.nodes)
+	f.daemonConfig[f.fromNodeID] = readDaemonConfig(manager)
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, node := range f.nodes {
+		if node.ID == f.fromNodeID || node.Description.Platform.OS == "windows" || node.Status.State != "ready" {
+			continue
+		}
+		wg.Add(1)
+		go func(node swarm.Node) {
+			defer wg.Done()
+			daemonConfig, msrMeta := investigateNode(opts, node)
+			mu.Lock()
+			defer mu.Unlock()
+			f.daemonConfig[node.ID] = daemonConfig
+			if msrMeta != nil {
+				f.msr[node.ID] = msrMeta
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	return buildConfig(opts, f), nil
+}
+
+func newHost(opts Options, address string) *mkeconfig.Host {
+	ssh := &rig.SSH{Address: address, User: opts.SSHUser, Port: opts.SSHPort}
+	if opts.SSHKeyPath != "" {
+		keyPath := opts.SSHKeyPath
+		ssh.KeyPath = &keyPath
+	}
+	return &mkeconfig.Host{
+		Connection:   rig.Connection{SSH: ssh},
+		DaemonConfig: dig.Mapping{},
+		Environment:  map[string]string{},
+		Metadata:     &mkeconfig.HostMetadata{},
+	}
+}
+
+func connect(h *mkeconfig.Host) error {
+	if err := h.Connect(); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", h, err)
+	}
+	if err := h.ResolveConfigurer(); err != nil {
+		h.Disconnect()
+		return fmt.Errorf("%s: %w", h, err)
+	}
+	return nil
+}
+
+// investigateNode connects to a node to read its daemon.json and to look for an MSR
+// replica. The MSR details are nil when the node has no replica.
+func investigateNode(opts Options, node swarm.Node) (dig.Mapping, *mkeconfig.MSRMetadata) {
+	h := newHost(opts, node.Address())
+	if err := connect(h); err != nil {
+		log.Warnf("%s: %s, its MSR replica and daemon.json are not imported", node.Description.Hostname, err)
+		return nil, nil
+	}
+	defer h.Disconnect()
+
+	daemonConfig := readDaemonConfig(h)
+	if node.Spec.Role != "worker" {
+		return daemonConfig, nil
+	}
+	msrMeta, err := msr.CollectFacts(h)
+	if err != nil {
+		log.Warnf("%s: failed to collect MSR details: %s", h, err)
+		return daemonConfig, nil
+	}
+	if !msrMeta.Installed {
+		return daemonConfig, nil
+	}
+	log.Infof("%s: MSR replica %s has version %s", h, msrMeta.ReplicaID, msrMeta.InstalledVersion)
+	return daemonConfig, msrMeta
+}
+
+func readDaemonConfig(h *mkeconfig.Host) dig.Mapping {
+	data, err := h.Configurer.ReadFile(h, h.Configurer.MCRConfigPath())
+	if err != nil {
+		log.Debugf("%s: no daemon.json: %s", h, err)
+		return nil
+	}
+	var cfg dig.Mapping
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		log.Warnf("%s: failed to parse daemon.json: %s", h, err)
+		return nil
+	}
+	return cfg
+}
+
+// mkeSANs returns the DNS names of the MKE server certificate that are not names of the
+// nodes or names MKE adds for its internal services.
+func mkeSANs(manager *mkeconfig.Host, nodes []swarm.Node) []string {
+	data, err := manager.Configurer.ReadFile(manager, mkeServerCertPath)
+	if err != nil {
+		log.Warnf("%s: failed to read the MKE server certificate, add the --san install flags by hand: %s", manager, err)
+		return nil
+	}
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		log.Warnf("%s: the MKE server certificate is not in the PEM format, add the --san install flags by hand", manager)
+		return nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		log.Warnf("%s: failed to parse the MKE server certificate, add the --san install flags by hand: %s", manager, err)
+		return nil
+	}
+	return filterSANs(cert.DNSNames, nodes)
+}
+
+func filterSANs(names []string, nodes []swarm.Node) []string {
+	nodeNames := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		nodeNames[strings.ToLower(n.Description.Hostname)] = true
+		nodeNames[strings.ToLower(strings.SplitN(n.Description.Hostname, ".", 2)[0])] = true
+	}
+
+	var sans []string
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		short := strings.SplitN(lower, ".", 2)[0]
+		switch {
+		case !strings.Contains(lower, "."), nodeNames[lower], nodeNames[short]:
+		case strings.HasSuffix(lower, ".local"), strings.HasSuffix(lower, ".svc"), strings.HasPrefix(lower, "kubernetes."):
+		default:
+			sans = append(sans, name)
+		}
+	}
+	return sans
+}
+
+// buildConfig creates the configuration from the facts.
+func buildConfig(opts Options, f *facts) *mkeconfig.ClusterConfig {
+	cfg := &mkeconfig.ClusterConfig{
+		APIVersion: "launchpad.mirantis.com/mke/v1.6",
+		Kind:       "mke",
+		Metadata:   &mkeconfig.ClusterMeta{Name: opts.Name},
+		Spec: &mkeconfig.ClusterSpec{
+			MCR: common.MCRConfig{Channel: mcrChannel(f.nodes)},
+			MKE: mkeconfig.MKEConfig{Version: f.mke.InstalledVersion},
+		},
+	}
+
+	// InstalledBootstrapImage is in the repo:/ucp:version format
+	if repo, _, ok := strings.Cut(f.mke.InstalledBootstrapImage, ":/ucp:"); ok && repo != constant.ImageRepo {
+		cfg.Spec.MKE.ImageRepo = repo
+	}
+	for _, san := range f.sans {
+		cfg.Spec.MKE.InstallFlags.Add("--san=" + san)
+	}
+	if f.mke.VXLAN {
+		cfg.Spec.MKE.InstallFlags.Add("--calico-vxlan")
+	}
+
+	nodes := append([]swarm.Node{}, f.nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return roleOrder(nodes[i], f) < roleOrder(nodes[j], f)
+	})
+
+	for _, node := range nodes {
+		if node.Status.State != "ready" {
+			log.Warnf("%s: the node is %s, it is left out of the configuration", node.Description.Hostname, node.Status.State)
+			continue
+		}
+
+		address := node.Address()
+		if node.ID == f.fromNodeID {
+			address = opts.Address
+		}
+		h := newHost(opts, address)
+		h.Role = node.Spec.Role
+		if msrMeta, ok := f.msr[node.ID]; ok {
+			h.Role = "msr"
+			if cfg.Spec.MSR == nil {
+				cfg.Kind = "mke+msr"
+				cfg.Spec.MSR = &mkeconfig.MSRConfig{Version: msrMeta.InstalledVersion, ReplicaIDs: "sequential"}
+				if i := strings.LastIndex(msrMeta.InstalledBootstrapImage, "/dtr:"); i > 0 && msrMeta.InstalledBootstrapImage[:i] != constant.ImageRepo {
+					cfg.Spec.MSR.ImageRepo = msrMeta.InstalledBootstrapImage[:i]
+				}
+			}
+		}
+		if node.Description.Platform.OS == "windows" {
+			log.Warnf("%s: windows node, set the winRM password in the configuration", node.Description.Hostname)
+			h.Connection = rig.Connection{WinRM: &rig.WinRM{Address: address, User: "Administrator", Port: 5985}}
+		}
+		if daemonConfig := f.daemonConfig[node.ID]; len(daemonConfig) > 0 {
+			h.DaemonConfig = daemonConfig
+		}
+		cfg.Spec.Hosts = append(cfg.Spec.Hosts, h)
+	}
+
+	return cfg
+}
+
+// roleOrder sorts the managers first and the MSR nodes last, like in the examples.
+func roleOrder(node swarm.Node, f *facts) int {
+	switch {
+	case node.Spec.Role == "manager":
+		return 0
+	case f.msr[node.ID] != nil:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// mcrChannel returns the stable channel of the MCR version most of the nodes run, for
+// example stable-25.0 for 25.0.8.
+func mcrChannel(nodes []swarm.Node) string {
+	counts := make(map[string]int)
+	for _, n := range nodes {
+		parts := strings.SplitN(n.Description.Engine.EngineVersion, ".", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		counts[parts[0]+"."+parts[1]]++
+	}
+	if len(counts) == 0 {
+		log.Warnf("failed to detect the MCR version, using the %s channel", constant.MCRChannel)
+		return constant.MCRChannel
+	}
+
+	versions := make([]string, 0, len(counts))
+	for v := range counts {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if counts[versions[i]] != counts[versions[j]] {
+			return counts[versions[i]] > counts[versions[j]]
+		}
+		return versions[i] < versions[j]
+	})
+	if len(versions) > 1 {
+		log.Warnf("the nodes run different MCR versions (%s), using the channel of %s", strings.Join(versions, ", "), versions[0])
+	}
+	return constant.MCRChannel + "-" + versions[0]
+}
@@ -0,0 +1,118 @@
+package importcluster
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Mirantis/launchpad/pkg/config"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	"github.com/k0sproject/dig"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const nodeInspect = `[
+  {
+    "ID": "m1",
+    "Spec": {"Role": "manager", "Availability": "active"},
+    "Description": {"Hostname": "mgr-0", "Platform": {"OS": "linux"}, "Engine": {"EngineVersion": "25.0.8"}},
+    "Status": {"State": "ready", "Addr": "0.0.0.0"},
+    "ManagerStatus": {"Leader": true, "Addr": "172.16.0.10:2377"}
+  },
+  {
+    "ID": "w1",
+    "Spec": {"Role": "worker", "Availability": "active"},
+    "Description": {"Hostname": "wrk-0", "Platform": {"OS": "linux"}, "Engine": {"EngineVersion": "25.0.8"}},
+    "Status": {"State": "ready", "Addr": "172.16.0.20"}
+  },
+  {
+    "ID": "d1",
+    "Spec": {"Role": "worker", "Availability": "active"},
+    "Description": {"Hostname": "msr-0", "Platform": {"OS": "linux"}, "Engine": {"EngineVersion": "23.0.15"}},
+    "Status": {"State": "ready", "Addr": "172.16.0.30"}
+  },
+  {
+    "ID": "win1",
+    "Spec": {"Role": "worker", "Availability": "active"},
+    "Description": {"Hostname": "win-0", "Platform": {"OS": "windows"}, "Engine": {"EngineVersion": "25.0.8"}},
+    "Status": {"State": "ready", "Addr": "172.16.0.40"}
+  },
+  {
+    "ID": "gone",
+    "Spec": {"Role": "worker", "Availability": "active"},
+    "Description": {"Hostname": "old-0", "Platform": {"OS": "linux"}, "Engine": {"EngineVersion": "20.10.9"}},
+    "Status": {"State": "down", "Addr": "172.16.0.50"}
+  }
+]`
+
+func testFacts(t *testing.T) *facts {
+	t.Helper()
+	f := &facts{
+		fromNodeID: "m1",
+		mke: mkeconfig.MKEMetadata{
+			Installed:               true,
+			InstalledVersion:        "3.7.15",
+			InstalledBootstrapImage: "registry.example.com/mirantis:/ucp:3.7.15",
+		},
+		msr: map[string]*mkeconfig.MSRMetadata{
+			"d1": {Installed: true, InstalledVersion: "2.9.20", InstalledBootstrapImage: "docker.io/mirantis/dtr:2.9.20", ReplicaID: "000000000001"},
+		},
+		daemonConfig: map[string]dig.Mapping{
+			"w1": {"debug": true},
+		},
+		sans: []string{"mke.example.com"},
+	}
+	require.NoError(t, json.Unmarshal([]byte(nodeInspect), &f.nodes))
+	return f
+}
+
+func TestBuildConfig(t *testing.T) {
+	opts := Options{Address: "mgr-0.example.com", Name: "imported", SSHUser: "ubuntu", SSHPort: 22}
+	cfg := buildConfig(opts, testFacts(t))
+
+	require.Equal(t, "mke+msr", cfg.Kind)
+	require.Equal(t, "imported", cfg.Metadata.Name)
+	require.Equal(t, "3.7.15", cfg.Spec.MKE.Version)
+	require.Equal(t, "registry.example.com/mirantis", cfg.Spec.MKE.ImageRepo)
+	require.Equal(t, []string{"--san=mke.example.com"}, []string(cfg.Spec.MKE.InstallFlags))
+	require.Equal(t, "2.9.20", cfg.Spec.MSR.Version)
+	require.Empty(t, cfg.Spec.MSR.ImageRepo)
+	require.Equal(t, "stable-25.0", cfg.Spec.MCR.Channel)
+
+	require.Len(t, cfg.Spec.Hosts, 4)
+	roles := make([]string, 0, len(cfg.Spec.Hosts))
+	addresses := make([]string, 0, len(cfg.Spec.Hosts))
+	for _, h := range cfg.Spec.Hosts {
+		roles = append(roles, h.Role)
+		addresses = append(addresses, h.Address())
+	}
+	require.Equal(t, []string{"manager", "worker", "worker", "msr"}, roles)
+	require.Equal(t, []string{"mgr-0.example.com", "172.16.0.20", "172.16.0.40", "172.16.0.30"}, addresses)
+	require.Equal(t, "ubuntu", cfg.Spec.Hosts[0].SSH.User)
+	require.Equal(t, true, cfg.Spec.Hosts[1].DaemonConfig["debug"])
+	require.NotNil(t, cfg.Spec.Hosts[2].WinRM)
+
+	// the configuration loads and only lacks what can't be detected
+	data, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.NoError(t, config.Validate(data))
+}
+
+func TestFilterSANs(t *testing.T) {
+	var nodes []swarm.Node
+	require.NoError(t, json.Unmarshal([]byte(nodeInspect), &nodes))
+
+	sans := filterSANs([]string{
+		"mke.example.com",
+		"mgr-0",
+		"mgr-0.internal.example.com",
+		"localhost",
+		"proxy.local",
+		"ucp-controller.kube-system.svc",
+		"kubernetes.default.svc.cluster.local",
+		"kubernetes.default",
+		"lb.example.com",
+	}, nodes)
+	require.Equal(t, []string{"mke.example.com", "lb.example.com"}, sans)
+}
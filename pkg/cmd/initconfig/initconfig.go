@@ -0,0 +1,266 @@
+package initconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/Mirantis/launchpad/pkg/config"
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/docker/hub"
+	common "github.com/Mirantis/launchpad/pkg/product/common/config"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/k0sproject/rig"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	errInvalidAnswer   = errors.New("invalid answer")
+	errVersionNotFound = errors.New("version not found")
+
+	// tagExists is replaced in tests.
+	tagExists = hub.TagExists
+)
+
+// minPasswordLength is the minimum length of the MKE admin password.
+const minPasswordLength = 8
+
+// Host is the answers for a single host.
+type Host struct {
+	Address string
+	Role    string
+}
+
+// Answers are the answers to the questions of the wizard.
+type Answers struct {
+	Kind          string
+	Name          string
+	Hosts         []Host
+	SSHUser       string
+	SSHKeyPath    string
+	MKEVersion    string
+	MSRVersion    string
+	MCRChannel    string
+	AdminUsername string
+	AdminPassword string
+}
+
+// ClusterConfig builds the cluster configuration from the answers.
+func (a *Answers) ClusterConfig() *mkeconfig.ClusterConfig {
+	cfg := &mkeconfig.ClusterConfig{
+		APIVersion: "launchpad.mirantis.com/mke/v1.6",
+		Kind:       a.Kind,
+		Metadata:   &mkeconfig.ClusterMeta{Name: a.Name},
+		Spec: &mkeconfig.ClusterSpec{
+			MCR: common.MCRConfig{Channel: a.MCRChannel},
+			MKE: mkeconfig.MKEConfig{
+				Version:       a.MKEVersion,
+				AdminUsername: a.AdminUsername,
+				AdminPassword: a.AdminPassword,
+			},
+		},
+	}
+	if a.Kind == "mke+msr" {
+		cfg.Spec.MSR = &mkeconfig.MSRConfig{Version: a.MSRVersion, ReplicaIDs: "sequential"}
+	}
+
+	for _, h := range a.Hosts {
+		ssh := &rig.SSH{Address: h.Address, User: a.SSHUser, Port: 22}
+		if a.SSHKeyPath != "" {
+			keyPath := a.SSHKeyPath
+			ssh.KeyPath = &keyPath
+		}
+		cfg.Spec.Hosts = append(cfg.Spec.Hosts, &mkeconfig.Host{Role: h.Role, Connection: rig.Connection{SSH: ssh}})
+	}
+
+	return cfg
+}
+
+// YAML returns the cluster configuration built from the answers as YAML, or an error if
+// the configuration doesn't pass the validation.
+func (a *Answers) YAML() ([]byte, error) {
+	data, err := yaml.Marshal(a.ClusterConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	if err := config.Validate(data); err != nil {
+		return nil, fmt.Errorf("the generated configuration is not valid: %w", err)
+	}
+	return data, nil
+}
+
+// Run asks the questions of the wizard and returns the resulting configuration as YAML.
+// The prompts are written to stderr so that the configuration can be redirected from
+// stdout. If kind is empty, it is asked too.
+func Run(kind string) ([]byte, error) {
+	ask := func(p survey.Prompt, response interface{}, opts ...survey.AskOpt) error {
+		opts = append(opts,
+			survey.WithStdio(os.Stdin, os.Stderr, os.Stderr),
+			survey.WithIcons(func(icons *survey.IconSet) {
+				icons.Question.Text = ">"
+			}),
+		)
+		if err := survey.AskOne(p, response, opts...); err != nil {
+			return fmt.Errorf("init failed: %w", err)
+		}
+		return nil
+	}
+
+	a := &Answers{Kind: kind}
+	if a.Kind == "" {
+		if err := ask(&survey.Select{Message: "Cluster kind", Options: []string{"mke", "mke+msr"}, Default: "mke"}, &a.Kind); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ask(&survey.Input{Message: "Cluster name", Default: "my-mke-cluster"}, &a.Name, survey.WithValidator(survey.Required)); err != nil {
+		return nil, err
+	}
+
+	if err := askHosts(ask, a); err != nil {
+		return nil, err
+	}
+
+	if err := ask(&survey.Input{Message: "SSH user for the hosts", Default: "root"}, &a.SSHUser, survey.WithValidator(survey.Required)); err != nil {
+		return nil, err
+	}
+	if err := ask(&survey.Input{Message: "SSH private key path (leave empty to use the SSH agent and default keys)", Default: "~/.ssh/id_rsa"}, &a.SSHKeyPath); err != nil {
+		return nil, err
+	}
+
+	mkeLatest, err := hub.LatestTag("mirantis", "ucp", false)
+	if err != nil {
+		log.Warnf("failed to look up the latest MKE version: %s", err)
+	}
+	if err := ask(&survey.Input{Message: "MKE version", Default: mkeLatest}, &a.MKEVersion, survey.WithValidator(versionValidator("MKE", "ucp"))); err != nil {
+		return nil, err
+	}
+
+	if a.Kind == "mke+msr" {
+		msrLatest, err := hub.LatestTag("mirantis", "dtr", false)
+		if err != nil {
+			log.Warnf("failed to look up the latest MSR version: %s", err)
+		}
+		if err := ask(&survey.Input{Message: "MSR version", Default: msrLatest}, &a.MSRVersion, survey.WithValidator(versionValidator("MSR", "dtr"))); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ask(&survey.Input{Message: "MCR channel, for example stable or stable-25.0", Default: constant.MCRChannel}, &a.MCRChannel, survey.WithValidator(validateChannel)); err != nil {
+		return nil, err
+	}
+
+	if err := ask(&survey.Input{Message: "MKE admin username", Default: "admin"}, &a.AdminUsername, survey.WithValidator(survey.Required)); err != nil {
+		return nil, err
+	}
+	if err := ask(&survey.Password{Message: "MKE admin password"}, &a.AdminPassword, survey.WithValidator(validatePassword)); err != nil {
+		return nil, err
+	}
+
+	return a.YAML()
+}
+
+type askFunc func(p survey.Prompt, response interface{}, opts ...survey.AskOpt) error
+
+// askHosts asks for host addresses and roles until an empty address is given.
+func askHosts(ask askFunc, a *Answers) error {
+	roles := []string{"manager", "worker"}
+	if a.Kind == "mke+msr" {
+		roles = append(roles, "msr")
+	}
+
+	for {
+		var address string
+		message := fmt.Sprintf("Address of host %d (leave empty when done)", len(a.Hosts)+1)
+		if err := ask(&survey.Input{Message: message}, &address, survey.WithValidator(validateAddress)); err != nil {
+			return err
+		}
+
+		if address == "" {
+			if err := validateHosts(a.Kind, a.Hosts); err != nil {
+				log.Warn(err.Error())
+				continue
+			}
+			return nil
+		}
+
+		role := "manager"
+		if len(a.Hosts) > 0 {
+			role = "worker"
+		}
+		if err := ask(&survey.Select{Message: "Role of " + address, Options: roles, Default: role}, &role); err != nil {
+			return err
+		}
+		a.Hosts = append(a.Hosts, Host{Address: address, Role: role})
+	}
+}
+
+// validateHosts checks that the hosts have the roles the cluster kind requires.
+func validateHosts(kind string, hosts []Host) error {
+	hasRole := func(role string) bool {
+		for _, h := range hosts {
+			if h.Role == role {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasRole("manager") {
+		return fmt.Errorf("%w: at least one manager host is required", errInvalidAnswer)
+	}
+	if kind == "mke+msr" && !hasRole("msr") {
+		return fmt.Errorf("%w: at least one msr host is required", errInvalidAnswer)
+	}
+	return nil
+}
+
+func validateAddress(val interface{}) error {
+	address, _ := val.(string)
+	if address == "" || net.ParseIP(address) != nil {
+		return nil
+	}
+	if strings.ContainsAny(address, " /:@") {
+		return fmt.Errorf("%w: %q is not an IP address or a hostname", errInvalidAnswer, address)
+	}
+	return nil
+}
+
+func validateChannel(val interface{}) error {
+	channel, _ := val.(string)
+	if channel == "" || strings.ContainsAny(channel, " /") {
+		return fmt.Errorf("%w: %q is not a valid MCR channel", errInvalidAnswer, channel)
+	}
+	return nil
+}
+
+func validatePassword(val interface{}) error {
+	password, _ := val.(string)
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("%w: the password must be at least %d characters long", errInvalidAnswer, minPasswordLength)
+	}
+	return nil
+}
+
+// versionValidator checks that the version is published on Docker Hub. When Docker Hub
+// can't be reached, the version is accepted with a warning.
+func versionValidator(product, image string) survey.Validator {
+	return func(val interface{}) error {
+		version, _ := val.(string)
+		if version == "" {
+			return fmt.Errorf("%w: the %s version is required", errInvalidAnswer, product)
+		}
+		exists, err := tagExists("mirantis", image, version)
+		if err != nil {
+			log.Warnf("failed to check if %s version %s exists: %s", product, version, err)
+			return nil
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s %s (no mirantis/%s:%s image on Docker Hub)", errVersionNotFound, product, version, image, version)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package initconfig
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestAnswersYAML(t *testing.T) {
+	a := &Answers{
+		Kind:          "mke+msr",
+		Name:          "test",
+		Hosts:         []Host{{Address: "10.0.0.1", Role: "manager"}, {Address: "10.0.0.2", Role: "worker"}, {Address: "msr.example.com", Role: "msr"}},
+		SSHUser:       "ubuntu",
+		SSHKeyPath:    "~/.ssh/id_ed25519",
+		MKEVersion:    "3.7.15",
+		MSRVersion:    "2.9.20",
+		MCRChannel:    "stable-25.0",
+		AdminUsername: "admin",
+		AdminPassword: "orcaorcaorca",
+	}
+
+	data, err := a.YAML()
+	require.NoError(t, err)
+
+	var cfg map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	require.Equal(t, "launchpad.mirantis.com/mke/v1.6", cfg["apiVersion"])
+	require.Contains(t, string(data), "address: msr.example.com")
+	require.Contains(t, string(data), "keyPath: ~/.ssh/id_ed25519")
+	require.Contains(t, string(data), "adminPassword: orcaorcaorca")
+	require.Contains(t, string(data), "channel: stable-25.0")
+}
+
+func TestAnswersYAMLInvalid(t *testing.T) {
+	a := &Answers{
+		Kind:       "mke",
+		Name:       "test",
+		Hosts:      []Host{{Address: "10.0.0.1", Role: "worker"}},
+		SSHUser:    "root",
+		MKEVersion: "3.7.15",
+		MCRChannel: "stable",
+	}
+	_, err := a.YAML()
+	require.ErrorContains(t, err, "manager role is required")
+}
+
+func TestValidateHosts(t *testing.T) {
+	require.NoError(t, validateHosts("mke", []Host{{Address: "10.0.0.1", Role: "manager"}}))
+	require.Error(t, validateHosts("mke", []Host{{Address: "10.0.0.1", Role: "worker"}}))
+	require.Error(t, validateHosts("mke+msr", []Host{{Address: "10.0.0.1", Role: "manager"}}))
+}
+
+func TestVersionValidator(t *testing.T) {
+	orig := tagExists
+	t.Cleanup(func() { tagExists = orig })
+
+	tagExists = func(_, _, tag string) (bool, error) {
+		switch tag {
+		case "3.7.15":
+			return true, nil
+		case "offline":
+			return false, errors.New("no network")
+		default:
+			return false, nil
+		}
+	}
+
+	validate := versionValidator("MKE", "ucp")
+	require.NoError(t, validate("3.7.15"))
+	require.ErrorIs(t, validate("3.7.99"), errVersionNotFound)
+	require.NoError(t, validate("offline"))
+	require.ErrorIs(t, validate(""), errInvalidAnswer)
+}
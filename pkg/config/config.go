@@ -20,12 +20,16 @@ import (
 	_ "github.com/Mirantis/launchpad/pkg/config/migration/v13"
 	// needed to load the migrators.
 	_ "github.com/Mirantis/launchpad/pkg/config/migration/v14"
+	// needed to load the migrators. Without v15 a v1.5 configuration stops at v1.5 and
+	// fails the apiVersion validation, and config migrate can't reach the current v1.6.
+	_ "github.com/Mirantis/launchpad/pkg/config/migration/v15"
 	// needed to load the migrators.
 	_ "github.com/Mirantis/launchpad/pkg/config/migration/v1beta1"
 	// needed to load the migrators.
 	_ "github.com/Mirantis/launchpad/pkg/config/migration/v1beta2"
 	// needed to load the migrators.
 	_ "github.com/Mirantis/launchpad/pkg/config/migration/v1beta3"
+	mcclog "github.com/Mirantis/launchpad/pkg/log"
 	"github.com/Mirantis/launchpad/pkg/product"
 	"github.com/Mirantis/launchpad/pkg/product/mke"
 	"github.com/a8m/envsubst"
@@ -36,62 +40,215 @@ import (
 
 // ProductFromFile loads a yaml file and returns a Product that matches its Kind or an error if the file loading or validation fails.
 func ProductFromFile(path string) (product.Product, error) { //nolint:ireturn
-	data, err := resolveClusterFile(path)
+	return ProductFromFiles([]string{path})
+}
+
+// ProductFromFiles loads yaml files, merges them in order as layers on top of each other
+// and returns a Product that matches the Kind of the result.
+func ProductFromFiles(paths []string) (product.Product, error) { //nolint:ireturn
+	data, _, err := loadFiles(paths)
 	if err != nil {
 		return nil, err
 	}
 	return ProductFromYAML(data)
 }
 
-var errMissingKind = errors.New("configuration does not contain the required keyword 'kind'")
+// MergeFiles returns the configuration the files result in after merging, migrations
+// and environment variable substitution. The secret references are left as they are,
+// merging doesn't run their commands.
+func MergeFiles(paths []string, opts OfflineOptions) ([]byte, error) {
+	data, _, err := loadFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	plain, _, _, err := loadYAML(data, loadOptions{secrets: keepSecretRefs, execInventory: opts.ExecInventory})
+	if err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+// RenderFiles returns the configuration the files result in like MergeFiles, with the
+// credentials redacted.
+func RenderFiles(paths []string, opts OfflineOptions) ([]byte, error) {
+	plain, err := MergeFiles(paths, opts)
+	if err != nil {
+		return nil, err
+	}
+	if exec.DisableRedact {
+		return plain, nil
+	}
+
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal(plain, config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configuration: %w", err)
+	}
+	for k, v := range config {
+		config[k] = redactValue(k, v)
+	}
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return out, nil
+}
+
+// redactValue redacts the credentials and the registered secrets in a configuration
+// value, keeping the document valid YAML.
+func redactValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			v[k] = redactValue(fmt.Sprint(k), val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redactValue(key, val)
+		}
+		return v
+	case string:
+		if credentialKeyRe.MatchString(key) {
+			return "[REDACTED]"
+		}
+		return mcclog.Redact(credentialsRe.ReplaceAllString(v, "$1$2[REDACTED]"))
+	default:
+		return value
+	}
+}
+
+var credentialKeyRe = regexp.MustCompile(`(?i)(username|password)$`)
+
+var credentialsRe = regexp.MustCompile(`(username|password)([:= ]) ?\S+`)
+
+// redactConfig redacts the credentials and the registered secrets from a configuration
+// unless redaction has been disabled.
+func redactConfig(cfg string) string {
+	if exec.DisableRedact {
+		return cfg
+	}
+	return mcclog.Redact(credentialsRe.ReplaceAllString(cfg, "$1$2[REDACTED]"))
+}
+
+var (
+	errMissingKind       = errors.New("configuration does not contain the required keyword 'kind'")
+	errMissingAPIVersion = errors.New("configuration does not contain the required keyword 'apiVersion'")
+)
 
 // ProductFromYAML returns a Product from YAML bytes, or an error.
 func ProductFromYAML(data []byte) (product.Product, error) { //nolint:ireturn
+	plain, kind, _, err := loadYAML(data, loadOptions{secrets: resolveSecretRefs, execInventory: true})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("loaded configuration:\n%s", redactConfig(string(plain)))
+
+	switch kind {
+	case "mke", "mke+msr":
+		mke, err := mke.NewMKE(plain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MKE configuration: %w", err)
+		}
+		return mke, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownConfigKind, kind)
+	}
+}
+
+// loadOptions control the parts of loadYAML that read local files and the environment
+// or run local commands, which the offline commands must not do.
+type loadOptions struct {
+	secrets secretMode
+	// execInventory runs the commands of the exec inventory sources.
+	execInventory bool
+}
+
+// OfflineOptions are the options of the commands that load a configuration without
+// connecting to the hosts, such as config validate and config render.
+type OfflineOptions struct {
+	// ExecInventory runs the commands of the exec inventory sources, which are left in
+	// spec.inventory otherwise.
+	ExecInventory bool
+}
+
+// loadYAML decrypts a SOPS encrypted configuration, migrates the configuration to the
+// current format, substitutes the environment variables, resolves the secret
+// references as opts say and adds the hosts of spec.inventory. It returns the resulting YAML, the
+// kind of the configuration and whether the structure of the result differs from the
+// original document, which is the case when it was migrated, secrets were resolved or
+// hosts were added.
+func loadYAML(data []byte, opts loadOptions) ([]byte, string, bool, error) {
 	config := make(map[string]interface{})
 	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal configuration: %w", err)
+		return nil, "", false, fmt.Errorf("failed to unmarshal configuration: %w", err)
+	}
+
+	if isSOPSEncrypted(config) {
+		decrypted, err := decryptSOPS(data, config)
+		if err != nil {
+			return nil, "", false, err
+		}
+		config = decrypted
 	}
 
+	apiVersion, ok := config["apiVersion"].(string)
+	if !ok {
+		return nil, "", false, errMissingAPIVersion
+	}
 	if err := migration.Migrate(config); err != nil {
-		return nil, fmt.Errorf("failed to migrate configuration: %w", err)
+		return nil, "", false, fmt.Errorf("failed to migrate configuration: %w", err)
 	}
+	rewritten := config["apiVersion"] != apiVersion
 
 	if config["kind"] == nil {
-		return nil, errMissingKind
+		return nil, "", rewritten, errMissingKind
+	}
+
+	// $patch keys of the spec.hosts entries that override inventory hosts are not
+	// variables, $$ is substituted with a $
+	for k, v := range config {
+		config[k] = escapePatchKeys(v)
 	}
 
 	data, err := yaml.Marshal(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+		return nil, "", rewritten, fmt.Errorf("failed to marshal configuration: %w", err)
 	}
 
 	plain, err := envsubst.Bytes(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to substitute environment variables: %w", err)
+		return nil, "", rewritten, fmt.Errorf("failed to substitute environment variables: %w", err)
 	}
 
-	cfg := string(plain)
-	if !exec.DisableRedact {
-		re := regexp.MustCompile(`(username|password)([:= ]) ?\S+`)
-		cfg = re.ReplaceAllString(cfg, "$1$2[REDACTED]")
+	// the secrets are resolved after the substitution so that their values are used as is
+	resolved := make(map[string]interface{})
+	if err := yaml.Unmarshal(plain, resolved); err != nil {
+		return nil, "", rewritten, fmt.Errorf("failed to unmarshal configuration: %w", err)
 	}
-
-	log.Debugf("loaded configuration:\n%s", cfg)
-
-	kindStr, ok := config["kind"].(string)
-	if !ok {
-		return nil, errMissingKind
+	count, err := resolveSecrets(resolved, opts.secrets)
+	if err != nil {
+		return nil, "", rewritten, err
 	}
-	switch kindStr {
-	case "mke", "mke+msr":
-		mke, err := mke.NewMKE(plain)
+	// the inventory sources can use secret references too
+	expanded, err := expandInventory(resolved, opts.execInventory)
+	if err != nil {
+		return nil, "", rewritten, err
+	}
+	if count > 0 || expanded {
+		plain, err = yaml.Marshal(resolved)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse MKE configuration: %w", err)
+			return nil, "", rewritten, fmt.Errorf("failed to marshal configuration: %w", err)
 		}
-		return mke, nil
-	default:
-		return nil, fmt.Errorf("%w: %s", errUnknownConfigKind, kindStr)
+		rewritten = true
 	}
+
+	kind, ok := config["kind"].(string)
+	if !ok {
+		return nil, "", rewritten, errMissingKind
+	}
+
+	return plain, kind, rewritten, nil
 }
 
 var errUnknownConfigKind = errors.New("unknown configuration kind")
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/Mirantis/launchpad/pkg/inventory"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// inventoryKey is the key of spec that lists the external sources of hosts.
+const inventoryKey = "inventory"
+
+// expandInventory reads the hosts from the sources in spec.inventory and adds them to
+// spec.hosts. The entries already in spec.hosts are merged on top of the inventory
+// hosts with the same address, so they can override the settings of single hosts, and
+// the others are appended. The inventory key is removed. The exec sources are only run
+// when execInventory is set, otherwise they are left in spec.inventory. It returns false
+// when there is no inventory.
+func expandInventory(config map[string]interface{}, execInventory bool) (bool, error) {
+	spec, ok := config["spec"].(map[interface{}]interface{})
+	if !ok {
+		return false, nil
+	}
+	value, ok := spec[inventoryKey]
+	if !ok {
+		return false, nil
+	}
+	delete(spec, inventoryKey)
+
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal spec.inventory: %w", err)
+	}
+	var sources []inventory.Source
+	if err := yaml.UnmarshalStrict(data, &sources); err != nil {
+		return false, fmt.Errorf("invalid spec.inventory: %w", err)
+	}
+
+	hosts, skipped, err := inventory.LoadSkipping(sources, func(s inventory.Source) bool {
+		return s.Exec != nil && !execInventory
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to load inventory: %w", err)
+	}
+	for _, s := range skipped {
+		log.Warnf("not running inventory command %q, its hosts are not included (use --exec-inventory to run it)", s.Exec.Command)
+	}
+	if len(skipped) > 0 {
+		spec[inventoryKey] = skipped
+	}
+
+	overrides, _ := spec["hosts"].([]interface{})
+	merged, err := mergeHosts(hosts, overrides)
+	if err != nil {
+		return false, err
+	}
+	spec["hosts"] = merged
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Mirantis/launchpad/pkg/product/mke"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductFromFileInventory(t *testing.T) {
+	dir := t.TempDir()
+	state := writeFile(t, dir, "output.json", `{
+  "hosts": {
+    "value": [
+      {"role": "manager", "ssh": {"address": "10.0.0.1", "user": "ubuntu"}},
+      {"role": "worker", "ssh": {"address": "10.0.0.2", "user": "ubuntu"}},
+      {"role": "worker", "ssh": {"address": "10.0.0.3", "user": "ubuntu"}}
+    ]
+  }
+}`)
+	t.Setenv("TF_OUTPUT", state)
+	path := writeFile(t, dir, "launchpad.yaml", `apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+metadata:
+  name: inventory
+spec:
+  inventory:
+    - terraform:
+        path: ${TF_OUTPUT}
+  hosts:
+    - ssh:
+        address: 10.0.0.2
+        user: centos
+        port: 2222
+    - ssh:
+        address: 10.0.0.3
+      $patch: delete
+    - role: worker
+      ssh:
+        address: 10.0.0.4
+  mke:
+    version: 3.7.5
+  mcr:
+    channel: stable
+`)
+
+	product, err := ProductFromFile(path)
+	require.NoError(t, err)
+	cfg := product.(*mke.MKE).ClusterConfig
+
+	require.Len(t, cfg.Spec.Hosts, 3)
+	require.Equal(t, "10.0.0.1", cfg.Spec.Hosts[0].Address())
+	require.Equal(t, "manager", cfg.Spec.Hosts[0].Role)
+	require.Equal(t, "10.0.0.2", cfg.Spec.Hosts[1].Address())
+	require.Equal(t, "worker", cfg.Spec.Hosts[1].Role)
+	require.Equal(t, "centos", cfg.Spec.Hosts[1].SSH.User)
+	require.Equal(t, 2222, cfg.Spec.Hosts[1].SSH.Port)
+	require.Equal(t, "10.0.0.4", cfg.Spec.Hosts[2].Address())
+	require.Empty(t, cfg.Spec.Inventory)
+
+	require.NoError(t, ValidateFile(path))
+}
+
+func TestValidateInventoryError(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "launchpad.yaml", `apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  inventory:
+    - ansible:
+        path: `+dir+`/missing.ini
+  mcr:
+    channel: stable
+`)
+
+	err := ValidateFile(path)
+	require.ErrorContains(t, err, "spec.inventory[0]: failed to read ansible inventory")
+}
+
+func TestValidateExecInventory(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+	script := writeFile(t, dir, "inventory.sh", `touch `+marker+`
+echo '[{"role": "worker", "ssh": {"address": "10.0.0.1"}}]'
+`)
+	path := writeFile(t, dir, "launchpad.yaml", `apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  inventory:
+    - exec:
+        command: sh `+script+`
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.2
+  mke:
+    version: 3.7.5
+  mcr:
+    channel: stable
+`)
+
+	require.NoError(t, ValidateFile(path))
+	require.NoFileExists(t, marker)
+
+	require.NoError(t, ValidateFiles([]string{path}, OfflineOptions{ExecInventory: true}))
+	require.FileExists(t, marker)
+}
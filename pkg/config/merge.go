@@ -0,0 +1,380 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// extendsKey is the top level key a configuration file uses to name the files it is
+// layered on, for example extends: base.yaml.
+const extendsKey = "extends"
+
+// patchKey with the value patchDelete in a spec.hosts entry of an overlay removes the
+// host with the same address from the base.
+const (
+	patchKey    = "$patch"
+	patchDelete = "delete"
+)
+
+// repeatableFlags are the flags that can be given several times, such as --san. The
+// flags of an overlay are added to them instead of replacing them.
+var repeatableFlags = map[string]bool{
+	"--san":        true,
+	"--dns":        true,
+	"--dns-opt":    true,
+	"--dns-search": true,
+}
+
+var (
+	errExtendsCycle   = errors.New("configuration files extend each other")
+	errInvalidExtends = errors.New("invalid extends")
+	errNoConfigFiles  = errors.New("no configuration files given")
+	errInvalidPatch   = errors.New("invalid $patch")
+)
+
+// loadFiles reads the configuration files and merges them in order, each file on top of
+// the previous ones. A file can also name the files it is layered on with the extends
+// key, those are merged first. When a single file that doesn't extend anything is given,
+// it is returned as is and merged is false.
+func loadFiles(paths []string) ([]byte, bool, error) {
+	if len(paths) == 0 {
+		return nil, false, errNoConfigFiles
+	}
+
+	var layers []interface{}
+	for _, path := range paths {
+		fileLayers, err := loadLayers(path, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		layers = append(layers, fileLayers...)
+	}
+
+	if len(layers) == 1 {
+		if _, ok := layers[0].([]byte); ok {
+			return layers[0].([]byte), false, nil
+		}
+	}
+
+	var (
+		merged interface{}
+		err    error
+	)
+	for _, layer := range layers {
+		if data, ok := layer.([]byte); ok {
+			var doc interface{}
+			if err := yaml.Unmarshal(data, &doc); err != nil {
+				return nil, false, fmt.Errorf("failed to unmarshal configuration: %w", err)
+			}
+			layer = doc
+		}
+		merged, err = mergeValues("", merged, layer)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal merged configuration: %w", err)
+	}
+	return data, true, nil
+}
+
+// loadLayers returns the layers of a configuration file, the files it extends first. A
+// plain file that doesn't extend anything is returned as a []byte, the others as parsed
+// documents, decrypted and without the extends key.
+func loadLayers(path string, seen []string) ([]interface{}, error) {
+	abs := path
+	if path != "-" {
+		if p, err := filepath.Abs(path); err == nil {
+			abs = p
+		}
+	}
+	for _, s := range seen {
+		if s == abs {
+			return nil, fmt.Errorf("%w: %s", errExtendsCycle, strings.Join(append(seen, abs), " -> "))
+		}
+	}
+	seen = append(seen, abs)
+
+	data, err := resolveClusterFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configuration %s: %w", path, err)
+	}
+
+	encrypted := isSOPSEncrypted(doc)
+	if encrypted {
+		// each file is decrypted on its own as the merged result can't be
+		doc, err = decryptSOPS(data, doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	extends, ok := doc[extendsKey]
+	if !ok {
+		if encrypted {
+			return []interface{}{normalize(doc)}, nil
+		}
+		return []interface{}{data}, nil
+	}
+	delete(doc, extendsKey)
+
+	var bases []string
+	switch v := extends.(type) {
+	case string:
+		bases = []string{v}
+	case []interface{}:
+		for _, b := range v {
+			s, ok := b.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w in %s: must be a file name or a list of file names", errInvalidExtends, path)
+			}
+			bases = append(bases, s)
+		}
+	default:
+		return nil, fmt.Errorf("%w in %s: must be a file name or a list of file names", errInvalidExtends, path)
+	}
+
+	dir := "."
+	if path != "-" {
+		dir = filepath.Dir(path)
+	}
+
+	var layers []interface{}
+	for _, base := range bases {
+		if !filepath.IsAbs(base) {
+			base = filepath.Join(dir, base)
+		}
+		baseLayers, err := loadLayers(base, seen)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, baseLayers...)
+	}
+
+	return append(layers, normalize(doc)), nil
+}
+
+// mergeValues merges the overlay on top of the base:
+//   - mappings are merged key by key, a null value in the overlay removes the key,
+//     secret references replace the value
+//   - spec.hosts entries are matched by their address, matching hosts are merged and
+//     new ones are appended, an entry with $patch: delete removes the host
+//   - in lists of command line flags such as installFlags, the flags of the overlay
+//     replace the flags with the same name in the base and new ones are appended, the
+//     values of repeatable flags such as --san are added to the base
+//   - other values, including other lists, are replaced
+//
+// An error is returned for a $patch other than delete.
+func mergeValues(path string, base, overlay interface{}) (interface{}, error) {
+	switch o := overlay.(type) {
+	case map[interface{}]interface{}:
+		if err := checkPatch(path, o); err != nil {
+			return nil, err
+		}
+		b, ok := base.(map[interface{}]interface{})
+		if _, isSecret := secretRef(o); !ok || isSecret {
+			return withoutPatchKeys(o), nil
+		}
+		for k, v := range o {
+			if v == nil {
+				delete(b, k)
+				continue
+			}
+			merged, err := mergeValues(joinPath(path, fmt.Sprint(k)), b[k], v)
+			if err != nil {
+				return nil, err
+			}
+			b[k] = merged
+		}
+		return b, nil
+	case []interface{}:
+		b, ok := base.([]interface{})
+		if !ok {
+			return o, nil
+		}
+		switch {
+		case path == "spec.hosts":
+			return mergeHosts(b, o)
+		case strings.HasSuffix(path, "Flags"):
+			return mergeFlags(b, o), nil
+		default:
+			return o, nil
+		}
+	default:
+		return overlay, nil
+	}
+}
+
+// checkPatch returns an error when the mapping has a $patch other than delete.
+func checkPatch(path string, m map[interface{}]interface{}) error {
+	patch, ok := m[patchKey]
+	if !ok || patch == patchDelete {
+		return nil
+	}
+	if path == "" {
+		path = "the configuration"
+	}
+	return fmt.Errorf("%w in %s: %v (only %s is supported)", errInvalidPatch, path, patch, patchDelete)
+}
+
+// escapePatchKeys escapes the $patch keys in the mappings of value for the environment
+// variable substitution, the values are left as they are.
+func escapePatchKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			v[k] = escapePatchKeys(val)
+		}
+		if patch, ok := v[patchKey]; ok {
+			delete(v, patchKey)
+			v["$"+patchKey] = patch
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = escapePatchKeys(val)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func withoutPatchKeys(m map[interface{}]interface{}) map[interface{}]interface{} {
+	delete(m, patchKey)
+	return m
+}
+
+func mergeHosts(base, overlay []interface{}) ([]interface{}, error) {
+	result := append([]interface{}{}, base...)
+
+	for _, o := range overlay {
+		host, ok := o.(map[interface{}]interface{})
+		address := hostAddress(o)
+		if !ok || address == "" {
+			result = append(result, o)
+			continue
+		}
+		if err := checkPatch("spec.hosts", host); err != nil {
+			return nil, err
+		}
+
+		index := -1
+		for i, b := range result {
+			if hostAddress(b) == address {
+				index = i
+				break
+			}
+		}
+
+		switch {
+		case host[patchKey] == patchDelete:
+			if index >= 0 {
+				result = append(result[:index], result[index+1:]...)
+			}
+		case index >= 0:
+			merged, err := mergeValues("", result[index], host)
+			if err != nil {
+				return nil, err
+			}
+			result[index] = merged
+		default:
+			result = append(result, withoutPatchKeys(host))
+		}
+	}
+
+	return result, nil
+}
+
+// hostAddress returns the address of a host entry in the configuration.
+func hostAddress(host interface{}) string {
+	h, ok := host.(map[interface{}]interface{})
+	if !ok {
+		return ""
+	}
+	for _, protocol := range []string{"ssh", "winRM", "openSSH"} {
+		if conn, ok := h[protocol].(map[interface{}]interface{}); ok {
+			if address, ok := conn["address"].(string); ok {
+				return address
+			}
+		}
+	}
+	if _, ok := h["localhost"]; ok {
+		return "localhost"
+	}
+	return ""
+}
+
+// mergeFlags merges the overlay flags on top of the base flags. The flags with the same
+// name in the base are replaced where the first of them was, except the repeatable
+// flags, whose values are added unless the base already has them.
+func mergeFlags(base, overlay []interface{}) []interface{} {
+	overlayNames := make(map[string]bool)
+	var repeated []interface{}
+	for _, f := range overlay {
+		name := flagName(f)
+		if !repeatableFlags[name] {
+			overlayNames[name] = true
+			continue
+		}
+		if !containsFlag(base, f) && !containsFlag(repeated, f) {
+			repeated = append(repeated, f)
+		}
+	}
+
+	result := make([]interface{}, 0, len(base)+len(overlay))
+	added := make(map[string]bool)
+	for _, f := range base {
+		name := flagName(f)
+		if !overlayNames[name] {
+			result = append(result, f)
+			continue
+		}
+		// all the flags of the same name are replaced where the first of them was
+		if !added[name] {
+			for _, o := range overlay {
+				if flagName(o) == name {
+					result = append(result, o)
+				}
+			}
+			added[name] = true
+		}
+	}
+	for _, o := range overlay {
+		if name := flagName(o); overlayNames[name] && !added[name] {
+			result = append(result, o)
+		}
+	}
+	return append(result, repeated...)
+}
+
+// containsFlag returns true when the flags include the flag with the same name and value.
+func containsFlag(flags []interface{}, flag interface{}) bool {
+	for _, f := range flags {
+		if fmt.Sprint(f) == fmt.Sprint(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// flagName returns the name part of a flag like --san=10.0.0.1 or --san 10.0.0.1.
+func flagName(flag interface{}) string {
+	s := fmt.Sprint(flag)
+	if i := strings.IndexAny(s, "= "); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Mirantis/launchpad/pkg/product/mke"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const baseConfig = `apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+metadata:
+  name: base
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+        user: root
+    - role: worker
+      ssh:
+        address: 10.0.0.2
+    - role: worker
+      ssh:
+        address: 10.0.0.3
+  mke:
+    version: 3.7.5
+    adminUsername: admin
+    installFlags:
+      - --san=base.example.com
+      - --san=base-lb.example.com
+      - --default-node-orchestrator=kubernetes
+      - --nodeport-range=32768-35535
+  mcr:
+    channel: stable
+`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestProductFromFilesOverlays(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", baseConfig)
+	overlay := writeFile(t, dir, "prod.yaml", `metadata:
+  name: prod
+spec:
+  hosts:
+    - ssh:
+        address: 10.0.0.1
+        user: ubuntu
+    - ssh:
+        address: 10.0.0.3
+      $patch: delete
+    - role: worker
+      ssh:
+        address: 10.0.0.4
+  mke:
+    adminUsername: null
+    installFlags:
+      - --san=prod.example.com
+      - --pod-cidr=10.1.0.0/16
+`)
+
+	product, err := ProductFromFiles([]string{base, overlay})
+	require.NoError(t, err)
+	cfg := product.(*mke.MKE).ClusterConfig
+
+	require.Equal(t, "prod", cfg.Metadata.Name)
+	require.Len(t, cfg.Spec.Hosts, 3)
+	require.Equal(t, "10.0.0.1", cfg.Spec.Hosts[0].Address())
+	require.Equal(t, "manager", cfg.Spec.Hosts[0].Role)
+	require.Equal(t, "ubuntu", cfg.Spec.Hosts[0].SSH.User)
+	require.Equal(t, "10.0.0.2", cfg.Spec.Hosts[1].Address())
+	require.Equal(t, "10.0.0.4", cfg.Spec.Hosts[2].Address())
+	require.Equal(t, "worker", cfg.Spec.Hosts[2].Role)
+
+	require.Empty(t, cfg.Spec.MKE.AdminUsername)
+	require.Equal(t, []string{
+		"--san=base.example.com",
+		"--san=base-lb.example.com",
+		"--default-node-orchestrator=kubernetes",
+		"--nodeport-range=32768-35535",
+		"--pod-cidr=10.1.0.0/16",
+		"--san=prod.example.com",
+	}, []string(cfg.Spec.MKE.InstallFlags))
+}
+
+func TestMergeFlags(t *testing.T) {
+	base := []interface{}{"--san=a.example.com", "--pod-cidr=10.0.0.0/16", "--san=b.example.com"}
+	overlay := []interface{}{"--san=b.example.com", "--pod-cidr=10.1.0.0/16", "--san c.example.com", "--nodeport-range=32768-35535"}
+	require.Equal(t, []interface{}{
+		"--san=a.example.com",
+		"--pod-cidr=10.1.0.0/16",
+		"--san=b.example.com",
+		"--nodeport-range=32768-35535",
+		"--san c.example.com",
+	}, mergeFlags(base, overlay))
+}
+
+func TestProductFromFilesInvalidPatch(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", baseConfig)
+	overlay := writeFile(t, dir, "overlay.yaml", `spec:
+  hosts:
+    - ssh:
+        address: 10.0.0.3
+      $patch: remove
+`)
+
+	_, err := ProductFromFiles([]string{base, overlay})
+	require.ErrorIs(t, err, errInvalidPatch)
+	require.ErrorContains(t, err, "spec.hosts: remove")
+}
+
+func TestProductFromFilesExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.yaml", baseConfig)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "envs"), 0o700))
+	writeFile(t, dir, "envs/common.yaml", `extends: ../base.yaml
+spec:
+  mke:
+    version: 3.7.6
+`)
+	dev := writeFile(t, dir, "envs/dev.yaml", `extends:
+  - common.yaml
+metadata:
+  name: dev
+`)
+
+	product, err := ProductFromFile(dev)
+	require.NoError(t, err)
+	cfg := product.(*mke.MKE).ClusterConfig
+	require.Equal(t, "dev", cfg.Metadata.Name)
+	require.Equal(t, "3.7.6", cfg.Spec.MKE.Version)
+	require.Len(t, cfg.Spec.Hosts, 3)
+}
+
+func TestProductFromFilesExtendsCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.yaml", "extends: b.yaml\n")
+	writeFile(t, dir, "b.yaml", "extends: a.yaml\n")
+
+	_, err := ProductFromFile(a)
+	require.ErrorIs(t, err, errExtendsCycle)
+}
+
+func TestRenderFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", baseConfig)
+	overlay := writeFile(t, dir, "overlay.yaml", `spec:
+  mke:
+    adminPassword:
+      fromEnv: TEST_RENDER_PASSWORD
+    version: $TEST_RENDER_VERSION
+`)
+	t.Setenv("TEST_RENDER_PASSWORD", "render-password")
+	t.Setenv("TEST_RENDER_VERSION", "3.7.9")
+
+	data, err := RenderFiles([]string{base, overlay}, OfflineOptions{})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "render-password")
+
+	var cfg map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	mkeSpec := cfg["spec"].(map[interface{}]interface{})["mke"].(map[interface{}]interface{})
+	require.Equal(t, "3.7.9", mkeSpec["version"])
+	// the secret references are not resolved
+	require.Equal(t, map[interface{}]interface{}{"fromEnv": "TEST_RENDER_PASSWORD"}, mkeSpec["adminPassword"])
+}
+
+func TestValidateFilesMerged(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", baseConfig)
+	overlay := writeFile(t, dir, "overlay.yaml", `spec:
+  hosts:
+    - role: banana
+      ssh:
+        address: 10.0.0.2
+`)
+
+	err := ValidateFiles([]string{base, overlay}, OfflineOptions{})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Problems, 1)
+	require.Equal(t, "spec.hosts[1].role", validationErr.Problems[0].Path)
+	require.Zero(t, validationErr.Problems[0].Line)
+}
+
+func TestLoadYAMLPatchKeys(t *testing.T) {
+	t.Setenv("patch", "substituted")
+	data := []byte(`apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - ssh:
+        address: 10.0.0.1
+      environment:
+        NOTE: "$patch: value"
+      $patch: delete
+`)
+	plain, _, _, err := loadYAML(data, loadOptions{secrets: keepSecretRefs})
+	require.NoError(t, err)
+
+	var cfg map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(plain, &cfg))
+	host := cfg["spec"].(map[interface{}]interface{})["hosts"].([]interface{})[0].(map[interface{}]interface{})
+	// only the key is escaped, the values get the usual substitution
+	require.Equal(t, patchDelete, host[patchKey])
+	require.Equal(t, "substituted: value", host["environment"].(map[interface{}]interface{})["NOTE"])
+}
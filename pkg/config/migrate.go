@@ -0,0 +1,289 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Mirantis/launchpad/pkg/config/migration"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var errMigrateSOPS = errors.New("the configuration is encrypted with SOPS, decrypt it with sops --decrypt before migrating and encrypt the result again")
+
+// MigrationStep describes the changes a single migrator made to a configuration.
+type MigrationStep struct {
+	From    string
+	To      string
+	Changes []string
+}
+
+// Migration is the result of MigrateYAML.
+type Migration struct {
+	// Steps lists the migrators that were run, oldest first. It is empty when the
+	// configuration already is in the current format.
+	Steps []MigrationStep
+	// YAML is the migrated configuration.
+	YAML []byte
+}
+
+// MigrateFile migrates the configuration file the same way as MigrateYAML.
+func MigrateFile(path string) (*Migration, error) {
+	data, err := resolveClusterFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return MigrateYAML(data)
+}
+
+// MigrateYAML runs the configuration migrations on a configuration document and returns
+// the migrated YAML with a summary of what each migrator changed. Unlike loading the
+// configuration, the environment variables are not substituted. The order of the keys
+// and the comments of the original document are kept for the parts the migrations
+// didn't replace.
+func MigrateYAML(data []byte) (*Migration, error) {
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configuration: %w", err)
+	}
+	if isSOPSEncrypted(config) {
+		return nil, errMigrateSOPS
+	}
+	if _, ok := config["apiVersion"].(string); !ok {
+		return nil, errMissingAPIVersion
+	}
+
+	result := &Migration{}
+	err := migration.MigrateSteps(config, func(before map[string]interface{}) {
+		step := MigrationStep{From: fmt.Sprint(before["apiVersion"]), To: fmt.Sprint(config["apiVersion"])}
+		diffValues("", normalize(before), normalize(config), &step.Changes)
+		result.Steps = append(result.Steps, step)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate configuration: %w", err)
+	}
+
+	if len(result.Steps) == 0 {
+		result.YAML = data
+		return result, nil
+	}
+
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil || doc.Kind != yamlv3.DocumentNode || len(doc.Content) == 0 {
+		// should not happen as the document was already parsed once
+		out, err := yaml.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+		}
+		result.YAML = out
+		return result, nil
+	}
+
+	if err := applyValue(doc.Content[0], normalize(config)); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	out := buf.Bytes()
+	if bytes.HasPrefix(data, []byte("---")) && !bytes.HasPrefix(out, []byte("---")) {
+		out = append([]byte("---\n"), out...)
+	}
+	result.YAML = out
+
+	return result, nil
+}
+
+// normalize converts a value to the types the yaml.v2 decoder produces, so that values
+// created by the migrators can be compared to the ones decoded from the document.
+func normalize(value interface{}) interface{} {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var out interface{}
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		return value
+	}
+	return out
+}
+
+// nodeValue decodes a YAML node the same way as normalize.
+func nodeValue(node *yamlv3.Node) (interface{}, bool) {
+	data, err := yamlv3.Marshal(node)
+	if err != nil {
+		return nil, false
+	}
+	var out interface{}
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// applyValue updates the node to represent the value, modifying only the parts of the
+// node that differ from it.
+func applyValue(node *yamlv3.Node, value interface{}) error {
+	if current, ok := nodeValue(node); ok && reflect.DeepEqual(current, value) {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		if node.Kind != yamlv3.MappingNode {
+			break
+		}
+		seen := make(map[string]bool, len(v))
+		content := make([]*yamlv3.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			val, ok := lookupKey(v, key)
+			if !ok {
+				continue
+			}
+			seen[key] = true
+			if err := applyValue(node.Content[i+1], val); err != nil {
+				return err
+			}
+			content = append(content, node.Content[i], node.Content[i+1])
+		}
+		for _, key := range sortedKeys(v) {
+			if seen[key] {
+				continue
+			}
+			var keyNode, valueNode yamlv3.Node
+			if err := keyNode.Encode(key); err != nil {
+				return fmt.Errorf("failed to encode %s: %w", key, err)
+			}
+			if err := valueNode.Encode(v[key]); err != nil {
+				return fmt.Errorf("failed to encode %s: %w", key, err)
+			}
+			content = append(content, &keyNode, &valueNode)
+		}
+		node.Content = content
+		return nil
+	case []interface{}:
+		if node.Kind != yamlv3.SequenceNode || len(node.Content) != len(v) {
+			break
+		}
+		for i, item := range v {
+			if err := applyValue(node.Content[i], item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var replacement yamlv3.Node
+	if err := replacement.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+	replacement.HeadComment = node.HeadComment
+	replacement.LineComment = node.LineComment
+	replacement.FootComment = node.FootComment
+	*node = replacement
+	return nil
+}
+
+func lookupKey(m map[interface{}]interface{}, key string) (interface{}, bool) {
+	for k, v := range m {
+		if fmt.Sprint(k) == key {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func sortedKeys(m map[interface{}]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, fmt.Sprint(k))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var sensitiveKeyRe = regexp.MustCompile(`(?i)password|secret|token|license`)
+
+// diffValues lists the differences between two normalized values as human readable
+// changes, using the same YAML paths as the validation errors.
+func diffValues(path string, before, after interface{}, changes *[]string) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	beforeMap, okBefore := before.(map[interface{}]interface{})
+	afterMap, okAfter := after.(map[interface{}]interface{})
+	if okBefore && okAfter {
+		for _, key := range sortedKeys(beforeMap) {
+			if _, ok := lookupKey(afterMap, key); !ok {
+				*changes = append(*changes, "removed "+joinPath(path, key))
+			}
+		}
+		for _, key := range sortedKeys(afterMap) {
+			afterValue, _ := lookupKey(afterMap, key)
+			beforeValue, ok := lookupKey(beforeMap, key)
+			if !ok {
+				*changes = append(*changes, "added "+joinPath(path, key))
+				continue
+			}
+			diffValues(joinPath(path, key), beforeValue, afterValue, changes)
+		}
+		return
+	}
+
+	beforeList, okBefore := before.([]interface{})
+	afterList, okAfter := after.([]interface{})
+	if okBefore && okAfter && len(beforeList) == len(afterList) {
+		for i := range beforeList {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), beforeList[i], afterList[i], changes)
+		}
+		return
+	}
+
+	if isScalar(before) && isScalar(after) {
+		if sensitiveKeyRe.MatchString(path[strings.LastIndex(path, ".")+1:]) || sensitiveFlag(before) || sensitiveFlag(after) {
+			*changes = append(*changes, "changed "+path)
+			return
+		}
+		*changes = append(*changes, fmt.Sprintf("changed %s: %v -> %v", path, before, after))
+		return
+	}
+
+	*changes = append(*changes, "replaced "+path)
+}
+
+// sensitiveFlag returns true when the value is a command line flag with a sensitive
+// name, such as --admin-password=secret in installFlags.
+func sensitiveFlag(value interface{}) bool {
+	flag, ok := value.(string)
+	return ok && strings.HasPrefix(flag, "-") && sensitiveKeyRe.MatchString(flagName(flag))
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case map[interface{}]interface{}, []interface{}:
+		return false
+	default:
+		return true
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateYAML(t *testing.T) {
+	data := []byte(`---
+# cluster for the staging environment
+apiVersion: launchpad.mirantis.com/mke/v1.4
+kind: mke
+spec:
+  hosts:
+    - role: manager # the first manager
+      ssh:
+        address: 10.0.0.1
+  mke:
+    version: 3.7.3
+    # locked by the security team
+    swarmInstallFlags:
+      - --autolock
+    adminPassword: $MKE_PASSWORD
+  mcr:
+    version: 23.0.8
+    channel: stable
+`)
+
+	result, err := MigrateYAML(data)
+	require.NoError(t, err)
+
+	require.Equal(t, `---
+# cluster for the staging environment
+apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - role: manager # the first manager
+      ssh:
+        address: 10.0.0.1
+  mke:
+    version: 3.7.3
+    adminPassword: $MKE_PASSWORD
+  mcr:
+    channel: stable-23.0.8
+    swarmInstallFlags:
+      - --autolock
+`, string(result.YAML))
+
+	require.Len(t, result.Steps, 2)
+	require.Equal(t, "launchpad.mirantis.com/mke/v1.4", result.Steps[0].From)
+	require.Equal(t, "launchpad.mirantis.com/mke/v1.5", result.Steps[0].To)
+	require.Equal(t, []string{
+		"changed apiVersion: launchpad.mirantis.com/mke/v1.4 -> launchpad.mirantis.com/mke/v1.5",
+		"added spec.mcr.swarmInstallFlags",
+		"removed spec.mke.swarmInstallFlags",
+	}, result.Steps[0].Changes)
+	require.Equal(t, "launchpad.mirantis.com/mke/v1.6", result.Steps[1].To)
+	require.Contains(t, result.Steps[1].Changes, "changed spec.mcr.channel: stable -> stable-23.0.8")
+	require.Contains(t, result.Steps[1].Changes, "removed spec.mcr.version")
+}
+
+func TestMigrateYAMLCurrent(t *testing.T) {
+	data := []byte(`apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+`)
+	result, err := MigrateYAML(data)
+	require.NoError(t, err)
+	require.Empty(t, result.Steps)
+	require.Equal(t, data, result.YAML)
+}
+
+func TestMigrateYAMLRedactsValues(t *testing.T) {
+	data := []byte(`apiVersion: launchpad.mirantis.com/v1
+kind: DockerEnterprise
+spec:
+  ucp:
+    installFlags:
+      - --admin-username=foo
+      - --admin-password=barbar
+`)
+	result, err := MigrateYAML(data)
+	require.NoError(t, err)
+	for _, step := range result.Steps {
+		for _, change := range step.Changes {
+			require.NotContains(t, change, "barbar")
+		}
+	}
+}
+
+func TestDiffValuesRedactsFlags(t *testing.T) {
+	before := map[interface{}]interface{}{"installFlags": []interface{}{"--san=a.example.com", "--admin-password=barbar"}}
+	after := map[interface{}]interface{}{"installFlags": []interface{}{"--san=b.example.com", "--admin-password=bazbaz"}}
+
+	var changes []string
+	diffValues("spec.mke", before, after, &changes)
+	require.Equal(t, []string{
+		"changed spec.mke.installFlags[0]: --san=a.example.com -> --san=b.example.com",
+		"changed spec.mke.installFlags[1]",
+	}, changes)
+}
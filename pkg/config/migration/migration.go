@@ -14,6 +14,12 @@ func Register(apiVersion string, migrator func(map[string]interface{}) error) {
 
 // Migrate will run through the migrations until there is no more migrators found and returns an error if any of the migrations fail.
 func Migrate(data map[string]interface{}) error {
+	return MigrateSteps(data, nil)
+}
+
+// MigrateSteps works like Migrate, and calls step after each migration with a copy of the
+// configuration as it was before the migration.
+func MigrateSteps(data map[string]interface{}, step func(before map[string]interface{})) error {
 	for {
 		migrator, ok := migrators[data["apiVersion"].(string)]
 		if migrator == nil || !ok {
@@ -25,6 +31,11 @@ func Migrate(data map[string]interface{}) error {
 			log.Tracef("migration original: %s", string(y))
 		}
 
+		var before map[string]interface{}
+		if step != nil {
+			before, _ = deepCopy(data).(map[string]interface{})
+		}
+
 		if err := migrator(data); err != nil {
 			return err
 		}
@@ -33,5 +44,34 @@ func Migrate(data map[string]interface{}) error {
 			y, _ := yaml.Marshal(&data)
 			log.Tracef("migration result: %s", string(y))
 		}
+
+		if step != nil {
+			step(before)
+		}
+	}
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = deepCopy(val)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			m[k] = deepCopy(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = deepCopy(val)
+		}
+		return s
+	default:
+		return v
 	}
 }
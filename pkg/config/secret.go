@@ -0,0 +1,278 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	mcclog "github.com/Mirantis/launchpad/pkg/log"
+	"github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// Secret reference keys. A string value in the configuration can be replaced with a
+// mapping that has one of these keys, for example:
+//
+//	adminPassword:
+//	  fromCommand: pass show mke/admin
+const (
+	secretFromFile    = "fromFile"
+	secretFromEnv     = "fromEnv"
+	secretFromCommand = "fromCommand"
+)
+
+// secretCommandTimeout is how long a fromCommand secret reference may take, it can
+// be waiting for a passphrase prompt.
+const secretCommandTimeout = 2 * time.Minute
+
+var (
+	errSecretRef = errors.New("failed to resolve secret reference")
+	errNoSOPS    = errors.New("the configuration is encrypted with SOPS but the sops command was not found")
+	errEnvNotSet = errors.New("environment variable is not set")
+
+	errEmptySecretRef = errors.New("empty secret reference")
+)
+
+// secretMode is what loadYAML does with the secret references.
+type secretMode int
+
+const (
+	// resolveSecretRefs replaces the references with the values they point to, for the
+	// commands that connect to the hosts.
+	resolveSecretRefs secretMode = iota
+	// checkSecretRefs replaces the references with a placeholder without reading the
+	// files and the environment or running the commands, for the offline validation.
+	checkSecretRefs
+	// keepSecretRefs leaves the references as they are.
+	keepSecretRefs
+)
+
+// secretPlaceholder is the value of the secret references that are not resolved.
+const secretPlaceholder = "[SECRET]"
+
+// resolveSecrets replaces the secret references in the configuration with the values
+// they point to, and registers the values for redaction, or handles them as mode
+// says. It returns the number of references replaced.
+func resolveSecrets(config map[string]interface{}, mode secretMode) (int, error) {
+	count := 0
+	for key, value := range config {
+		resolved, n, err := resolveSecretValue(key, value, mode)
+		if err != nil {
+			return count, err
+		}
+		config[key] = resolved
+		count += n
+	}
+	return count, nil
+}
+
+func resolveSecretValue(path string, value interface{}, mode secretMode) (interface{}, int, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		if ref, ok := secretRef(v); ok {
+			if ref.value == "" {
+				return nil, 0, fmt.Errorf("%w: %s: %w: %s", errSecretRef, path, errEmptySecretRef, ref.kind)
+			}
+			switch mode {
+			case keepSecretRefs:
+				return v, 0, nil
+			case checkSecretRefs:
+				return secretPlaceholder, 1, nil
+			}
+			secret, err := ref.resolve()
+			if err != nil {
+				return nil, 0, fmt.Errorf("%w: %s: %w", errSecretRef, path, err)
+			}
+			mcclog.RegisterSecret(secret)
+			log.Debugf("resolved secret reference %s from %s", path, ref.kind)
+			return secret, 1, nil
+		}
+		count := 0
+		for key, val := range v {
+			resolved, n, err := resolveSecretValue(joinPath(path, fmt.Sprint(key)), val, mode)
+			if err != nil {
+				return nil, count, err
+			}
+			v[key] = resolved
+			count += n
+		}
+		return v, count, nil
+	case []interface{}:
+		count := 0
+		for i, val := range v {
+			resolved, n, err := resolveSecretValue(fmt.Sprintf("%s[%d]", path, i), val, mode)
+			if err != nil {
+				return nil, count, err
+			}
+			v[i] = resolved
+			count += n
+		}
+		return v, count, nil
+	default:
+		return value, 0, nil
+	}
+}
+
+type secretReference struct {
+	kind  string
+	value string
+}
+
+// secretRef returns the secret reference if the mapping is one.
+func secretRef(m map[interface{}]interface{}) (secretReference, bool) {
+	if len(m) != 1 {
+		return secretReference{}, false
+	}
+	for k, v := range m {
+		key, _ := k.(string)
+		value, ok := v.(string)
+		if !ok {
+			return secretReference{}, false
+		}
+		switch key {
+		case secretFromFile, secretFromEnv, secretFromCommand:
+			return secretReference{kind: key, value: value}, true
+		}
+	}
+	return secretReference{}, false
+}
+
+func (r secretReference) resolve() (string, error) {
+	switch r.kind {
+	case secretFromFile:
+		path, err := homedir.Expand(r.value)
+		if err != nil {
+			return "", fmt.Errorf("failed to expand path %s: %w", r.value, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case secretFromEnv:
+		value, ok := os.LookupEnv(r.value)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", errEnvNotSet, r.value)
+		}
+		return value, nil
+	default:
+		out, err := runSecretCommand(r.value)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+}
+
+// runSecretCommand runs a command in the local shell and returns its output. The
+// command can prompt on the terminal, for example for a GPG passphrase.
+func runSecretCommand(command string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("command %q failed: %w", command, err)
+	}
+	return out, nil
+}
+
+// isSOPSEncrypted returns true if the document has the metadata SOPS adds to the
+// files it encrypts.
+func isSOPSEncrypted(config map[string]interface{}) bool {
+	meta, ok := config["sops"].(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+	_, hasMAC := meta["mac"]
+	return hasMAC
+}
+
+// decryptSOPS decrypts a SOPS encrypted configuration with the sops command, which
+// finds the age, PGP or KMS keys the usual way, for example from SOPS_AGE_KEY_FILE.
+// The decrypted values are registered for redaction.
+func decryptSOPS(data []byte, encrypted map[string]interface{}) (map[string]interface{}, error) {
+	sops, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, errNoSOPS
+	}
+
+	// sops detects the format from the file extension
+	tmp, err := os.CreateTemp("", "launchpad-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(sops, "--decrypt", tmp.Name()) //nolint:gosec // runs the sops binary found in PATH
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt configuration with sops: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	decrypted := make(map[string]interface{})
+	if err := yaml.Unmarshal(out, decrypted); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decrypted configuration: %w", err)
+	}
+
+	var values []string
+	collectDecrypted(normalize(encrypted), normalize(decrypted), &values)
+	mcclog.RegisterSecret(values...)
+
+	return decrypted, nil
+}
+
+// collectDecrypted collects the decrypted values of the fields that were encrypted.
+func collectDecrypted(encrypted, decrypted interface{}, values *[]string) {
+	switch enc := encrypted.(type) {
+	case string:
+		if strings.HasPrefix(enc, "ENC[") {
+			*values = append(*values, fmt.Sprint(decrypted))
+		}
+	case map[interface{}]interface{}:
+		dec, ok := decrypted.(map[interface{}]interface{})
+		if !ok {
+			return
+		}
+		for k, v := range enc {
+			collectDecrypted(v, dec[k], values)
+		}
+	case []interface{}:
+		dec, ok := decrypted.([]interface{})
+		if !ok {
+			return
+		}
+		for i := range enc {
+			if i < len(dec) {
+				collectDecrypted(enc[i], dec[i], values)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	mcclog "github.com/Mirantis/launchpad/pkg/log"
+	"github.com/Mirantis/launchpad/pkg/product/mke"
+	"github.com/stretchr/testify/require"
+)
+
+const secretConfig = `apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+metadata:
+  name: test
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+  mke:
+    version: 3.7.5
+    adminUsername:
+      fromEnv: TEST_MKE_ADMIN
+    adminPassword:
+      fromFile: %s
+  mcr:
+    channel: stable
+    license:
+      fromCommand: echo license-from-command
+`
+
+func TestProductFromYAMLSecrets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("password-from-file\n"), 0o600))
+	t.Setenv("TEST_MKE_ADMIN", "admin-from-env")
+
+	product, err := ProductFromYAML([]byte(fmt.Sprintf(secretConfig, passwordFile)))
+	require.NoError(t, err)
+
+	cfg := product.(*mke.MKE).ClusterConfig
+	require.Equal(t, "admin-from-env", cfg.Spec.MKE.AdminUsername)
+	require.Equal(t, "password-from-file", cfg.Spec.MKE.AdminPassword)
+	require.Equal(t, "license-from-command", cfg.Spec.MCR.License)
+
+	require.Equal(t, "user [REDACTED] password [REDACTED]", mcclog.Redact("user admin-from-env password password-from-file"))
+}
+
+func TestProductFromYAMLSecretErrors(t *testing.T) {
+	_, err := ProductFromYAML([]byte(fmt.Sprintf(secretConfig, "/nonexistent/password")))
+	require.ErrorIs(t, err, errSecretRef)
+	require.ErrorContains(t, err, "spec.mke")
+}
+
+func TestValidateSecrets(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+	data := strings.Replace(fmt.Sprintf(secretConfig, filepath.Join(dir, "nonexistent")), "echo license-from-command", "touch "+marker, 1)
+
+	// the references are not resolved, the file and the variable don't need to exist
+	require.NoError(t, Validate([]byte(data)))
+	require.NoFileExists(t, marker, "validate ran a fromCommand secret")
+
+	err := Validate([]byte(strings.Replace(data, "fromEnv: TEST_MKE_ADMIN", "fromEnv: ''", 1)))
+	require.ErrorContains(t, err, "empty secret reference")
+}
+
+func TestProductFromYAMLSOPS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+
+	// a fake sops that "decrypts" by replacing the ENC[] value
+	dir := t.TempDir()
+	script := "#!/bin/sh\n[ \"$1\" = \"--decrypt\" ] || exit 1\nsed -e 's/ENC\\[AES256_GCM,data:abc\\]/sops-secret-value/' -e '/^sops:/,$d' \"$2\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sops"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	data := []byte(`apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+  mke:
+    version: 3.7.5
+    adminPassword: ENC[AES256_GCM,data:abc]
+  mcr:
+    channel: stable
+sops:
+  mac: ENC[AES256_GCM,data:def]
+  version: 3.9.0
+`)
+	product, err := ProductFromYAML(data)
+	require.NoError(t, err)
+	require.Equal(t, "sops-secret-value", product.(*mke.MKE).ClusterConfig.Spec.MKE.AdminPassword)
+	require.Equal(t, "[REDACTED]", mcclog.Redact("sops-secret-value"))
+
+	_, err = MigrateYAML(data)
+	require.ErrorIs(t, err, errMigrateSOPS)
+}
+
+func TestProductFromYAMLSOPSNotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := ProductFromYAML([]byte(`apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+sops:
+  mac: foo
+`))
+	require.ErrorIs(t, err, errNoSOPS)
+}
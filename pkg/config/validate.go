@@ -0,0 +1,221 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	common "github.com/Mirantis/launchpad/pkg/product/common/config"
+	"github.com/Mirantis/launchpad/pkg/product/mke"
+	"github.com/a8m/envsubst"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Problem is an error found in a configuration.
+type Problem struct {
+	// Path is the YAML path of the field, for example spec.hosts[0].role, if known.
+	Path string
+	// Line is the line of the field in the configuration file, if known.
+	Line    int
+	Message string
+}
+
+// String returns the problem prefixed with its line and path.
+func (p Problem) String() string {
+	var sb strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&sb, "line %d: ", p.Line)
+	}
+	if p.Path != "" {
+		sb.WriteString(p.Path + ": ")
+	}
+	sb.WriteString(p.Message)
+	return sb.String()
+}
+
+// ValidationError is returned by Validate when problems are found in a configuration.
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Error lists the problems.
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		lines = append(lines, p.String())
+	}
+	return "invalid configuration:\n" + strings.Join(lines, "\n")
+}
+
+// ValidateFile validates the configuration file the same way as Validate.
+func ValidateFile(path string) error {
+	return ValidateFiles([]string{path}, OfflineOptions{})
+}
+
+// ValidateFiles merges the configuration files the same way as ProductFromFiles and
+// validates the result the same way as Validate. The problems of merged files have no
+// line numbers.
+func ValidateFiles(paths []string, opts OfflineOptions) error {
+	data, merged, err := loadFiles(paths)
+	if err != nil {
+		return &ValidationError{Problems: []Problem{problemFromError(err)}}
+	}
+	if !merged {
+		return validate(data, opts)
+	}
+	err = validate(data, opts)
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		for i := range validationErr.Problems {
+			validationErr.Problems[i].Line = 0
+		}
+	}
+	return err
+}
+
+var lineRe = regexp.MustCompile(`line (\d+): (.*)`)
+
+// Validate loads a configuration the same way apply does, running the migrations, the
+// environment variable substitution and the strict unmarshalling, and then runs the
+// validations that don't need a connection to the hosts. The secret references are
+// checked but not resolved and the exec inventory sources are not run, validating a
+// configuration never runs its commands. The problems found are returned as a
+// *ValidationError.
+func Validate(data []byte) error {
+	return validate(data, OfflineOptions{})
+}
+
+func validate(data []byte, opts OfflineOptions) error {
+	plain, kind, rewritten, err := loadYAML(data, loadOptions{secrets: checkSecretRefs, execInventory: opts.ExecInventory})
+	if err != nil {
+		return &ValidationError{Problems: []Problem{problemFromError(err)}}
+	}
+
+	if !rewritten {
+		// validate the original document so that the parser errors refer to its lines
+		if original, err := envsubst.Bytes(data); err == nil {
+			plain = original
+		}
+	}
+
+	switch kind {
+	case "mke", "mke+msr":
+		err = mke.ValidateConfig(plain)
+	default:
+		err = &common.FieldError{Path: "kind", Err: fmt.Errorf("%w: %s", errUnknownConfigKind, kind)}
+	}
+	if err == nil {
+		return nil
+	}
+
+	// used for looking up the lines of the fields in the original document
+	var root yamlv3.Node
+	_ = yamlv3.Unmarshal(data, &root)
+
+	var problems []Problem
+	for _, err := range flattenErrors(err) {
+		var fieldErr *common.FieldError
+		var typeErr *yaml.TypeError
+		switch {
+		case errors.As(err, &fieldErr):
+			problems = append(problems, Problem{Path: fieldErr.Path, Line: lineOf(&root, fieldErr.Path), Message: fieldErr.Err.Error()})
+		case errors.As(err, &typeErr):
+			for _, msg := range typeErr.Errors {
+				p := problemFromMessage(msg)
+				if rewritten {
+					// the line numbers refer to the rewritten configuration
+					p.Line = 0
+				}
+				problems = append(problems, p)
+			}
+		default:
+			problems = append(problems, problemFromError(err))
+		}
+	}
+
+	return &ValidationError{Problems: problems}
+}
+
+// flattenErrors returns the errors joined with errors.Join as a list.
+func flattenErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, flattenErrors(e)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+func problemFromError(err error) Problem {
+	return problemFromMessage(err.Error())
+}
+
+// problemFromMessage extracts the line number from the yaml parser error messages.
+func problemFromMessage(msg string) Problem {
+	if m := lineRe.FindStringSubmatch(msg); m != nil {
+		if line, err := strconv.Atoi(m[1]); err == nil {
+			return Problem{Line: line, Message: m[2]}
+		}
+	}
+	return Problem{Message: msg}
+}
+
+// lineOf returns the line of the node at the YAML path, or of its closest ancestor
+// that exists in the document.
+func lineOf(root *yamlv3.Node, path string) int {
+	node := root
+	if node.Kind == yamlv3.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line := node.Line
+
+	for _, segment := range strings.Split(path, ".") {
+		name, index, hasIndex := strings.Cut(segment, "[")
+		key, next := mappingEntry(node, name)
+		if next == nil {
+			return line
+		}
+		node, line = next, key.Line
+
+		if hasIndex {
+			index = strings.TrimSuffix(index, "]")
+			switch node.Kind { //nolint:exhaustive
+			case yamlv3.SequenceNode:
+				i, err := strconv.Atoi(index)
+				if err != nil || i < 0 || i >= len(node.Content) {
+					return line
+				}
+				node = node.Content[i]
+				line = node.Line
+			case yamlv3.MappingNode:
+				key, next := mappingEntry(node, index)
+				if next == nil {
+					return line
+				}
+				node, line = next, key.Line
+			default:
+				return line
+			}
+		}
+	}
+
+	return line
+}
+
+// mappingEntry returns the key and value nodes of a key in a mapping node.
+func mappingEntry(node *yamlv3.Node, key string) (*yamlv3.Node, *yamlv3.Node) {
+	if node.Kind != yamlv3.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	data := []byte(`apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+metadata:
+  name: test
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+    - role: banana
+      ssh:
+        address: 10.0.0.2
+  mke:
+    version: 3.7.5
+    installFlags:
+      - --pod-cidr=10.1.0.0/16
+  mcr:
+    channel: stable
+`)
+
+	err := Validate(data)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Problems, 2)
+
+	require.Equal(t, "spec.hosts[1].role", validationErr.Problems[0].Path)
+	require.Equal(t, 10, validationErr.Problems[0].Line)
+	require.Contains(t, validationErr.Problems[0].Message, "banana is not one of")
+
+	require.Equal(t, "spec.mke.installFlags", validationErr.Problems[1].Path)
+	require.Equal(t, 15, validationErr.Problems[1].Line)
+	require.Contains(t, validationErr.Problems[1].Message, "overlaps with the Swarm overlay address pool")
+}
+
+func TestValidateUnknownField(t *testing.T) {
+	data := []byte(`apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+      foo: bar
+  mke:
+    version: 3.7.5
+  mcr:
+    channel: stable
+`)
+
+	err := Validate(data)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Problems, 1)
+	require.Equal(t, 8, validationErr.Problems[0].Line)
+	require.Contains(t, validationErr.Problems[0].Message, "field foo not found")
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	err := Validate([]byte("apiVersion: launchpad.mirantis.com/mke/v1.6\nkind: mke\n  spec: foo: bar\n"))
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Problems, 1)
+	require.Equal(t, 3, validationErr.Problems[0].Line)
+}
+
+func TestValidateValid(t *testing.T) {
+	data := []byte(`apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+  mke:
+    version: 3.7.5
+  mcr:
+    channel: stable
+`)
+	require.NoError(t, Validate(data))
+}
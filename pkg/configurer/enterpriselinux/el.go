@@ -119,3 +119,43 @@ func (c Configurer) isAWSInstance(h os.Host) bool {
 	}
 	return strings.Contains(found, "region")
 }
+
+// elRebootRequiredCmd prints yes when a reboot is required, with needs-restarting from
+// yum-utils when it is installed or otherwise by comparing the running kernel to the
+// newest installed one.
+const elRebootRequiredCmd = `if command -v needs-restarting > /dev/null 2>&1; then needs-restarting -r > /dev/null 2>&1 && echo no || echo yes; else [ "kernel-$(uname -r)" = "$(rpm -q --last kernel | head -n 1 | cut -d ' ' -f 1)" ] && echo no || echo yes; fi`
+
+// UpdatePackages upgrades the installed packages with yum and returns the changed
+// packages. The MCR packages are excluded from the upgrade when holdMCR is set.
+func (c Configurer) UpdatePackages(h os.Host, holdMCR bool) ([]string, error) {
+	before, err := h.ExecOutput(configurer.RpmInventoryCmd)
+	if err != nil {
+		return nil, fmt.Errorf("list installed packages: %w", err)
+	}
+
+	cmd := "yum -y upgrade"
+	if holdMCR {
+		for _, name := range configurer.MCRPackages {
+			cmd += " --exclude=" + name
+		}
+	}
+	if err := h.Exec(cmd, exec.Sudo(h)); err != nil {
+		return nil, fmt.Errorf("yum upgrade: %w", err)
+	}
+
+	after, err := h.ExecOutput(configurer.RpmInventoryCmd)
+	if err != nil {
+		return nil, fmt.Errorf("list installed packages: %w", err)
+	}
+	return configurer.ChangedPackages(before, after), nil
+}
+
+// RebootRequired returns true when the running kernel or core libraries have been
+// updated since the last boot.
+func (c Configurer) RebootRequired(h os.Host) (bool, error) {
+	out, err := h.ExecOutput(elRebootRequiredCmd, exec.Sudo(h))
+	if err != nil {
+		return false, fmt.Errorf("check if reboot is required: %w", err)
+	}
+	return strings.TrimSpace(out) == "yes", nil
+}
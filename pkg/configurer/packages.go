@@ -0,0 +1,88 @@
+package configurer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MCRPackages are the packages of the container runtime, the OS package updates hold
+// them back unless MCR is included.
+var MCRPackages = []string{"docker-ee", "docker-ee-cli", "docker-ee-rootless-extras", "containerd.io"}
+
+const (
+	// DpkgInventoryCmd lists the installed deb packages as "name version" lines.
+	DpkgInventoryCmd = `dpkg-query -W -f '${db:Status-Abbrev} ${Package} ${Version}\n' | awk '$1 == "ii" {print $2, $3}'`
+	// RpmInventoryCmd lists the installed rpm packages as "name version" lines.
+	RpmInventoryCmd = `rpm -qa --qf '%{NAME} %{VERSION}-%{RELEASE}.%{ARCH}\n'`
+)
+
+// ParsePackages parses a package inventory of "name version" lines into the installed
+// versions of each package. A package can have several versions installed, such as the
+// kernel on the rpm based distributions.
+func ParsePackages(inventory string) map[string][]string {
+	packages := make(map[string][]string)
+	for _, line := range strings.Split(inventory, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		packages[fields[0]] = append(packages[fields[0]], fields[1])
+	}
+	return packages
+}
+
+// ChangedPackages compares the package inventories from before and after an update and
+// returns the upgraded, installed and removed packages as sorted human readable lines.
+func ChangedPackages(before, after string) []string {
+	old := ParsePackages(before)
+	updated := ParsePackages(after)
+
+	names := make(map[string]struct{}, len(updated))
+	for name := range old {
+		names[name] = struct{}{}
+	}
+	for name := range updated {
+		names[name] = struct{}{}
+	}
+
+	var changes []string
+	for name := range names {
+		removed := versionsNotIn(old[name], updated[name])
+		added := versionsNotIn(updated[name], old[name])
+		switch {
+		case len(removed) > 0 && len(added) > 0:
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", name, strings.Join(removed, ","), strings.Join(added, ",")))
+		case len(added) > 0:
+			changes = append(changes, fmt.Sprintf("%s %s (installed)", name, strings.Join(added, ",")))
+		case len(removed) > 0:
+			changes = append(changes, fmt.Sprintf("%s %s (removed)", name, strings.Join(removed, ",")))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// UnheldMCRPackages returns the MCR packages in the inventory that are not in held, the
+// packages an update needs to hold back and release afterwards.
+func UnheldMCRPackages(inventory string, held []string) []string {
+	installed := ParsePackages(inventory)
+	var hold []string
+	for _, name := range MCRPackages {
+		if _, ok := installed[name]; ok && !slices.Contains(held, name) {
+			hold = append(hold, name)
+		}
+	}
+	return hold
+}
+
+func versionsNotIn(versions, other []string) []string {
+	var result []string
+	for _, v := range versions {
+		if !slices.Contains(other, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
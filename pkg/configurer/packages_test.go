@@ -0,0 +1,35 @@
+package configurer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedPackages(t *testing.T) {
+	before := `openssl 3.0.2-0ubuntu1.15
+kernel 5.14.0-427.el9.x86_64
+kernel 5.14.0-503.el9.x86_64
+libfoo 1.0
+docker-ee 25.0.5
+`
+	after := `openssl 3.0.2-0ubuntu1.18
+kernel 5.14.0-503.el9.x86_64
+kernel 5.14.0-570.el9.x86_64
+libbar 2.1
+docker-ee 25.0.5
+`
+	require.Equal(t, []string{
+		"kernel 5.14.0-427.el9.x86_64 -> 5.14.0-570.el9.x86_64",
+		"libbar 2.1 (installed)",
+		"libfoo 1.0 (removed)",
+		"openssl 3.0.2-0ubuntu1.15 -> 3.0.2-0ubuntu1.18",
+	}, ChangedPackages(before, after))
+	require.Empty(t, ChangedPackages(before, before))
+}
+
+func TestUnheldMCRPackages(t *testing.T) {
+	inventory := "docker-ee 25.0.5\ndocker-ee-cli 25.0.5\ncontainerd.io 1.7.17\nopenssl 3.0.2\n"
+	require.Equal(t, []string{"docker-ee", "containerd.io"}, UnheldMCRPackages(inventory, []string{"docker-ee-cli"}))
+	require.Empty(t, UnheldMCRPackages("openssl 3.0.2\n", nil))
+}
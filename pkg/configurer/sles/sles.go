@@ -129,3 +129,67 @@ func (c Configurer) UninstallMCR(h os.Host, engineConfig commonconfig.MCRConfig)
 
 	return nil
 }
+
+// zypperNeedsRebooting is the exit code of zypper needs-rebooting when a reboot is required.
+const zypperNeedsRebooting = "102"
+
+// UpdatePackages updates the installed packages with zypper and returns the changed
+// packages. The MCR packages are locked for the update when holdMCR is set.
+func (c Configurer) UpdatePackages(h os.Host, holdMCR bool) ([]string, error) {
+	before, err := h.ExecOutput(configurer.RpmInventoryCmd)
+	if err != nil {
+		return nil, fmt.Errorf("list installed packages: %w", err)
+	}
+
+	if holdMCR {
+		locks, err := h.ExecOutput("zypper --quiet locks")
+		if err != nil {
+			return nil, fmt.Errorf("list zypper locks: %w", err)
+		}
+		if hold := configurer.UnheldMCRPackages(before, lockedPackages(locks)); len(hold) > 0 {
+			if err := h.Exec("zypper --non-interactive addlock "+strings.Join(hold, " "), exec.Sudo(h)); err != nil {
+				return nil, fmt.Errorf("lock MCR packages: %w", err)
+			}
+			defer func() {
+				if err := h.Exec("zypper --non-interactive removelock "+strings.Join(hold, " "), exec.Sudo(h)); err != nil {
+					log.Warnf("%s: failed to remove the locks of the MCR packages: %s", h, err)
+				}
+			}()
+		}
+	}
+
+	if err := h.Exec("zypper --non-interactive update --auto-agree-with-licenses", exec.Sudo(h)); err != nil {
+		return nil, fmt.Errorf("zypper update: %w", err)
+	}
+
+	after, err := h.ExecOutput(configurer.RpmInventoryCmd)
+	if err != nil {
+		return nil, fmt.Errorf("list installed packages: %w", err)
+	}
+	return configurer.ChangedPackages(before, after), nil
+}
+
+// RebootRequired returns true when zypper reports that core libraries or services have
+// been updated since the last boot.
+func (c Configurer) RebootRequired(h os.Host) (bool, error) {
+	out, err := h.ExecOutput("zypper needs-rebooting > /dev/null 2>&1; echo $?", exec.Sudo(h))
+	if err != nil {
+		return false, fmt.Errorf("check if reboot is required: %w", err)
+	}
+	return strings.TrimSpace(out) == zypperNeedsRebooting, nil
+}
+
+// lockedPackages returns the package names in the zypper locks table.
+func lockedPackages(locks string) []string {
+	var names []string
+	for _, line := range strings.Split(locks, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) < 2 {
+			continue
+		}
+		if name := strings.TrimSpace(fields[1]); name != "" && name != "Name" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -2,12 +2,14 @@ package ubuntu
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Mirantis/launchpad/pkg/configurer"
 	commonconfig "github.com/Mirantis/launchpad/pkg/product/common/config"
 	"github.com/k0sproject/rig/exec"
 	"github.com/k0sproject/rig/os"
 	"github.com/k0sproject/rig/os/linux"
+	log "github.com/sirupsen/logrus"
 )
 
 // Configurer is a generic Ubuntu level configurer implementation. Some of the configurer interface implementation
@@ -116,3 +118,49 @@ func (c Configurer) UninstallMCR(h os.Host, engineConfig commonconfig.MCRConfig)
 
 	return nil
 }
+
+// UpdatePackages upgrades the installed packages with apt and returns the changed
+// packages. The MCR packages are held back with apt-mark for the upgrade when holdMCR
+// is set.
+func (c Configurer) UpdatePackages(h os.Host, holdMCR bool) ([]string, error) {
+	before, err := h.ExecOutput(configurer.DpkgInventoryCmd)
+	if err != nil {
+		return nil, fmt.Errorf("list installed packages: %w", err)
+	}
+
+	if holdMCR {
+		held, err := h.ExecOutput("apt-mark showhold")
+		if err != nil {
+			return nil, fmt.Errorf("list held packages: %w", err)
+		}
+		if hold := configurer.UnheldMCRPackages(before, strings.Fields(held)); len(hold) > 0 {
+			if err := h.Exec("apt-mark hold "+strings.Join(hold, " "), exec.Sudo(h)); err != nil {
+				return nil, fmt.Errorf("hold MCR packages: %w", err)
+			}
+			defer func() {
+				if err := h.Exec("apt-mark unhold "+strings.Join(hold, " "), exec.Sudo(h)); err != nil {
+					log.Warnf("%s: failed to release the hold on the MCR packages: %s", h, err)
+				}
+			}()
+		}
+	}
+
+	if err := h.Exec("DEBIAN_FRONTEND=noninteractive apt-get update", exec.Sudo(h)); err != nil {
+		return nil, fmt.Errorf("could not update apt package info: %w", err)
+	}
+	if err := h.Exec("DEBIAN_FRONTEND=noninteractive apt-get -y -o Dpkg::Options::=--force-confdef -o Dpkg::Options::=--force-confold --with-new-pkgs upgrade", exec.Sudo(h)); err != nil {
+		return nil, fmt.Errorf("apt-get upgrade: %w", err)
+	}
+
+	after, err := h.ExecOutput(configurer.DpkgInventoryCmd)
+	if err != nil {
+		return nil, fmt.Errorf("list installed packages: %w", err)
+	}
+	return configurer.ChangedPackages(before, after), nil
+}
+
+// RebootRequired returns true when the packages installed since the last boot have
+// flagged that a reboot is required.
+func (c Configurer) RebootRequired(h os.Host) (bool, error) {
+	return c.FileExist(h, "/var/run/reboot-required"), nil
+}
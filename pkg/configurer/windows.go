@@ -2,6 +2,8 @@ package configurer
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"path"
@@ -106,11 +108,6 @@ func (c WindowsConfigurer) InstallMCR(h os.Host, engineConfig commonconfig.MCRCo
 		if err := rh.Reboot(); err != nil {
 			return fmt.Errorf("%s: failed to reboot host: %w", h, err)
 		}
-		// Machine is back up. Delete the ONSTART scheduled task so it does not
-		// trigger another reboot on subsequent startups.
-		if err := h.Exec(`schtasks /delete /tn "LaunchpadReboot" /f`); err != nil {
-			log.Warnf("%s: failed to clean up LaunchpadReboot task: %s", h, err)
-		}
 		return nil
 	}
 
@@ -134,21 +131,16 @@ func isExitCode3010(err error) bool {
 //
 // /sc onstart is used instead of /sc once to avoid schtasks writing a
 // stderr warning about the start time being in the past, which rig treats
-// as an error. The task is deleted immediately after triggering (while the
-// 5-second timer counts down) so it does not re-fire on subsequent startups.
-// A post-reboot cleanup in InstallMCR provides a second deletion attempt as
-// a fallback.
+// as an error. The task would re-fire on subsequent startups, the host deletes
+// it with CleanUpReboot once it is back up.
 //
 // TODO: move this fix upstream into the k0sproject/rig Windows configurer.
 func (c WindowsConfigurer) Reboot(h os.Host) error {
-	const taskName = "LaunchpadReboot"
-	// The ONSTART trigger means the task will re-fire on the next startup, but
-	// the post-reboot cleanup in InstallMCR deletes it once the host is back up.
-	create := fmt.Sprintf(`schtasks /create /tn "%s" /tr "shutdown /r /f /t 5" /sc onstart /f /ru SYSTEM`, taskName)
+	create := fmt.Sprintf(`schtasks /create /tn "%s" /tr "shutdown /r /f /t 5" /sc onstart /f /ru SYSTEM`, windowsRebootTask)
 	if err := h.Exec(create); err != nil {
 		return fmt.Errorf("failed to create reboot task: %w", err)
 	}
-	run := fmt.Sprintf(`schtasks /run /tn "%s"`, taskName)
+	run := fmt.Sprintf(`schtasks /run /tn "%s"`, windowsRebootTask)
 	if err := h.Exec(run); err != nil {
 		// Tolerate connection-level errors; the OS may kill WinRM as it starts
 		// rebooting before the run command returns.
@@ -162,6 +154,12 @@ func (c WindowsConfigurer) Reboot(h os.Host) error {
 	return nil
 }
 
+// CleanUpReboot deletes the scheduled task of Reboot once the host is back up, so that
+// it does not reboot the host again on the next startup.
+func (c WindowsConfigurer) CleanUpReboot(h os.Host) error {
+	return deleteScheduledTask(h, windowsRebootTask)
+}
+
 // UninstallMCR uninstalls docker-ee engine
 // This relies on using the http://get.mirantis.com/install.ps1 script with the '-Uninstall' option, and some cleanup as per
 // https://docs.microsoft.com/en-us/virtualization/windowscontainers/manage-docker/configure-docker-daemon#how-to-uninstall-docker
@@ -403,3 +401,191 @@ func (c WindowsConfigurer) attemptPathDelete(h os.Host, path string) {
 		log.Infof("%s: removed %s successfully", h, path)
 	}
 }
+
+const (
+	// windowsRebootTask is the scheduled task that reboots the host as SYSTEM.
+	windowsRebootTask = "LaunchpadReboot"
+	// windowsUpdateTask is the scheduled task that runs Windows Update as SYSTEM.
+	windowsUpdateTask = "LaunchpadUpdate"
+	// windowsUpdateScriptPath and windowsUpdateResultPath are the update script the task
+	// runs and the JSON result it writes when it is done.
+	windowsUpdateScriptPath = `C:\Windows\Temp\launchpad-update.ps1`
+	windowsUpdateResultPath = `C:\Windows\Temp\launchpad-update.json`
+	// windowsUpdateTimeout is how long the updates may take to download and install.
+	windowsUpdateTimeout      = 3 * time.Hour
+	windowsUpdatePollInterval = 15 * time.Second
+)
+
+// windowsUpdateScript searches, downloads and installs the pending software updates with
+// the Windows Update Agent API and writes the result codes of the download, the
+// installation and each of the updates as JSON to the result file. The result file is
+// moved in place once complete.
+const windowsUpdateScript = `
+$ErrorActionPreference = 'Stop'
+$out = '` + windowsUpdateResultPath + `'
+$result = @{ resultCode = 2; updates = @() }
+try {
+  $session = New-Object -ComObject Microsoft.Update.Session
+  $search = $session.CreateUpdateSearcher().Search("IsInstalled=0 and Type='Software' and IsHidden=0")
+  $updates = New-Object -ComObject Microsoft.Update.UpdateColl
+  foreach ($update in $search.Updates) {
+    if ($update.InstallationBehavior.CanRequestUserInput) { continue }
+    if (-not $update.EulaAccepted) { $update.AcceptEula() }
+    [void]$updates.Add($update)
+  }
+  if ($updates.Count -gt 0) {
+    $downloader = $session.CreateUpdateDownloader()
+    $downloader.Updates = $updates
+    $result.downloadResultCode = [int]$downloader.Download().ResultCode
+    $installer = $session.CreateUpdateInstaller()
+    $installer.Updates = $updates
+    $installation = $installer.Install()
+    $result.resultCode = [int]$installation.ResultCode
+    for ($i = 0; $i -lt $updates.Count; $i++) {
+      $result.updates += @{ title = $updates.Item($i).Title; resultCode = [int]$installation.GetUpdateResult($i).ResultCode }
+    }
+  }
+} catch {
+  $result.error = $_.Exception.Message
+}
+ConvertTo-Json -InputObject $result -Depth 3 | Set-Content -Path "$out.tmp" -Encoding UTF8
+Move-Item -Force -Path "$out.tmp" -Destination $out
+`
+
+// windowsRebootPendingScript prints True when Windows Update or the component based
+// servicing has a reboot pending.
+const windowsRebootPendingScript = `(Test-Path 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\WindowsUpdate\Auto Update\RebootRequired') -or (Test-Path 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\Component Based Servicing\RebootPending')`
+
+// The result codes of the Windows Update Agent operations (OperationResultCode).
+const (
+	wuaSucceeded           = 2
+	wuaSucceededWithErrors = 3
+	wuaFailed              = 4
+	wuaAborted             = 5
+)
+
+var errWindowsUpdate = errors.New("windows update failed")
+
+// windowsUpdateResult is the result file of windowsUpdateScript.
+type windowsUpdateResult struct {
+	Error              string `json:"error"`
+	DownloadResultCode int    `json:"downloadResultCode"`
+	ResultCode         int    `json:"resultCode"`
+	Updates            []struct {
+		Title      string `json:"title"`
+		ResultCode int    `json:"resultCode"`
+	} `json:"updates"`
+}
+
+// UpdatePackages installs the pending Windows updates and returns their titles. MCR is
+// not distributed through Windows Update, so there is nothing to hold back.
+//
+// The Windows Update Agent refuses to download and install updates in a WinRM session,
+// so like Reboot, the updates are installed by a scheduled task that runs as SYSTEM.
+// The task writes its result to a file, which is polled until the task is done.
+func (c WindowsConfigurer) UpdatePackages(h os.Host, _ bool) ([]string, error) {
+	if err := c.WriteFile(h, windowsUpdateScriptPath, windowsUpdateScript, "0700"); err != nil {
+		return nil, fmt.Errorf("write windows update script: %w", err)
+	}
+	defer func() {
+		for _, file := range []string{windowsUpdateScriptPath, windowsUpdateResultPath} {
+			if err := c.DeleteFile(h, file); err != nil {
+				log.Debugf("%s: failed to delete %s: %s", h, file, err)
+			}
+		}
+	}()
+	if c.FileExist(h, windowsUpdateResultPath) {
+		if err := c.DeleteFile(h, windowsUpdateResultPath); err != nil {
+			return nil, fmt.Errorf("delete previous windows update result: %w", err)
+		}
+	}
+
+	command := "powershell.exe -NoProfile -NonInteractive -ExecutionPolicy Bypass -File " + windowsUpdateScriptPath
+	if err := runSystemTask(h, windowsUpdateTask, command); err != nil {
+		return nil, fmt.Errorf("start windows update: %w", err)
+	}
+	defer func() {
+		if err := deleteScheduledTask(h, windowsUpdateTask); err != nil {
+			log.Warnf("%s: %s", h, err)
+		}
+	}()
+
+	log.Infof("%s: waiting for windows update to finish", h)
+	deadline := time.Now().Add(windowsUpdateTimeout)
+	for !c.FileExist(h, windowsUpdateResultPath) {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: timed out after %s", errWindowsUpdate, windowsUpdateTimeout)
+		}
+		time.Sleep(windowsUpdatePollInterval)
+	}
+	output, err := c.ReadFile(h, windowsUpdateResultPath)
+	if err != nil {
+		return nil, fmt.Errorf("read windows update result: %w", err)
+	}
+	return parseWindowsUpdateResult(output)
+}
+
+// parseWindowsUpdateResult returns the titles of the installed updates from the result
+// of windowsUpdateScript. An error is returned when the download or the installation
+// did not fully succeed, along with the updates that were installed.
+func parseWindowsUpdateResult(output string) ([]string, error) {
+	var result windowsUpdateResult
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(output), "\ufeff")), &result); err != nil {
+		return nil, fmt.Errorf("parse windows update result: %w", err)
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("%w: %s", errWindowsUpdate, result.Error)
+	}
+
+	var installed, failed []string
+	for _, u := range result.Updates {
+		if u.ResultCode == wuaSucceeded {
+			installed = append(installed, u.Title)
+		} else {
+			failed = append(failed, fmt.Sprintf("%s (result code %d)", u.Title, u.ResultCode))
+		}
+	}
+
+	switch {
+	case result.DownloadResultCode == wuaFailed || result.DownloadResultCode == wuaAborted:
+		return installed, fmt.Errorf("%w: the download ended with result code %d", errWindowsUpdate, result.DownloadResultCode)
+	case result.ResultCode == wuaSucceededWithErrors:
+		return installed, fmt.Errorf("%w: only some of the updates were installed, failed: %s", errWindowsUpdate, strings.Join(failed, ", "))
+	case result.ResultCode != wuaSucceeded:
+		return installed, fmt.Errorf("%w: the installation ended with result code %d, failed: %s", errWindowsUpdate, result.ResultCode, strings.Join(failed, ", "))
+	}
+	return installed, nil
+}
+
+// runSystemTask creates a scheduled task that runs the command as SYSTEM and starts it.
+// The task is not deleted, it has an ONSTART trigger that makes it run again on the next
+// startup until it is deleted with deleteScheduledTask. ONSTART is used instead of ONCE
+// to avoid the warning schtasks writes to stderr about a start time in the past, which
+// rig treats as an error.
+func runSystemTask(h os.Host, name, command string) error {
+	create := fmt.Sprintf(`schtasks /create /tn "%s" /tr "%s" /sc onstart /f /ru SYSTEM`, name, command)
+	if err := h.Exec(create); err != nil {
+		return fmt.Errorf("failed to create scheduled task %s: %w", name, err)
+	}
+	if err := h.Exec(fmt.Sprintf(`schtasks /run /tn "%s"`, name)); err != nil {
+		return fmt.Errorf("failed to run scheduled task %s: %w", name, err)
+	}
+	return nil
+}
+
+// deleteScheduledTask deletes a scheduled task created with runSystemTask.
+func deleteScheduledTask(h os.Host, name string) error {
+	if err := h.Exec(fmt.Sprintf(`schtasks /delete /tn "%s" /f`, name)); err != nil {
+		return fmt.Errorf("failed to delete scheduled task %s: %w", name, err)
+	}
+	return nil
+}
+
+// RebootRequired returns true when a reboot is pending to finish installing updates.
+func (c WindowsConfigurer) RebootRequired(h os.Host) (bool, error) {
+	output, err := h.ExecOutput(ps.Cmd(windowsRebootPendingScript))
+	if err != nil {
+		return false, fmt.Errorf("check pending reboot: %w", err)
+	}
+	return strings.EqualFold(strings.TrimSpace(output), "true"), nil
+}
@@ -0,0 +1,58 @@
+package configurer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWindowsUpdateResult(t *testing.T) {
+	t.Run("succeeded", func(t *testing.T) {
+		installed, err := parseWindowsUpdateResult("\ufeff" + `{
+  "downloadResultCode": 2,
+  "resultCode": 2,
+  "updates": [
+    {"title": "2024-05 Cumulative Update", "resultCode": 2},
+    {"title": "Security Intelligence Update", "resultCode": 2}
+  ]
+}`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"2024-05 Cumulative Update", "Security Intelligence Update"}, installed)
+	})
+
+	t.Run("nothing to install", func(t *testing.T) {
+		installed, err := parseWindowsUpdateResult(`{"resultCode": 2, "updates": []}`)
+		require.NoError(t, err)
+		require.Empty(t, installed)
+	})
+
+	t.Run("partially succeeded", func(t *testing.T) {
+		installed, err := parseWindowsUpdateResult(`{
+  "downloadResultCode": 2,
+  "resultCode": 3,
+  "updates": [
+    {"title": "2024-05 Cumulative Update", "resultCode": 4},
+    {"title": "Security Intelligence Update", "resultCode": 2}
+  ]
+}`)
+		require.ErrorIs(t, err, errWindowsUpdate)
+		require.ErrorContains(t, err, "2024-05 Cumulative Update (result code 4)")
+		require.Equal(t, []string{"Security Intelligence Update"}, installed)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		_, err := parseWindowsUpdateResult(`{"downloadResultCode": 2, "resultCode": 4, "updates": [{"title": "KB1", "resultCode": 4}]}`)
+		require.ErrorIs(t, err, errWindowsUpdate)
+	})
+
+	t.Run("download failed", func(t *testing.T) {
+		_, err := parseWindowsUpdateResult(`{"downloadResultCode": 4, "resultCode": 2}`)
+		require.ErrorIs(t, err, errWindowsUpdate)
+	})
+
+	t.Run("script error", func(t *testing.T) {
+		_, err := parseWindowsUpdateResult(`{"resultCode": 2, "error": "Exception from HRESULT: 0x80240438"}`)
+		require.ErrorIs(t, err, errWindowsUpdate)
+		require.ErrorContains(t, err, "0x80240438")
+	})
+}
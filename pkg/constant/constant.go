@@ -13,10 +13,22 @@ const (
 	MCRInstallURLWindows = "https://get.mirantis.com/install.ps1"
 	// StateBaseDir defines the base dir for all local state.
 	StateBaseDir = ".mirantis-launchpad"
+	// LabelPrefix is the prefix of the node labels launchpad uses itself.
+	LabelPrefix = "com.mirantis.launchpad."
+	// ManagedLabel is the label of the nodes managed by launchpad.
+	ManagedLabel = LabelPrefix + "managed"
+	// ManagedMSRLabel is the label of the MSR nodes managed by launchpad.
+	ManagedMSRLabel = LabelPrefix + "managed.dtr"
+	// ManagedLabelsLabel lists the keys of the spec.hosts[*].labels launchpad has set on the node.
+	ManagedLabelsLabel = LabelPrefix + "labels"
+	// ManagedTaintsLabel lists the key:effect of the spec.hosts[*].taints launchpad has set on the node.
+	ManagedTaintsLabel = LabelPrefix + "taints"
+	// SANsLabel lists the MKE --san addresses on the manager nodes.
+	SANsLabel = "com.docker.ucp.SANs"
 	// ManagedLabelCmd marks the node as being managed by launchpad.
-	ManagedLabelCmd = "node update --label-add com.mirantis.launchpad.managed=true"
+	ManagedLabelCmd = "node update --label-add " + ManagedLabel + "=true"
 	// ManagedMSRLabelCmd marks a MSR node as being managed by launchpad.
-	ManagedMSRLabelCmd = "node update --label-add com.mirantis.launchpad.managed.dtr=true"
+	ManagedMSRLabelCmd = "node update --label-add " + ManagedMSRLabel + "=true"
 	// LinuxDefaultDockerRoot defines the default docker root.
 	LinuxDefaultDockerRoot = "/var/lib/docker"
 	// LinuxDefaultDockerExecRoot defines the default docker exec root.
@@ -2,6 +2,7 @@ package hub
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,14 +20,22 @@ type tagListResponse struct {
 	} `json:"results"`
 }
 
-var errQueryFailed = fmt.Errorf("latest version query failed, you can try running with --disable-upgrade-check")
+var (
+	errQueryFailed    = fmt.Errorf("latest version query failed, you can try running with --disable-upgrade-check")
+	errTagQueryFailed = errors.New("tag query failed")
+)
+
+// hubURL is the address of the Docker Hub API.
+var hubURL = "https://hub.docker.com"
+
+const queryTimeout = 5 * time.Second
 
 // LatestTag returns the latest tag name from a public docker hub repository.
 // If pre is true, also prereleases are considered.
 func LatestTag(org, image string, pre bool) (string, error) {
-	url := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/%s/tags", org, image)
+	url := fmt.Sprintf("%s/v2/repositories/%s/%s/tags", hubURL, org, image)
 	client := http.Client{
-		Timeout: time.Second * 5,
+		Timeout: queryTimeout,
 	}
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -78,3 +87,33 @@ func LatestTag(org, image string, pre bool) (string, error) {
 	sort.Sort(version.Collection(tags))
 	return tags[len(tags)-1].String(), nil
 }
+
+// TagExists returns true if the tag exists in a public docker hub repository.
+func TagExists(org, image, tag string) (bool, error) {
+	url := fmt.Sprintf("%s/v2/repositories/%s/%s/tags/%s", hubURL, org, image, tag)
+	client := http.Client{
+		Timeout: queryTimeout,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", errTagQueryFailed, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	// url is from the Docker Hub API (trusted source)
+	res, err := client.Do(req) // #nosec G704
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", errTagQueryFailed, err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return false, nil
+	case res.StatusCode > 299 || res.StatusCode < http.StatusOK:
+		return false, fmt.Errorf("%w: response status %d", errTagQueryFailed, res.StatusCode)
+	default:
+		return true, nil
+	}
+}
@@ -0,0 +1,57 @@
+package hub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(t *testing.T) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/repositories/mirantis/ucp/tags":
+			_, _ = w.Write([]byte(`{"count":4,"results":[{"name":"3.7.1"},{"name":"3.8.0-rc1"},{"name":"3.7.10"},{"name":"latest"}]}`))
+		case "/v2/repositories/mirantis/ucp/tags/3.7.1":
+			_, _ = w.Write([]byte(`{"name":"3.7.1"}`))
+		case "/v2/repositories/mirantis/ucp/tags/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	orig := hubURL
+	hubURL = server.URL
+	t.Cleanup(func() { hubURL = orig })
+}
+
+func TestLatestTag(t *testing.T) {
+	testServer(t)
+
+	tag, err := LatestTag("mirantis", "ucp", false)
+	require.NoError(t, err)
+	require.Equal(t, "3.7.10", tag)
+
+	tag, err = LatestTag("mirantis", "ucp", true)
+	require.NoError(t, err)
+	require.Equal(t, "3.8.0-rc1", tag)
+}
+
+func TestTagExists(t *testing.T) {
+	testServer(t)
+
+	exists, err := TagExists("mirantis", "ucp", "3.7.1")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = TagExists("mirantis", "ucp", "3.7.2")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	_, err = TagExists("mirantis", "ucp", "broken")
+	require.Error(t, err)
+}
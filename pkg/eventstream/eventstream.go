@@ -0,0 +1,133 @@
+// Package eventstream provides a machine-readable stream of events describing the
+// progress of a launchpad run. Each event is written as a single line of JSON.
+package eventstream
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	mcclog "github.com/Mirantis/launchpad/pkg/log"
+)
+
+const (
+	// TypeRunStart is emitted when a command starts.
+	TypeRunStart = "run_start"
+	// TypeRunFinish is emitted when a command finishes.
+	TypeRunFinish = "run_finish"
+	// TypePhaseStart is emitted when a phase starts running.
+	TypePhaseStart = "phase_start"
+	// TypePhaseFinish is emitted when a phase has finished running.
+	TypePhaseFinish = "phase_finish"
+	// TypePhaseSkip is emitted when a phase is not run.
+	TypePhaseSkip = "phase_skip"
+	// TypeHostResult is emitted with the outcome of a phase step on a single host.
+	TypeHostResult = "host_result"
+	// TypeClusterInfo is emitted with the cluster information at the end of a successful apply.
+	TypeClusterInfo = "cluster_info"
+)
+
+// Event is a single entry in the event stream.
+type Event struct {
+	Time     time.Time              `json:"time"`
+	Type     string                 `json:"type"`
+	Command  string                 `json:"command,omitempty"`
+	Phase    string                 `json:"phase,omitempty"`
+	Host     string                 `json:"host,omitempty"`
+	Duration float64                `json:"duration,omitempty"`
+	Success  *bool                  `json:"success,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Reason   string                 `json:"reason,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	encoder *json.Encoder
+)
+
+// SetOutput sets the writer the events are written to. Passing nil disables the stream.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if w == nil {
+		encoder = nil
+		return
+	}
+	encoder = json.NewEncoder(w)
+}
+
+// Enabled returns true when the event stream has an output.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return encoder != nil
+}
+
+// Emit writes an event to the stream if it is enabled.
+func Emit(e Event) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if encoder == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	e.Error = mcclog.Redact(e.Error)
+	e.Reason = mcclog.Redact(e.Reason)
+	if e.Data != nil {
+		data := make(map[string]interface{}, len(e.Data))
+		for k, v := range e.Data {
+			data[k] = redactValue(v)
+		}
+		e.Data = data
+	}
+	// errors are ignored, the event stream must never break the run
+	_ = encoder.Encode(e)
+}
+
+// Result returns an event with the success and error fields set based on err.
+func Result(e Event, err error) Event {
+	success := err == nil
+	e.Success = &success
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// redactValue returns a copy of an event data value with the registered secrets
+// redacted from its strings.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return mcclog.Redact(v)
+	case error:
+		return mcclog.Redact(v.Error())
+	case []string:
+		redacted := make([]string, len(v))
+		for i, s := range v {
+			redacted[i] = mcclog.Redact(s)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, val := range v {
+			redacted[i] = redactValue(val)
+		}
+		return redacted
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			redacted[k] = redactValue(val)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,57 @@
+package eventstream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	mcclog "github.com/Mirantis/launchpad/pkg/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	SetOutput(buf)
+	defer SetOutput(nil)
+
+	require.True(t, Enabled())
+	Emit(Event{Type: TypeRunStart, Command: "apply"})
+	Emit(Result(Event{Type: TypeHostResult, Phase: "Install", Host: "h1"}, nil))
+	Emit(Result(Event{Type: TypeHostResult, Phase: "Install", Host: "h2"}, errors.New("boom")))
+
+	var events []Event
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 3)
+
+	require.Equal(t, TypeRunStart, events[0].Type)
+	require.False(t, events[0].Time.IsZero())
+	require.Nil(t, events[0].Success)
+
+	require.True(t, *events[1].Success)
+	require.False(t, *events[2].Success)
+	require.Equal(t, "boom", events[2].Error)
+}
+
+func TestEmitDisabled(t *testing.T) {
+	SetOutput(nil)
+	require.False(t, Enabled())
+	Emit(Event{Type: TypeRunStart})
+}
+
+func TestEmitRedacts(t *testing.T) {
+	mcclog.RegisterSecret("event-s3cr3t")
+	buf := &bytes.Buffer{}
+	SetOutput(buf)
+	defer SetOutput(nil)
+
+	Emit(Result(Event{Type: TypeHostResult, Data: map[string]interface{}{"command": "login -p event-s3cr3t"}}, errors.New("login -p event-s3cr3t failed")))
+	require.NotContains(t, buf.String(), "event-s3cr3t")
+	require.Contains(t, buf.String(), "[REDACTED]")
+}
@@ -0,0 +1,395 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+	"github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultAnsibleGroups maps the usual group names to roles when no groups are given.
+var defaultAnsibleGroups = map[string]string{
+	"manager":  "manager",
+	"managers": "manager",
+	"worker":   "worker",
+	"workers":  "worker",
+	"msr":      "msr",
+	"msrs":     "msr",
+}
+
+var (
+	errInvalidInventory = errors.New("invalid ansible inventory")
+	errRoleConflict     = errors.New("host belongs to groups with different roles")
+)
+
+// ansibleGroup is a group of an Ansible inventory.
+type ansibleGroup struct {
+	hosts    []string
+	children []string
+	vars     map[string]string
+}
+
+// ansibleInventory is a parsed Ansible inventory.
+type ansibleInventory struct {
+	groups   map[string]*ansibleGroup
+	hostVars map[string]map[string]string
+	// hosts are in the order they first appear in the file
+	hosts []string
+}
+
+func newAnsibleInventory() *ansibleInventory {
+	return &ansibleInventory{
+		groups:   make(map[string]*ansibleGroup),
+		hostVars: make(map[string]map[string]string),
+	}
+}
+
+func (inv *ansibleInventory) group(name string) *ansibleGroup {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &ansibleGroup{vars: make(map[string]string)}
+		inv.groups[name] = g
+	}
+	return g
+}
+
+func (inv *ansibleInventory) addHost(group, name string, vars map[string]string) {
+	if _, ok := inv.hostVars[name]; !ok {
+		inv.hostVars[name] = make(map[string]string)
+		inv.hosts = append(inv.hosts, name)
+	}
+	for k, v := range vars {
+		inv.hostVars[name][k] = v
+	}
+	g := inv.group(group)
+	for _, h := range g.hosts {
+		if h == name {
+			return
+		}
+	}
+	g.hosts = append(g.hosts, name)
+}
+
+// load reads the inventory, INI or YAML depending on the file extension, and returns the
+// hosts that belong to a group that is mapped to a role.
+func (a *Ansible) load() ([]interface{}, error) {
+	path, err := homedir.Expand(a.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path %s: %w", a.Path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ansible inventory: %w", err)
+	}
+
+	var inv *ansibleInventory
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		inv, err = parseAnsibleYAML(data)
+	default:
+		inv, err = parseAnsibleINI(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ansible inventory %s: %w", a.Path, err)
+	}
+
+	groups := a.Groups
+	if len(groups) == 0 {
+		groups = defaultAnsibleGroups
+	}
+	return inv.launchpadHosts(groups)
+}
+
+// parseAnsibleINI parses an inventory in the INI format, with [group], [group:vars] and
+// [group:children] sections.
+func parseAnsibleINI(data []byte) (*ansibleInventory, error) {
+	inv := newAnsibleInventory()
+	section, kind := "ungrouped", "hosts"
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%w: line %d: unterminated section %s", errInvalidInventory, lineNo, line)
+			}
+			section, kind, _ = strings.Cut(strings.Trim(line, "[]"), ":")
+			if kind == "" {
+				kind = "hosts"
+			}
+			inv.group(section)
+			continue
+		}
+
+		switch kind {
+		case "hosts":
+			fields, err := shellwords.Parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", errInvalidInventory, lineNo, err)
+			}
+			vars := make(map[string]string, len(fields)-1)
+			for _, field := range fields[1:] {
+				k, v, ok := strings.Cut(field, "=")
+				if !ok {
+					return nil, fmt.Errorf("%w: line %d: expected key=value, got %s", errInvalidInventory, lineNo, field)
+				}
+				vars[k] = v
+			}
+			inv.addHost(section, fields[0], vars)
+		case "vars":
+			k, v, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("%w: line %d: expected key=value, got %s", errInvalidInventory, lineNo, line)
+			}
+			inv.group(section).vars[strings.TrimSpace(k)] = strings.Trim(strings.TrimSpace(v), `"'`)
+		case "children":
+			g := inv.group(section)
+			g.children = append(g.children, line)
+			inv.group(line)
+		default:
+			return nil, fmt.Errorf("%w: line %d: unknown section type %s", errInvalidInventory, lineNo, kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+	return inv, nil
+}
+
+// parseAnsibleYAML parses an inventory in the YAML format, where each group can have
+// hosts, vars and children.
+func parseAnsibleYAML(data []byte) (*ansibleInventory, error) {
+	var doc interface{}
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	groups, ok := doc.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a mapping of groups", errInvalidInventory)
+	}
+
+	inv := newAnsibleInventory()
+	for _, name := range sortedKeys(groups) {
+		if err := inv.parseYAMLGroup(fmt.Sprint(name), groups[name]); err != nil {
+			return nil, err
+		}
+	}
+	return inv, nil
+}
+
+func (inv *ansibleInventory) parseYAMLGroup(name string, value interface{}) error {
+	g := inv.group(name)
+	if value == nil {
+		return nil
+	}
+	def, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return fmt.Errorf("%w: group %s: expected a mapping", errInvalidInventory, name)
+	}
+
+	vars, err := stringVars(def["vars"])
+	if err != nil {
+		return fmt.Errorf("group %s vars: %w", name, err)
+	}
+	for k, v := range vars {
+		g.vars[k] = v
+	}
+
+	if hosts, ok := def["hosts"].(map[interface{}]interface{}); ok {
+		for _, host := range sortedKeys(hosts) {
+			hostVars, err := stringVars(hosts[host])
+			if err != nil {
+				return fmt.Errorf("host %v: %w", host, err)
+			}
+			inv.addHost(name, fmt.Sprint(host), hostVars)
+		}
+	}
+
+	if children, ok := def["children"].(map[interface{}]interface{}); ok {
+		for _, child := range sortedKeys(children) {
+			childName := fmt.Sprint(child)
+			g.children = append(g.children, childName)
+			if err := inv.parseYAMLGroup(childName, children[child]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func stringVars(value interface{}) (map[string]string, error) {
+	vars := make(map[string]string)
+	if value == nil {
+		return vars, nil
+	}
+	m, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a mapping of variables", errInvalidInventory)
+	}
+	for k, v := range m {
+		vars[fmt.Sprint(k)] = fmt.Sprint(v)
+	}
+	return vars, nil
+}
+
+// contains returns true if the host is in the group or in one of its children.
+func (inv *ansibleInventory) contains(group, host string, seen map[string]bool) bool {
+	if seen[group] {
+		return false
+	}
+	seen[group] = true
+	g, ok := inv.groups[group]
+	if !ok {
+		return false
+	}
+	for _, h := range g.hosts {
+		if h == host {
+			return true
+		}
+	}
+	for _, child := range g.children {
+		if inv.contains(child, host, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// depths returns the depth of each group in the group tree, the all group being the root.
+// Like in Ansible, the variables of the deeper groups take precedence.
+func (inv *ansibleInventory) depths() map[string]int {
+	depths := make(map[string]int, len(inv.groups))
+	var walk func(name string, depth int)
+	walk = func(name string, depth int) {
+		if d, ok := depths[name]; ok && d >= depth {
+			return
+		}
+		depths[name] = depth
+		if g, ok := inv.groups[name]; ok {
+			for _, child := range g.children {
+				if child != name && depth < len(inv.groups) {
+					walk(child, depth+1)
+				}
+			}
+		}
+	}
+	walk("all", 0)
+	for name := range inv.groups {
+		if _, ok := depths[name]; !ok {
+			walk(name, 1)
+		}
+	}
+	return depths
+}
+
+// launchpadHosts returns the hosts that belong to a group in the mapping, with the role
+// of the group, in the spec.hosts format.
+func (inv *ansibleInventory) launchpadHosts(roles map[string]string) ([]interface{}, error) {
+	depths := inv.depths()
+	groupNames := make([]string, 0, len(inv.groups))
+	for name := range inv.groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Slice(groupNames, func(i, j int) bool {
+		if depths[groupNames[i]] != depths[groupNames[j]] {
+			return depths[groupNames[i]] < depths[groupNames[j]]
+		}
+		return groupNames[i] < groupNames[j]
+	})
+
+	var hosts []interface{}
+	for _, name := range inv.hosts {
+		vars := make(map[string]string)
+		role, roleGroup := "", ""
+		for _, group := range groupNames {
+			if group != "all" && !inv.contains(group, name, make(map[string]bool)) {
+				continue
+			}
+			for k, v := range inv.groups[group].vars {
+				vars[k] = v
+			}
+			groupRole, ok := roles[group]
+			if !ok {
+				continue
+			}
+			if role != "" && role != groupRole {
+				return nil, fmt.Errorf("%w: %s is in %s (%s) and %s (%s)", errRoleConflict, name, roleGroup, role, group, groupRole)
+			}
+			role, roleGroup = groupRole, group
+		}
+		if role == "" {
+			log.Debugf("ansible inventory: skipping host %s, it is not in a group mapped to a role", name)
+			continue
+		}
+		for k, v := range inv.hostVars[name] {
+			vars[k] = v
+		}
+		hosts = append(hosts, ansibleHost(name, role, vars))
+	}
+	return hosts, nil
+}
+
+// ansibleHost converts the Ansible connection variables of a host to a spec.hosts entry.
+func ansibleHost(name, role string, vars map[string]string) map[interface{}]interface{} {
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v, ok := vars[k]; ok && v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	address := first("ansible_host", "ansible_ssh_host")
+	if address == "" {
+		address = name
+	}
+
+	conn := map[interface{}]interface{}{"address": address}
+	setString := func(key string, value string) {
+		if value != "" {
+			conn[key] = value
+		}
+	}
+	setPort := func(value string) {
+		if port, err := strconv.Atoi(value); err == nil {
+			conn["port"] = port
+		}
+	}
+
+	host := map[interface{}]interface{}{"role": role}
+	switch first("ansible_connection") {
+	case "local":
+		host["localhost"] = map[interface{}]interface{}{"enabled": true}
+	case "winrm", "psrp":
+		setString("user", first("ansible_user", "ansible_winrm_user"))
+		setString("password", first("ansible_password", "ansible_winrm_password"))
+		setPort(first("ansible_port", "ansible_winrm_port"))
+		port, _ := strconv.Atoi(first("ansible_port", "ansible_winrm_port"))
+		scheme := first("ansible_winrm_scheme")
+		conn["useHTTPS"] = scheme == "https" || (scheme == "" && port == 5986)
+		conn["insecure"] = first("ansible_winrm_server_cert_validation") == "ignore"
+		host["winRM"] = conn
+	default:
+		setString("user", first("ansible_user", "ansible_ssh_user"))
+		setString("keyPath", first("ansible_ssh_private_key_file", "ansible_private_key_file"))
+		setPort(first("ansible_port", "ansible_ssh_port"))
+		host["ssh"] = conn
+	}
+	return host
+}
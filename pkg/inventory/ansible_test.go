@@ -0,0 +1,79 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnsibleINI(t *testing.T) {
+	path := writeFile(t, "hosts", `# cluster hosts
+bastion.example.com
+
+[managers]
+mgr-0 ansible_host=10.0.0.1
+mgr-1 ansible_host=10.0.0.2 ansible_user="centos"
+
+[nodes]
+wrk-0 ansible_host=10.0.0.3 ansible_port=2222
+win-0 ansible_host=10.0.0.9 ansible_connection=winrm ansible_password=secret ansible_port=5986 ansible_winrm_server_cert_validation=ignore
+
+[cluster:children]
+managers
+nodes
+
+[cluster:vars]
+ansible_user = ubuntu
+ansible_ssh_private_key_file=~/.ssh/cluster
+`)
+
+	hosts, err := Load([]Source{{Ansible: &Ansible{Path: path, Groups: map[string]string{"managers": "manager", "nodes": "worker"}}}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[interface{}]interface{}{"role": "manager", "ssh": map[interface{}]interface{}{"address": "10.0.0.1", "user": "ubuntu", "keyPath": "~/.ssh/cluster"}},
+		map[interface{}]interface{}{"role": "manager", "ssh": map[interface{}]interface{}{"address": "10.0.0.2", "user": "centos", "keyPath": "~/.ssh/cluster"}},
+		map[interface{}]interface{}{"role": "worker", "ssh": map[interface{}]interface{}{"address": "10.0.0.3", "user": "ubuntu", "keyPath": "~/.ssh/cluster", "port": 2222}},
+		map[interface{}]interface{}{"role": "worker", "winRM": map[interface{}]interface{}{"address": "10.0.0.9", "user": "ubuntu", "password": "secret", "port": 5986, "useHTTPS": true, "insecure": true}},
+	}, hosts)
+}
+
+func TestAnsibleYAML(t *testing.T) {
+	path := writeFile(t, "inventory.yml", `all:
+  vars:
+    ansible_user: root
+  children:
+    managers:
+      hosts:
+        10.0.0.1:
+    workers:
+      vars:
+        ansible_user: ubuntu
+      hosts:
+        wrk-0:
+          ansible_host: 10.0.0.2
+          ansible_port: 2222
+    monitoring:
+      hosts:
+        10.0.0.5:
+`)
+
+	// the default groups are used when none are given
+	hosts, err := Load([]Source{{Ansible: &Ansible{Path: path}}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[interface{}]interface{}{"role": "manager", "ssh": map[interface{}]interface{}{"address": "10.0.0.1", "user": "root"}},
+		map[interface{}]interface{}{"role": "worker", "ssh": map[interface{}]interface{}{"address": "10.0.0.2", "user": "ubuntu", "port": 2222}},
+	}, hosts)
+}
+
+func TestAnsibleRoleConflict(t *testing.T) {
+	path := writeFile(t, "hosts.ini", `[managers]
+10.0.0.1
+
+[workers]
+10.0.0.1
+`)
+
+	_, err := Load([]Source{{Ansible: &Ansible{Path: path}}})
+	require.ErrorIs(t, err, errRoleConflict)
+}
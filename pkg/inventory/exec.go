@@ -0,0 +1,42 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// execTimeout is how long the inventory command may take.
+const execTimeout = 5 * time.Minute
+
+// load runs the command in the local shell and reads the hosts from its output.
+func (e *Exec) load() ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", e.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", e.Command)
+	}
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("inventory command %q failed: %w", e.Command, err)
+	}
+
+	var doc interface{}
+	if err := unmarshal(out, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of inventory command %q: %w", e.Command, err)
+	}
+	hosts, err := hostsFromValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("inventory command %q: %w", e.Command, err)
+	}
+	return hosts, nil
+}
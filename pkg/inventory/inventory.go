@@ -0,0 +1,252 @@
+// Package inventory reads the hosts of a cluster from external sources, such as a
+// Terraform state, an Ansible inventory or the output of a local command. The hosts are
+// returned in the format of the spec.hosts entries of the configuration.
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// Source is an entry of spec.inventory. Exactly one of the fields is set.
+type Source struct {
+	Terraform *Terraform `yaml:"terraform,omitempty" jsonschema:"description=Read the hosts from a Terraform state or the output of terraform output -json"`
+	Ansible   *Ansible   `yaml:"ansible,omitempty" jsonschema:"description=Read the hosts from an Ansible inventory in the INI or YAML format"`
+	Exec      *Exec      `yaml:"exec,omitempty" jsonschema:"description=Read the hosts from the JSON or YAML printed by a local command"`
+}
+
+// Terraform reads the hosts from an output of a Terraform state file or of a file
+// written with terraform output -json.
+type Terraform struct {
+	Path   string `yaml:"path" validate:"required" jsonschema:"description=Path of the state file or the terraform output -json file"`
+	Output string `yaml:"output,omitempty" default:"hosts" jsonschema:"description=Name of the output that has the hosts, a list of hosts or a launchpad configuration"`
+}
+
+// Ansible reads the hosts from an Ansible inventory file. The roles of the hosts come
+// from the groups they belong to.
+type Ansible struct {
+	Path   string            `yaml:"path" validate:"required" jsonschema:"description=Path of the inventory file"`
+	Groups map[string]string `yaml:"groups,omitempty" validate:"dive,oneof=manager worker msr" jsonschema:"description=Maps the inventory groups to host roles"`
+}
+
+// Exec reads the hosts from the output of a local command.
+type Exec struct {
+	Command string `yaml:"command" validate:"required" jsonschema:"description=Command that prints the hosts as JSON or YAML"`
+}
+
+var (
+	errInvalidSource = errors.New("invalid inventory source")
+	errInvalidHosts  = errors.New("invalid inventory hosts")
+)
+
+// Load reads the hosts from the sources, in order. The hosts are mappings in the format
+// of the spec.hosts entries, as decoded by gopkg.in/yaml.v2.
+func Load(sources []Source) ([]interface{}, error) {
+	hosts, _, err := LoadSkipping(sources, nil)
+	return hosts, err
+}
+
+// LoadSkipping reads the hosts like Load, except from the sources skip returns true for,
+// which are returned as they are.
+func LoadSkipping(sources []Source, skip func(Source) bool) ([]interface{}, []Source, error) {
+	var (
+		hosts   []interface{}
+		skipped []Source
+	)
+	for i, source := range sources {
+		if skip != nil && skip(source) {
+			skipped = append(skipped, source)
+			continue
+		}
+		sourceHosts, err := source.load()
+		if err != nil {
+			return nil, nil, fmt.Errorf("spec.inventory[%d]: %w", i, err)
+		}
+		hosts = append(hosts, sourceHosts...)
+	}
+	return hosts, skipped, nil
+}
+
+func (s Source) load() ([]interface{}, error) {
+	count := 0
+	for _, set := range []bool{s.Terraform != nil, s.Ansible != nil, s.Exec != nil} {
+		if set {
+			count++
+		}
+	}
+	if count != 1 {
+		return nil, fmt.Errorf("%w: exactly one of terraform, ansible or exec must be given", errInvalidSource)
+	}
+
+	var (
+		hosts []interface{}
+		err   error
+		from  string
+	)
+	switch {
+	case s.Terraform != nil:
+		from = "terraform state " + s.Terraform.Path
+		hosts, err = s.Terraform.load()
+	case s.Ansible != nil:
+		from = "ansible inventory " + s.Ansible.Path
+		hosts, err = s.Ansible.load()
+	default:
+		from = "command " + s.Exec.Command
+		hosts, err = s.Exec.load()
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("loaded %d hosts from %s", len(hosts), from)
+	return hosts, nil
+}
+
+// hostsFromValue returns the hosts in a decoded JSON or YAML value, which can be a list
+// of hosts, a mapping with a hosts key, a mapping of host names to hosts or a string
+// that contains a launchpad configuration.
+func hostsFromValue(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		hosts := make([]interface{}, 0, len(v))
+		for i, item := range v {
+			host, err := normalizeHost(item)
+			if err != nil {
+				return nil, fmt.Errorf("host %d: %w", i, err)
+			}
+			hosts = append(hosts, host)
+		}
+		return hosts, nil
+	case map[interface{}]interface{}:
+		if spec, ok := v["spec"].(map[interface{}]interface{}); ok {
+			return hostsFromValue(spec["hosts"])
+		}
+		if list, ok := v["hosts"]; ok {
+			return hostsFromValue(list)
+		}
+		hosts := make([]interface{}, 0, len(v))
+		for _, name := range sortedKeys(v) {
+			host, err := normalizeHost(v[name])
+			if err != nil {
+				return nil, fmt.Errorf("host %s: %w", name, err)
+			}
+			hosts = append(hosts, host)
+		}
+		return hosts, nil
+	case string:
+		var doc interface{}
+		if err := unmarshal([]byte(v), &doc); err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidHosts, err)
+		}
+		if _, ok := doc.(string); ok {
+			return nil, fmt.Errorf("%w: expected a list of hosts", errInvalidHosts)
+		}
+		return hostsFromValue(doc)
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: expected a list of hosts, got %T", errInvalidHosts, value)
+	}
+}
+
+// flatConnectionKeys are the keys of a flattened host, such as the ones in the
+// launchpad_hosts_ssh local of the Terraform examples, that go to the connection.
+var flatConnectionKeys = map[string]string{
+	"address":  "address",
+	"user":     "user",
+	"port":     "port",
+	"key_path": "keyPath",
+	"keyPath":  "keyPath",
+	"password": "password",
+	"useHTTPS": "useHTTPS",
+	"insecure": "insecure",
+}
+
+// normalizeHost converts a flattened host, one that has keys like ssh_address or
+// winrm_user instead of an ssh or winRM mapping, to the spec.hosts format. Hosts that
+// already are in that format are returned as is.
+func normalizeHost(item interface{}) (map[interface{}]interface{}, error) {
+	host, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a mapping, got %T", errInvalidHosts, item)
+	}
+	for _, protocol := range []string{"ssh", "winRM", "openSSH", "localhost"} {
+		if _, ok := host[protocol]; ok {
+			return host, nil
+		}
+	}
+
+	result := make(map[interface{}]interface{})
+	connections := map[string]map[interface{}]interface{}{}
+	plain := make(map[interface{}]interface{})
+	for k, v := range host {
+		key := fmt.Sprint(k)
+		if protocol, field, found := strings.Cut(key, "_"); found && (protocol == "ssh" || protocol == "winrm") {
+			name, ok := flatConnectionKeys[field]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown key %s", errInvalidHosts, key)
+			}
+			if protocol == "winrm" {
+				protocol = "winRM"
+			}
+			if connections[protocol] == nil {
+				connections[protocol] = make(map[interface{}]interface{})
+			}
+			connections[protocol][name] = v
+			continue
+		}
+		if name, ok := flatConnectionKeys[key]; ok {
+			plain[name] = v
+			continue
+		}
+		if key != "label" {
+			result[k] = v
+		}
+	}
+
+	switch {
+	case len(connections) > 0:
+		// the address next to ssh_address is the public name of the host in the
+		// Terraform examples
+		delete(plain, "address")
+		for k, v := range plain {
+			result[k] = v
+		}
+	case plain["address"] != nil:
+		connections["ssh"] = plain
+	default:
+		return nil, fmt.Errorf("%w: no address or connection", errInvalidHosts)
+	}
+	for protocol, conn := range connections {
+		result[protocol] = conn
+	}
+	return result, nil
+}
+
+// unmarshal decodes JSON or YAML to the types gopkg.in/yaml.v2 decodes to.
+func unmarshal(data []byte, out *interface{}) error {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return yaml.Unmarshal(data, out) //nolint:wrapcheck
+	}
+	// JSON can't be decoded with yaml.v2 as is, for example when it is indented with tabs
+	converted, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to convert JSON: %w", err)
+	}
+	return yaml.Unmarshal(converted, out) //nolint:wrapcheck
+}
+
+func sortedKeys(m map[interface{}]interface{}) []interface{} {
+	keys := make([]interface{}, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+	return keys
+}
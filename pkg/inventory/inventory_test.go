@@ -0,0 +1,107 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestTerraformState(t *testing.T) {
+	path := writeFile(t, "terraform.tfstate", `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "outputs": {
+    "hosts": {
+      "value": [
+        {"role": "manager", "ssh": {"address": "10.0.0.1", "user": "ubuntu", "port": 22}},
+        {"role": "worker", "ssh": {"address": "10.0.0.2", "user": "ubuntu"}}
+      ],
+      "type": ["tuple", []]
+    }
+  },
+  "resources": []
+}`)
+
+	hosts, err := Load([]Source{{Terraform: &Terraform{Path: path}}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[interface{}]interface{}{"role": "manager", "ssh": map[interface{}]interface{}{"address": "10.0.0.1", "user": "ubuntu", "port": 22}},
+		map[interface{}]interface{}{"role": "worker", "ssh": map[interface{}]interface{}{"address": "10.0.0.2", "user": "ubuntu"}},
+	}, hosts)
+}
+
+func TestTerraformOutputFlatHosts(t *testing.T) {
+	// the shape of the launchpad_hosts_ssh and launchpad_hosts_winrm locals of the examples
+	path := writeFile(t, "output.json", `{
+  "nodes": {
+    "sensitive": true,
+    "type": "object",
+    "value": {
+      "mgr-0": {"label": "mgr-0", "role": "manager", "address": "mgr-0.example.com", "ssh_address": "10.0.0.1", "ssh_user": "ubuntu", "ssh_port": 22, "ssh_key_path": "/keys/id_rsa"},
+      "win-0": {"label": "win-0", "role": "worker", "winrm_address": "10.0.0.9", "winrm_user": "Administrator", "winrm_password": "secret", "winrm_useHTTPS": true, "winrm_insecure": true}
+    }
+  }
+}`)
+
+	hosts, err := Load([]Source{{Terraform: &Terraform{Path: path, Output: "nodes"}}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[interface{}]interface{}{"role": "manager", "ssh": map[interface{}]interface{}{"address": "10.0.0.1", "user": "ubuntu", "port": 22, "keyPath": "/keys/id_rsa"}},
+		map[interface{}]interface{}{"role": "worker", "winRM": map[interface{}]interface{}{"address": "10.0.0.9", "user": "Administrator", "password": "secret", "useHTTPS": true, "insecure": true}},
+	}, hosts)
+}
+
+func TestTerraformOutputLaunchpadYAML(t *testing.T) {
+	path := writeFile(t, "output.json", `{
+  "launchpad_yaml": {
+    "sensitive": true,
+    "type": "string",
+    "value": "apiVersion: launchpad.mirantis.com/mke/v1.6\nkind: mke\nspec:\n  hosts:\n  # mgr-0 (ssh)\n  - role: manager\n    ssh:\n      address: 10.0.0.1\n"
+  }
+}`)
+
+	hosts, err := Load([]Source{{Terraform: &Terraform{Path: path, Output: "launchpad_yaml"}}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[interface{}]interface{}{"role": "manager", "ssh": map[interface{}]interface{}{"address": "10.0.0.1"}},
+	}, hosts)
+}
+
+func TestTerraformOutputNotFound(t *testing.T) {
+	path := writeFile(t, "output.json", `{"nodes": {"value": []}, "ingresses": {"value": {}}}`)
+
+	_, err := Load([]Source{{Terraform: &Terraform{Path: path}}})
+	require.ErrorIs(t, err, errOutputNotFound)
+	require.ErrorContains(t, err, "available: ingresses, nodes")
+}
+
+func TestExec(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no shell")
+	}
+	hosts, err := Load([]Source{{Exec: &Exec{Command: `printf '{"hosts": [{"role": "manager", "address": "10.0.0.1", "user": "admin"}]}'`}}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[interface{}]interface{}{"role": "manager", "ssh": map[interface{}]interface{}{"address": "10.0.0.1", "user": "admin"}},
+	}, hosts)
+
+	_, err = Load([]Source{{Exec: &Exec{Command: "exit 3"}}})
+	require.ErrorContains(t, err, "spec.inventory[0]: inventory command \"exit 3\" failed")
+}
+
+func TestSourceExactlyOne(t *testing.T) {
+	_, err := Load([]Source{{}})
+	require.ErrorIs(t, err, errInvalidSource)
+
+	_, err = Load([]Source{{Exec: &Exec{Command: "true"}, Ansible: &Ansible{Path: "hosts.ini"}}})
+	require.ErrorIs(t, err, errInvalidSource)
+}
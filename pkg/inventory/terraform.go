@@ -0,0 +1,60 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// defaultTerraformOutput is the output the hosts are read from when none is given.
+const defaultTerraformOutput = "hosts"
+
+var errOutputNotFound = errors.New("terraform output not found")
+
+// load reads the hosts from the output. The file can be a state file, which has the
+// outputs under the outputs key, or the output of terraform output -json, which has
+// them at the top level.
+func (t *Terraform) load() ([]interface{}, error) {
+	path, err := homedir.Expand(t.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path %s: %w", t.Path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terraform state: %w", err)
+	}
+
+	var doc interface{}
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform state %s: %w", t.Path, err)
+	}
+	outputs, ok := doc.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a terraform state or output file", errInvalidSource, t.Path)
+	}
+	if _, isState := outputs["terraform_version"].(string); isState {
+		outputs, _ = outputs["outputs"].(map[interface{}]interface{})
+	}
+
+	name := t.Output
+	if name == "" {
+		name = defaultTerraformOutput
+	}
+	output, ok := outputs[name].(map[interface{}]interface{})
+	if !ok {
+		names := make([]string, 0, len(outputs))
+		for _, k := range sortedKeys(outputs) {
+			names = append(names, fmt.Sprint(k))
+		}
+		return nil, fmt.Errorf("%w: %s in %s (available: %s)", errOutputNotFound, name, t.Path, strings.Join(names, ", "))
+	}
+
+	hosts, err := hostsFromValue(output["value"])
+	if err != nil {
+		return nil, fmt.Errorf("terraform output %s: %w", name, err)
+	}
+	return hosts, nil
+}
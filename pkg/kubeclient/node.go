@@ -0,0 +1,118 @@
+package kubeclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeChanges are the labels and taints to set on a node and the ones to remove from it.
+type NodeChanges struct {
+	Labels       map[string]string
+	RemoveLabels []string
+	Taints       []corev1.Taint
+	// RemoveTaints are matched by their key and effect.
+	RemoveTaints []corev1.Taint
+}
+
+// UpdateNode applies the changes to the named node. The labels and taints that are not
+// in the changes are left alone. A taint with the key and effect of one of the Taints
+// is replaced. The node is only updated when something changes.
+func (kc *KubeClient) UpdateNode(ctx context.Context, name string, changes NodeChanges) error {
+	node, err := kc.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node %q: %w", name, err)
+	}
+
+	labels := make(map[string]string, len(node.Labels)+len(changes.Labels))
+	for k, v := range node.Labels {
+		labels[k] = v
+	}
+	for _, k := range changes.RemoveLabels {
+		delete(labels, k)
+	}
+	for k, v := range changes.Labels {
+		labels[k] = v
+	}
+
+	taints := make([]corev1.Taint, 0, len(node.Spec.Taints)+len(changes.Taints))
+	for _, t := range node.Spec.Taints {
+		if containsTaint(changes.RemoveTaints, t) || containsTaint(changes.Taints, t) {
+			continue
+		}
+		taints = append(taints, t)
+	}
+	taints = append(taints, changes.Taints...)
+
+	if sameLabels(labels, node.Labels) && sameTaints(taints, node.Spec.Taints) {
+		return nil
+	}
+
+	node.Labels = labels
+	node.Spec.Taints = taints
+	if _, err := kc.client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update node %q: %w", name, err)
+	}
+	return nil
+}
+
+func sameLabels(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// containsTaint returns true when the list has a taint with the key and effect of t.
+func containsTaint(list []corev1.Taint, t corev1.Taint) bool {
+	for _, l := range list {
+		if l.Key == t.Key && l.Effect == t.Effect {
+			return true
+		}
+	}
+	return false
+}
+
+// sameTaints compares the taints without their order.
+func sameTaints(a, b []corev1.Taint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, t := range a {
+		found := false
+		for _, u := range b {
+			if t.Key == u.Key && t.Value == u.Value && t.Effect == u.Effect {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// IsTransient returns true for the errors of UpdateNode worth retrying: the node not
+// registered yet, an update conflict and the API server being busy or unavailable.
+func IsTransient(err error) bool {
+	var netErr net.Error
+	return apierrors.IsNotFound(err) ||
+		apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) ||
+		errors.As(err, &netErr)
+}
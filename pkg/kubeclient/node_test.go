@@ -0,0 +1,62 @@
+package kubeclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestUpdateNode(t *testing.T) {
+	kc := NewTestClient(t)
+	ctx := context.Background()
+
+	err := kc.UpdateNode(ctx, "node-1", NodeChanges{Labels: map[string]string{"zone": "eu-1"}})
+	require.Error(t, err)
+
+	_, err = kc.client.CoreV1().Nodes().Create(ctx, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"kubernetes.io/hostname": "node-1", "zone": "eu-2", "old": "true"},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{
+				{Key: "other", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "dedicated", Value: "web", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "gone", Effect: corev1.TaintEffectNoExecute},
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	err = kc.UpdateNode(ctx, "node-1", NodeChanges{
+		Labels:       map[string]string{"zone": "eu-1", "gpu": "true"},
+		RemoveLabels: []string{"old"},
+		Taints:       []corev1.Taint{{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule}},
+		RemoveTaints: []corev1.Taint{{Key: "gone", Effect: corev1.TaintEffectNoExecute}},
+	})
+	require.NoError(t, err)
+
+	node, err := kc.client.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"kubernetes.io/hostname": "node-1", "zone": "eu-1", "gpu": "true"}, node.Labels)
+	require.Equal(t, []corev1.Taint{
+		{Key: "other", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule},
+	}, node.Spec.Taints)
+}
+
+func TestIsTransient(t *testing.T) {
+	nodes := schema.GroupResource{Resource: "nodes"}
+	require.True(t, IsTransient(fmt.Errorf("failed to get node: %w", apierrors.NewNotFound(nodes, "node-1"))))
+	require.True(t, IsTransient(apierrors.NewConflict(nodes, "node-1", errors.New("modified"))))
+	require.True(t, IsTransient(apierrors.NewServiceUnavailable("restarting")))
+	require.False(t, IsTransient(apierrors.NewForbidden(nodes, "node-1", errors.New("denied"))))
+	require.False(t, IsTransient(errors.New("invalid taint")))
+}
@@ -25,14 +25,14 @@ type FormatterWriterHook struct {
 }
 
 // Fire will be called when some logging function is called with current hook
-// It will format log entry to string and write it to appropriate writer.
+// It will format log entry to string, redact the registered secrets and write it to appropriate writer.
 func (hook *FormatterWriterHook) Fire(entry *log.Entry) error {
 	line, err := hook.Formatter.Format(entry)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Unable to format log entry: %v", err)
 		return fmt.Errorf("unable to format log entry: %w", err)
 	}
-	if _, err = hook.Writer.Write(line); err != nil {
+	if _, err = hook.Writer.Write([]byte(Redact(string(line)))); err != nil {
 		return fmt.Errorf("unable to write log entry to writer: %w", err)
 	}
 	return nil
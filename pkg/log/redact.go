@@ -0,0 +1,82 @@
+package log
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/k0sproject/rig/exec"
+	log "github.com/sirupsen/logrus"
+)
+
+// minSecretLength is the length under which a warning is given when a secret is
+// registered, replacing very short strings also redacts unrelated parts of the output.
+const minSecretLength = 4
+
+var (
+	secretsMu sync.RWMutex
+	secrets   = make(map[string]struct{})
+	replacer  *strings.Replacer
+)
+
+// RegisterSecret adds values that are replaced with [REDACTED] in all the output, the
+// log, the event stream and the traces, for example passwords resolved from secret
+// references in the configuration.
+func RegisterSecret(values ...string) {
+	short := registerSecrets(values)
+	// logged after the lock is released, the log output is redacted too
+	for i := 0; i < short; i++ {
+		log.Warnf("a secret shorter than %d characters was registered for redaction, unrelated output containing it is redacted too", minSecretLength)
+	}
+}
+
+// registerSecrets adds the values and rebuilds the replacer, it returns the number of
+// short values.
+func registerSecrets(values []string) int {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	short := 0
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if _, ok := secrets[v]; ok {
+			continue
+		}
+		if len(v) < minSecretLength {
+			short++
+		}
+		secrets[v] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(secrets))
+	for v := range secrets {
+		sorted = append(sorted, v)
+	}
+	// replace the longest secrets first in case one contains another
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	pairs := make([]string, 0, len(sorted)*2)
+	for _, v := range sorted {
+		pairs = append(pairs, v, "[REDACTED]")
+	}
+	replacer = strings.NewReplacer(pairs...)
+	return short
+}
+
+// Redact replaces the registered secrets in s, unless redaction has been disabled with
+// --disable-redact.
+func Redact(s string) string {
+	if exec.DisableRedact {
+		return s
+	}
+
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	if replacer == nil {
+		return s
+	}
+	return replacer.Replace(s)
+}
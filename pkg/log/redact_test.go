@@ -0,0 +1,29 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/k0sproject/rig/exec"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	RegisterSecret("hunter2hunter2", "xyz", "hunter2", " ")
+	require.Equal(t, "password is [REDACTED], [REDACTED] and [REDACTED]", Redact("password is hunter2hunter2, hunter2 and xyz"))
+
+	exec.DisableRedact = true
+	t.Cleanup(func() { exec.DisableRedact = false })
+	require.Equal(t, "password is hunter2", Redact("password is hunter2"))
+}
+
+func TestFormatterWriterHookRedacts(t *testing.T) {
+	RegisterSecret("s3cr3t-value")
+
+	var buf bytes.Buffer
+	hook := &FormatterWriterHook{Writer: &buf, Formatter: &log.TextFormatter{DisableTimestamp: true, DisableColors: true}, LogLevels: log.AllLevels}
+	require.NoError(t, hook.Fire(&log.Entry{Logger: log.StandardLogger(), Level: log.InfoLevel, Message: "running login -p s3cr3t-value"}))
+	require.NotContains(t, buf.String(), "s3cr3t-value")
+	require.Contains(t, buf.String(), "[REDACTED]")
+}
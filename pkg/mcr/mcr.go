@@ -38,6 +38,28 @@ func DrainNode(lead *mkeconfig.Host, h *mkeconfig.Host) error {
 	return nil
 }
 
+// ActivateNode sets the availability of the swarm node of the host back to active after
+// a drain. If the node is not part of a swarm the call is a no-op.
+func ActivateNode(lead *mkeconfig.Host, h *mkeconfig.Host) error {
+	nodeID, err := swarm.NodeID(h)
+	if err != nil {
+		return fmt.Errorf("failed to get node ID for %s: %w", h, err)
+	}
+
+	if nodeID == "" {
+		log.Debugf("%s: not part of a swarm, skipping activation", h)
+		return nil
+	}
+
+	activateCmd := lead.Configurer.DockerCommandf("node update --availability active %s", nodeID)
+	if err := lead.Exec(activateCmd); err != nil {
+		return fmt.Errorf("%s: failed to activate node %s: %w", lead, nodeID, err)
+	}
+
+	log.Infof("%s: node %s activated", lead, nodeID)
+	return nil
+}
+
 // EnsureMCRRunning ensure that MCR is running.
 func EnsureMCRRunning(h *mkeconfig.Host, _ commonconfig.MCRConfig) error {
 	if _, err := h.MCRVersion(); err != nil {
@@ -243,7 +243,7 @@ func DownloadBundle(config *mkeconfig.ClusterConfig) error {
 		return fmt.Errorf("failed to download admin bundle: %w", err)
 	}
 
-	bundleDir, err := getBundleDir(config)
+	bundleDir, err := BundleDir(config)
 	if err != nil {
 		return err
 	}
@@ -255,7 +255,8 @@ func DownloadBundle(config *mkeconfig.ClusterConfig) error {
 	return nil
 }
 
-func getBundleDir(config *mkeconfig.ClusterConfig) (string, error) {
+// BundleDir returns the directory the client bundle of the admin user is downloaded to.
+func BundleDir(config *mkeconfig.ClusterConfig) (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -0,0 +1,79 @@
+package phase
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+)
+
+// Drift describes a difference between the configuration and the cluster.
+type Drift struct {
+	Host    string `json:"host,omitempty"`
+	Role    string `json:"role,omitempty"`
+	Item    string `json:"item"`
+	Config  string `json:"config"`
+	Cluster string `json:"cluster"`
+	// NotApplied is the reason apply leaves the difference as it is, empty when apply
+	// changes the cluster to match the configuration.
+	NotApplied string `json:"notApplied,omitempty"`
+}
+
+// DriftReport is the result of a diff. It lists the differences between the
+// configuration and the cluster.
+type DriftReport struct {
+	Drift []Drift `json:"drift"`
+}
+
+// Add adds a difference to the report.
+func (r *DriftReport) Add(d Drift) {
+	r.Drift = append(r.Drift, d)
+}
+
+// Empty returns true when the cluster matches the configuration.
+func (r *DriftReport) Empty() bool {
+	return len(r.Drift) == 0
+}
+
+// Applicable returns the report of the differences that apply changes.
+func (r *DriftReport) Applicable() *DriftReport {
+	applicable := &DriftReport{}
+	for _, d := range r.Drift {
+		if d.NotApplied == "" {
+			applicable.Add(d)
+		}
+	}
+	return applicable
+}
+
+// NotAppliedReasons returns the reasons apply leaves differences as they are, each
+// reason once.
+func (r *DriftReport) NotAppliedReasons() []string {
+	var reasons []string
+	for _, d := range r.Drift {
+		if d.NotApplied != "" && !slices.Contains(reasons, d.NotApplied) {
+			reasons = append(reasons, d.NotApplied)
+		}
+	}
+	return reasons
+}
+
+// WriteTable writes the report as a human-readable table.
+func (r *DriftReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tITEM\tCONFIG\tCLUSTER")
+	for _, d := range r.Drift {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", orDash(d.Host), d.Item, orDash(d.Config), orDash(d.Cluster))
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write drift report: %w", err)
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,166 @@
+package phase
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// JournalStatusCompleted is the status of a phase that has finished successfully.
+	JournalStatusCompleted = "completed"
+	// JournalStatusFailed is the status of a phase that has returned an error.
+	JournalStatusFailed = "failed"
+
+	// HostResultOK is the per-host result recorded for hosts that did not return an error.
+	HostResultOK = "ok"
+)
+
+// JournalEntry is the recorded outcome of a single phase.
+type JournalEntry struct {
+	Title    string            `json:"title"`
+	Status   string            `json:"status"`
+	Duration float64           `json:"duration"`
+	Hosts    map[string]string `json:"hosts,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// Journal is an on-disk record of the progress of a run. It is used to resume a run that
+// failed halfway through without redoing the phases that have already completed.
+type Journal struct {
+	ConfigHash string         `json:"configHash"`
+	StartedAt  time.Time      `json:"startedAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	Phases     []JournalEntry `json:"phases"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// DefaultJournalPath returns the journal file location for the named cluster and action
+// under the local launchpad state directory.
+func DefaultJournalPath(clusterName, action string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, constant.StateBaseDir, "cluster", clusterName, action+".journal.json"), nil
+}
+
+// ConfigHash returns a checksum of the given configuration. It is used to detect
+// configuration changes between a failed run and an attempt to resume it.
+func ConfigHash(config interface{}) (string, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// NewJournal returns a new empty journal which will be stored to path.
+func NewJournal(path, configHash string) *Journal {
+	return &Journal{
+		ConfigHash: configHash,
+		StartedAt:  time.Now(),
+		path:       path,
+	}
+}
+
+var errJournalConfigChanged = errors.New("configuration has changed since the journal was written")
+
+// LoadJournal reads a journal from path. An error is returned if the file can't be read
+// or if it was written for a configuration with a different hash.
+func LoadJournal(path, configHash string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	j := &Journal{path: path}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", path, err)
+	}
+
+	if j.ConfigHash != configHash {
+		return nil, errJournalConfigChanged
+	}
+
+	return j, nil
+}
+
+// Path returns the file location of the journal.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Completed returns true if a phase with the given title has been recorded as completed.
+func (j *Journal) Completed(title string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, e := range j.Phases {
+		if e.Title == title {
+			return e.Status == JournalStatusCompleted
+		}
+	}
+	return false
+}
+
+// Record stores the outcome of a phase and writes the journal to disk.
+func (j *Journal) Record(entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	replaced := false
+	for i, e := range j.Phases {
+		if e.Title == entry.Title {
+			j.Phases[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		j.Phases = append(j.Phases, entry)
+	}
+	j.UpdatedAt = time.Now()
+
+	return j.write()
+}
+
+func (j *Journal) write() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %w", err)
+	}
+
+	// write to a temporary file first so that a crash never leaves a truncated journal behind
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the journal file.
+func (j *Journal) Remove() error {
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package phase
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Kind       string
+	APIVersion string
+}
+
+type testPhase struct {
+	title      string
+	err        error
+	runs       int
+	repeatable bool
+}
+
+func (p *testPhase) Title() string {
+	return p.title
+}
+
+func (p *testPhase) Run(_ context.Context) error {
+	p.runs++
+	return p.err
+}
+
+func (p *testPhase) RepeatOnResume() bool {
+	return p.repeatable
+}
+
+func TestJournalLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apply.journal.json")
+
+	j := NewJournal(path, "abc")
+	require.NoError(t, j.Record(JournalEntry{Title: "one", Status: JournalStatusCompleted, Hosts: map[string]string{"h1": HostResultOK}}))
+	require.NoError(t, j.Record(JournalEntry{Title: "two", Status: JournalStatusFailed, Error: "boom"}))
+
+	loaded, err := LoadJournal(path, "abc")
+	require.NoError(t, err)
+	require.True(t, loaded.Completed("one"))
+	require.False(t, loaded.Completed("two"))
+	require.False(t, loaded.Completed("three"))
+	require.Equal(t, HostResultOK, loaded.Phases[0].Hosts["h1"])
+
+	_, err = LoadJournal(path, "def")
+	require.ErrorIs(t, err, errJournalConfigChanged)
+
+	require.NoError(t, loaded.Remove())
+	_, err = LoadJournal(path, "abc")
+	require.Error(t, err)
+}
+
+func TestConfigHash(t *testing.T) {
+	a, err := ConfigHash(&testConfig{Kind: "mke"})
+	require.NoError(t, err)
+	b, err := ConfigHash(&testConfig{Kind: "mke"})
+	require.NoError(t, err)
+	c, err := ConfigHash(&testConfig{Kind: "mke+msr"})
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+	require.NotEqual(t, a, c)
+}
+
+func TestManagerResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apply.journal.json")
+	errFailed := errors.New("failed")
+
+	facts := &testPhase{title: "facts", repeatable: true}
+	install := &testPhase{title: "install"}
+	join := &testPhase{title: "join", err: errFailed}
+
+	m := NewManager(&testConfig{})
+	m.Journal = NewJournal(path, "abc")
+	m.AddPhases(facts, install, join)
+	require.ErrorIs(t, m.Run(context.Background()), errFailed)
+
+	journal, err := LoadJournal(path, "abc")
+	require.NoError(t, err)
+
+	join.err = nil
+	m = NewManager(&testConfig{})
+	m.Journal = journal
+	m.AddPhases(facts, install, join)
+	require.NoError(t, m.Run(context.Background()))
+
+	require.Equal(t, 2, facts.runs, "repeatable phase should run again")
+	require.Equal(t, 1, install.runs, "completed phase should be skipped")
+	require.Equal(t, 2, join.runs, "failed phase should be retried")
+}
@@ -1,21 +1,33 @@
 package phase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/Mirantis/launchpad/pkg/analytics"
+	"github.com/Mirantis/launchpad/pkg/eventstream"
+	"github.com/Mirantis/launchpad/pkg/tracing"
 	"github.com/logrusorgru/aurora/v4"
 	event "github.com/segmentio/analytics-go/v3"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type phase interface {
-	Run() error
+	Run(ctx context.Context) error
 	Title() string
 }
 
+// contextual is implemented by configurations that bind a context to the remote
+// connections, so that commands started on the hosts are cancelled with it.
+type contextual interface {
+	SetContext(ctx context.Context)
+}
+
 type withconfig interface {
 	Prepare(interface{}) error
 }
@@ -32,14 +44,61 @@ type cleanupdisabling interface {
 	DisableCleanup()
 }
 
+type repeatable interface {
+	RepeatOnResume() bool
+}
+
+type readonly interface {
+	IsReadOnly() bool
+}
+
+type planner interface {
+	PlannedActions() []PlannedAction
+}
+
 // Manager executes phases to construct the cluster.
 type Manager struct {
-	phases       []phase
-	config       interface{}
+	phases []phase
+	config interface{}
+	// IgnoreErrors logs the phase failures and carries on with the next phases.
 	IgnoreErrors bool
 	SkipCleanup  bool
+
+	// Journal, when set, is used to record the outcome of each phase. Phases that
+	// the journal already lists as completed are skipped unless they are repeatable.
+	Journal *Journal
+
+	// DryRun only runs the read-only phases. The other phases are asked to describe
+	// their intended actions, which are collected into the Plan.
+	DryRun bool
+
+	// Only, when set, limits the run to the phases with the listed identifiers.
+	Only []string
+	// Skip lists the identifiers of phases that are not run.
+	Skip []string
+	// AllowUnsafeSelection allows selecting phases without the phases they require.
+	AllowUnsafeSelection bool
+
+	// KeepGoing carries on when a phase fails only on some hosts. The failed hosts are
+	// dropped from the rest of the run and the run fails with a summary of the host
+	// failures at the end.
+	KeepGoing bool
+
+	plan *Plan
+
+	mu        sync.Mutex
+	durations []PhaseDuration
 }
 
+// cleanupTimeout bounds the cleanup of a phase that was interrupted.
+const cleanupTimeout = 2 * time.Minute
+
+var (
+	errInterrupted = errors.New("interrupted")
+	errHostsFailed = errors.New("the run failed on some of the hosts")
+	errNotRun      = errors.New("not run")
+)
+
 // NewManager constructs new phase manager.
 func NewManager(config interface{}) *Manager {
 	phaseMgr := &Manager{
@@ -59,70 +118,382 @@ func (m *Manager) AddPhase(p phase) {
 	m.phases = append(m.phases, p)
 }
 
-// Run executes all the added Phases in order.
-func (m *Manager) Run() error {
-	for _, phase := range m.phases {
-		title := phase.Title()
+// Plan returns the plan collected during a dry run.
+func (m *Manager) Plan() *Plan {
+	return m.plan
+}
 
-		if p, ok := phase.(withconfig); ok {
-			log.Debugf("preparing phase '%s'", title)
-			if err := p.Prepare(m.config); err != nil {
-				return fmt.Errorf("phase '%s' failed to prepare: %w", title, err)
-			}
+// Run executes all the added Phases in order. Consecutive phases that declare resource
+// scopes are run concurrently unless they depend on each other, see ResourceScopes.
+// When the context is cancelled, the phases in progress are interrupted, their cleanup
+// is run and no further phases are started.
+func (m *Manager) Run(ctx context.Context) (err error) {
+	if err := m.validateSelection(); err != nil {
+		return err
+	}
+
+	hostFailures := &Error{}
+	defer func() {
+		if hostFailures.Count() > 0 {
+			err = errors.Join(err, m.hostFailureSummary(hostFailures))
 		}
+	}()
 
-		if m.SkipCleanup {
-			if p, ok := phase.(cleanupdisabling); ok {
-				log.Debugf("disabling in-phase cleanup for '%s'", title)
-				p.DisableCleanup()
-			}
+	m.setContext(ctx)
+
+	if m.DryRun {
+		m.plan = &Plan{}
+	}
+
+	m.durations = nil
+	defer m.logDurations()
+
+	// analytics events are sent once all the phases have finished
+	var tracked []func()
+	defer func() {
+		for i := len(tracked) - 1; i >= 0; i-- {
+			tracked[i]()
 		}
+	}()
 
-		if p, ok := phase.(conditional); ok {
-			if !p.ShouldRun() {
-				log.Debugf("skipping phase '%s'", title)
-				continue
+	for i := 0; i < len(m.phases); {
+		group := m.group(i)
+		i += len(group)
+
+		runnable := make([]phase, 0, len(group))
+		for _, phase := range group {
+			run, err := m.prepare(ctx, phase)
+			if err != nil {
+				return err
+			}
+			if run {
+				runnable = append(runnable, phase)
 			}
 		}
+		if len(runnable) == 0 {
+			continue
+		}
 
-		text := aurora.Green("==> Running phase: %s").String()
-		log.Infof(text, title)
-		start := time.Now()
-
-		result := phase.Run()
+		results := m.runGroup(ctx, runnable)
 
-		duration := time.Since(start)
-		log.Debugf("phase '%s' took %s", title, duration.Truncate(time.Minute))
+		var errs []error
+		for idx, phase := range runnable {
+			res := results[idx]
+			if res.track != nil {
+				tracked = append(tracked, res.track)
+			}
+			if res.err == nil {
+				continue
+			}
 
-		if e, ok := phase.(Eventable); ok {
-			r := reflect.ValueOf(m.config).Elem()
-			props := event.Properties{
-				"kind":        r.FieldByName("Kind").String(),
-				"api_version": r.FieldByName("APIVersion").String(),
-				"duration":    duration.Seconds(),
+			title := phase.Title()
+			if errors.Is(res.err, errNotRun) {
+				// the phase didn't start, there is nothing to drop or clean up
+				errs = append(errs, fmt.Errorf("phase failure: %s => %w", title, res.err))
+				continue
 			}
-			for k, v := range e.GetEventProperties() {
-				props[k] = v
+			if m.KeepGoing && m.dropHosts(title, res.err, hostFailures) {
+				continue
 			}
-			props["success"] = result == nil
-			defer func() { analytics.TrackEvent(title, props) }()
-		}
 
-		if result != nil {
 			if p, ok := phase.(withcleanup); ok {
 				if !m.SkipCleanup {
-					defer p.CleanUp()
+					m.cleanUp(ctx, title, p)
 				}
 			}
 
 			if m.IgnoreErrors {
-				log.Debugf("ignoring phase '%s' error: %s", title, result.Error())
-				return nil
+				log.Debugf("ignoring phase '%s' error: %s", title, res.err.Error())
+				continue
 			}
-			return fmt.Errorf("phase failure: %s => %w", title, result)
+			errs = append(errs, fmt.Errorf("phase failure: %s => %w", title, res.err))
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...)
 		}
-		log.Debugf("phase '%s' completed successfully", title)
 	}
 
 	return nil
 }
+
+// prepare runs the steps that decide whether a phase is to be run: the phase selection,
+// planning during a dry run, Prepare, ShouldRun and the journal of a previous run.
+func (m *Manager) prepare(ctx context.Context, phase phase) (bool, error) {
+	title := phase.Title()
+
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("%w before phase '%s': %w", errInterrupted, title, context.Cause(ctx))
+	}
+
+	if !m.selected(phaseID(phase)) {
+		log.Infof(aurora.Green("==> Skipping phase: %s (not selected)").String(), title)
+		eventstream.Emit(eventstream.Event{Type: eventstream.TypePhaseSkip, Phase: title, Reason: "not selected"})
+		return false, nil
+	}
+
+	if m.DryRun {
+		if p, ok := phase.(readonly); !ok || !p.IsReadOnly() {
+			m.planPhase(phase)
+			return false, nil
+		}
+	}
+
+	if p, ok := phase.(withconfig); ok {
+		log.Debugf("preparing phase '%s'", title)
+		if err := p.Prepare(m.config); err != nil {
+			eventstream.Emit(eventstream.Result(eventstream.Event{Type: eventstream.TypePhaseFinish, Phase: title}, err))
+			return false, fmt.Errorf("phase '%s' failed to prepare: %w", title, err)
+		}
+	}
+
+	if m.SkipCleanup {
+		if p, ok := phase.(cleanupdisabling); ok {
+			log.Debugf("disabling in-phase cleanup for '%s'", title)
+			p.DisableCleanup()
+		}
+	}
+
+	if p, ok := phase.(conditional); ok {
+		if !p.ShouldRun() {
+			log.Debugf("skipping phase '%s'", title)
+			eventstream.Emit(eventstream.Event{Type: eventstream.TypePhaseSkip, Phase: title, Reason: "not required"})
+			return false, nil
+		}
+	}
+
+	if m.Journal != nil && m.Journal.Completed(title) {
+		if p, ok := phase.(repeatable); !ok || !p.RepeatOnResume() {
+			log.Infof(aurora.Green("==> Skipping phase: %s (completed in a previous run)").String(), title)
+			eventstream.Emit(eventstream.Event{Type: eventstream.TypePhaseSkip, Phase: title, Reason: "completed in a previous run"})
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// phaseResult is the outcome of running a single phase.
+type phaseResult struct {
+	err   error
+	track func()
+}
+
+// execute runs a prepared phase and records its outcome. When bind is set, the phase runs
+// alone and the host connections are bound to the context of its span while it runs.
+func (m *Manager) execute(ctx context.Context, phase phase, bind bool) phaseResult {
+	title := phase.Title()
+
+	log.Infof(aurora.Green("==> Running phase: %s").String(), title)
+	start := time.Now()
+	eventstream.Emit(eventstream.Event{Type: eventstream.TypePhaseStart, Phase: title})
+
+	spanCtx, span := tracing.Start(ctx, "phase", attribute.String("phase", phaseID(phase)), attribute.String("title", title))
+	if bind {
+		// commands run on the hosts by this phase are recorded as children of its span
+		m.setContext(spanCtx)
+		defer m.setContext(ctx)
+	}
+
+	running := &runningPhase{title: title}
+	result := phase.Run(withRunningPhase(spanCtx, running))
+	if result != nil && ctx.Err() != nil {
+		result = fmt.Errorf("%w: %w", context.Cause(ctx), result)
+	}
+
+	duration := time.Since(start)
+	if e, ok := phase.(Eventable); ok {
+		span.SetAttributes(tracing.Attributes(e.GetEventProperties())...)
+	}
+	span.SetAttributes(attribute.Float64("duration", duration.Seconds()), attribute.Bool("success", result == nil))
+	tracing.End(span, result)
+	log.Debugf("phase '%s' took %s", title, duration.Truncate(time.Second))
+	eventstream.Emit(eventstream.Result(eventstream.Event{Type: eventstream.TypePhaseFinish, Phase: title, Duration: duration.Seconds()}, result))
+	m.recordDuration(title, duration)
+
+	if m.Journal != nil {
+		m.recordJournal(title, duration, running.take(), result)
+	}
+
+	res := phaseResult{err: result}
+
+	if e, ok := phase.(Eventable); ok {
+		r := reflect.ValueOf(m.config).Elem()
+		props := event.Properties{
+			"kind":        r.FieldByName("Kind").String(),
+			"api_version": r.FieldByName("APIVersion").String(),
+			"duration":    duration.Seconds(),
+		}
+		for k, v := range e.GetEventProperties() {
+			props[k] = v
+		}
+		props["success"] = result == nil
+		res.track = func() { analytics.TrackEvent(title, props) }
+	}
+
+	if result == nil {
+		log.Debugf("phase '%s' completed successfully", title)
+	}
+
+	return res
+}
+
+// dropHosts marks the hosts a phase failed on as dropped and adds them to the host
+// failures. It returns false if the error is not limited to hosts that can be dropped.
+// Manager hosts can't be dropped as the swarm and MKE rely on them.
+func (m *Manager) dropHosts(title string, err error, hostFailures *Error) bool {
+	var perr *Error
+	if !errors.As(err, &perr) {
+		return false
+	}
+	hostErrors, ok := perr.HostErrors()
+	if !ok {
+		return false
+	}
+	for _, he := range hostErrors {
+		if he.Host.Role == "manager" {
+			return false
+		}
+	}
+
+	for _, he := range hostErrors {
+		log.Warnf("%s: phase '%s' failed, dropping the host from the rest of the run: %s", he.Host, title, he.Err)
+		he.Host.Drop()
+		hostFailures.AddError(&HostError{Host: he.Host, Phase: title, Err: he.Err})
+	}
+	return true
+}
+
+// hostFailureSummary logs the hosts that were dropped during the run and returns an
+// error listing them.
+func (m *Manager) hostFailureSummary(hostFailures *Error) error {
+	log.Errorf("the run failed on %d host(s):", hostFailures.Count())
+	for _, err := range hostFailures.Errors {
+		log.Errorf("  %s", err)
+	}
+	return fmt.Errorf("%w:\n%w", errHostsFailed, hostFailures)
+}
+
+// cleanUp runs the cleanup of a failed phase. If the phase failed because the run was
+// interrupted, the remote connections are given a fresh context for the cleanup.
+func (m *Manager) cleanUp(ctx context.Context, title string, p withcleanup) {
+	if ctx.Err() != nil {
+		log.Warnf("running cleanup for interrupted phase '%s'", title)
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		m.setContext(cleanupCtx)
+		defer m.setContext(ctx)
+	}
+	p.CleanUp()
+}
+
+func (m *Manager) setContext(ctx context.Context) {
+	if c, ok := m.config.(contextual); ok {
+		c.SetContext(ctx)
+	}
+}
+
+func (m *Manager) recordJournal(title string, duration time.Duration, hosts map[string]string, result error) {
+	entry := JournalEntry{
+		Title:    title,
+		Status:   JournalStatusCompleted,
+		Duration: duration.Seconds(),
+		Hosts:    hosts,
+	}
+	if result != nil {
+		entry.Status = JournalStatusFailed
+		entry.Error = result.Error()
+	}
+	if err := m.Journal.Record(entry); err != nil {
+		log.Warnf("failed to update run journal: %s", err)
+	}
+}
+
+// planPhase adds the intended actions of a phase to the plan without running it.
+func (m *Manager) planPhase(phase phase) {
+	title := phase.Title()
+	planned := PlannedPhase{Title: title}
+
+	if p, ok := phase.(withconfig); ok {
+		log.Debugf("preparing phase '%s' for planning", title)
+		if err := p.Prepare(m.config); err != nil {
+			log.Debugf("phase '%s' failed to prepare: %s", title, err)
+			planned.Note = fmt.Sprintf("can't be determined before earlier phases have run: %s", err)
+			m.plan.Phases = append(m.plan.Phases, planned)
+			return
+		}
+	}
+
+	if p, ok := phase.(conditional); ok {
+		if !p.ShouldRun() {
+			log.Debugf("phase '%s' would be skipped", title)
+			return
+		}
+	}
+
+	if p, ok := phase.(planner); ok {
+		planned.Actions = p.PlannedActions()
+		if len(planned.Actions) == 0 {
+			log.Debugf("phase '%s' has nothing to do", title)
+			return
+		}
+	} else {
+		planned.Actions = []PlannedAction{{Action: "run"}}
+	}
+
+	m.plan.Phases = append(m.plan.Phases, planned)
+}
+
+// runningPhase collects the per-host outcomes reported by RunParallelOnHosts while a
+// phase runs. It is passed to the phase in its context.
+type runningPhase struct {
+	mu      sync.Mutex
+	title   string
+	results map[string]string
+}
+
+type runningPhaseKey struct{}
+
+func withRunningPhase(ctx context.Context, r *runningPhase) context.Context {
+	return context.WithValue(ctx, runningPhaseKey{}, r)
+}
+
+// runningPhaseFrom returns the running phase of the context or nil when the context
+// does not come from the phase manager.
+func runningPhaseFrom(ctx context.Context) *runningPhase {
+	r, _ := ctx.Value(runningPhaseKey{}).(*runningPhase)
+	return r
+}
+
+func (r *runningPhase) get() string {
+	if r == nil {
+		return ""
+	}
+	return r.title
+}
+
+func (r *runningPhase) add(host string, err error) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.results == nil {
+		r.results = make(map[string]string)
+	}
+	if err != nil {
+		r.results[host] = err.Error()
+	} else if _, ok := r.results[host]; !ok {
+		r.results[host] = HostResultOK
+	}
+}
+
+func (r *runningPhase) take() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := r.results
+	r.results = nil
+	return res
+}
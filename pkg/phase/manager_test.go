@@ -0,0 +1,133 @@
+package phase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/k0sproject/rig"
+	"github.com/stretchr/testify/require"
+)
+
+type contextTestConfig struct {
+	testConfig
+	contexts []context.Context
+}
+
+func (c *contextTestConfig) SetContext(ctx context.Context) {
+	c.contexts = append(c.contexts, ctx)
+}
+
+type interruptedTestPhase struct {
+	cancel     context.CancelFunc
+	cleaned    bool
+	cleanupErr error
+	config     *contextTestConfig
+}
+
+func (p *interruptedTestPhase) Title() string {
+	return "interrupted"
+}
+
+func (p *interruptedTestPhase) Run(ctx context.Context) error {
+	p.cancel()
+	<-ctx.Done()
+	return errors.New("command interrupted")
+}
+
+func (p *interruptedTestPhase) CleanUp() {
+	p.cleaned = true
+	p.cleanupErr = p.config.contexts[len(p.config.contexts)-1].Err()
+}
+
+func TestManagerInterrupt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := &contextTestConfig{}
+	interrupted := &interruptedTestPhase{cancel: cancel, config: config}
+	next := &testPhase{title: "next"}
+
+	m := NewManager(config)
+	m.AddPhases(interrupted, next)
+	err := m.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.Equal(t, 0, next.runs, "no phases should be started after an interrupt")
+	require.True(t, interrupted.cleaned, "cleanup should be run for the interrupted phase")
+	require.NoError(t, interrupted.cleanupErr, "cleanup should get a live context")
+	require.Equal(t, ctx, config.contexts[0])
+}
+
+type hostFailingTestPhase struct {
+	hosts mkeconfig.Hosts
+	runs  int
+}
+
+func (p *hostFailingTestPhase) Title() string {
+	return "install"
+}
+
+func (p *hostFailingTestPhase) Run(_ context.Context) error {
+	p.runs++
+	perr := &Error{}
+	for _, h := range p.hosts {
+		perr.AddHostError(h, errors.New("install failed"))
+	}
+	return perr
+}
+
+func TestManagerKeepGoing(t *testing.T) {
+	worker := &mkeconfig.Host{Role: "worker", Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.2"}}}
+	manager := &mkeconfig.Host{Role: "manager", Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.1"}}}
+
+	t.Run("worker failure", func(t *testing.T) {
+		failing := &hostFailingTestPhase{hosts: mkeconfig.Hosts{worker}}
+		next := &testPhase{title: "next"}
+
+		m := NewManager(&testConfig{})
+		m.KeepGoing = true
+		m.AddPhases(failing, next)
+		err := m.Run(context.Background())
+		require.ErrorIs(t, err, errHostsFailed)
+		require.ErrorContains(t, err, "[SSH] 10.0.0.2: install: install failed")
+		require.Equal(t, 1, next.runs)
+		require.True(t, worker.Dropped())
+	})
+
+	t.Run("manager failure", func(t *testing.T) {
+		failing := &hostFailingTestPhase{hosts: mkeconfig.Hosts{manager}}
+		next := &testPhase{title: "next"}
+
+		m := NewManager(&testConfig{})
+		m.KeepGoing = true
+		m.AddPhases(failing, next)
+		err := m.Run(context.Background())
+		require.Error(t, err)
+		require.NotErrorIs(t, err, errHostsFailed)
+		require.Equal(t, 0, next.runs)
+		require.False(t, manager.Dropped())
+	})
+
+	t.Run("without keep going", func(t *testing.T) {
+		failing := &hostFailingTestPhase{hosts: mkeconfig.Hosts{worker}}
+		next := &testPhase{title: "next"}
+
+		m := NewManager(&testConfig{})
+		m.AddPhases(failing, next)
+		require.Error(t, m.Run(context.Background()))
+		require.Equal(t, 0, next.runs)
+	})
+}
+
+func TestManagerIgnoreErrors(t *testing.T) {
+	failing := &testPhase{title: "failing", err: errors.New("failed")}
+	next := &testPhase{title: "next"}
+
+	m := NewManager(&testConfig{})
+	m.IgnoreErrors = true
+	m.AddPhases(failing, next)
+	require.NoError(t, m.Run(context.Background()))
+	require.Equal(t, 1, next.runs, "the phases after a failed phase should be run")
+}
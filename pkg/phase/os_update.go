@@ -0,0 +1,69 @@
+package phase
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+)
+
+// HostUpdate is the outcome of the OS update of a host.
+type HostUpdate struct {
+	Host string `json:"host"`
+	Role string `json:"role"`
+	// Packages lists the upgraded, installed and removed packages, or the installed
+	// updates on Windows.
+	Packages []string `json:"packages"`
+	Rebooted bool     `json:"rebooted"`
+	// Error is the reason the update of the host failed, empty when it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// OSUpdateReport is the result of an OS update. It lists the changed packages of each
+// host, whether the host was rebooted and why the update of the host failed.
+type OSUpdateReport struct {
+	Hosts []HostUpdate `json:"hosts"`
+
+	mu sync.Mutex
+}
+
+// Add adds the outcome of a host to the report, the workers are updated concurrently.
+func (r *OSUpdateReport) Add(u HostUpdate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Hosts = append(r.Hosts, u)
+}
+
+// WriteTable writes the report as a human-readable table with a row for each changed
+// package. The error of a failed host is written on a row of its own after its packages.
+func (r *OSUpdateReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tROLE\tSTATUS\tREBOOTED\tPACKAGES")
+	for _, u := range r.Hosts {
+		status := "updated"
+		if u.Error != "" {
+			status = "failed"
+		}
+		rebooted := "no"
+		if u.Rebooted {
+			rebooted = "yes"
+		}
+		if len(u.Packages) == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t-\n", u.Host, u.Role, status, rebooted)
+		}
+		for i, pkg := range u.Packages {
+			if i == 0 {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", u.Host, u.Role, status, rebooted, pkg)
+			} else {
+				fmt.Fprintf(tw, "\t\t\t\t%s\n", pkg)
+			}
+		}
+		if u.Error != "" {
+			fmt.Fprintf(tw, "\t\t\t\terror: %s\n", u.Error)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write os update report: %w", err)
+	}
+	return nil
+}
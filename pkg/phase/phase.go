@@ -1,9 +1,12 @@
 package phase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/Mirantis/launchpad/pkg/eventstream"
 	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
 	log "github.com/sirupsen/logrus"
 )
@@ -35,6 +38,26 @@ func (p *CleanupDisabling) CleanupDisabled() bool {
 	return p.disableCleanup
 }
 
+// Repeatable can be embedded to phases that need to be run again when resuming a
+// previous run, such as phases that gather facts which later phases rely on.
+type Repeatable struct{}
+
+// RepeatOnResume returns true.
+func (Repeatable) RepeatOnResume() bool {
+	return true
+}
+
+// ReadOnly can be embedded to phases that do not change anything on the hosts. Such
+// phases are run during a dry run and also when resuming a previous run.
+type ReadOnly struct {
+	Repeatable
+}
+
+// IsReadOnly returns true.
+func (ReadOnly) IsReadOnly() bool {
+	return true
+}
+
 // Prepare rceives the cluster config and stores it to the phase's config field.
 func (p *BasicPhase) Prepare(config interface{}) error {
 	if cfg, ok := config.(*mkeconfig.ClusterConfig); ok {
@@ -50,7 +73,7 @@ func (p *HostSelectPhase) Prepare(config interface{}) error {
 		return nil
 	}
 	p.Config = cfg
-	hosts := p.Config.Spec.Hosts.Filter(p.HostFilterFunc)
+	hosts := p.Config.Spec.Hosts.Filter(func(h *mkeconfig.Host) bool { return !h.Dropped() && p.HostFilterFunc(h) })
 	p.Hosts = hosts
 	return nil
 }
@@ -96,6 +119,30 @@ func (e *Error) Count() int {
 	return len(e.Errors)
 }
 
+// AddHostError adds the failure of a phase on a single host to the collection.
+func (e *Error) AddHostError(h *mkeconfig.Host, err error) {
+	e.Errors = append(e.Errors, &HostError{Host: h, Err: err})
+}
+
+// HostErrors returns the host errors in the collection. The boolean is true when all of
+// the collected errors are host errors.
+func (e *Error) HostErrors() ([]*HostError, bool) {
+	hostErrors := make([]*HostError, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		var he *HostError
+		if !errors.As(err, &he) {
+			return hostErrors, false
+		}
+		hostErrors = append(hostErrors, he)
+	}
+	return hostErrors, len(hostErrors) > 0
+}
+
+// Unwrap returns the collected errors.
+func (e *Error) Unwrap() []error {
+	return e.Errors
+}
+
 // Error returns the combined stringified error.
 func (e *Error) Error() string {
 	messages := make([]string, 0, len(e.Errors))
@@ -105,13 +152,43 @@ func (e *Error) Error() string {
 	return strings.Join(messages, "\n")
 }
 
-// RunParallelOnHosts runs a function parallelly on the listed hosts.
-func RunParallelOnHosts(hosts mkeconfig.Hosts, config *mkeconfig.ClusterConfig, action func(h *mkeconfig.Host, config *mkeconfig.ClusterConfig) error) error {
+// HostError is the failure of a phase on a single host. When the manager is run with
+// KeepGoing, a phase that only fails with host errors doesn't stop the run. The failed
+// hosts are dropped instead and left out of the rest of the run.
+type HostError struct {
+	Host  *mkeconfig.Host
+	Phase string
+	Err   error
+}
+
+// Error returns the host and the error message.
+func (e *HostError) Error() string {
+	// most of the host errors already start with the host
+	msg := strings.TrimPrefix(e.Err.Error(), e.Host.String()+": ")
+	if e.Phase != "" {
+		return fmt.Sprintf("%s: %s: %s", e.Host, e.Phase, msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Host, msg)
+}
+
+// Unwrap returns the wrapped error.
+func (e *HostError) Unwrap() error {
+	return e.Err
+}
+
+// RunParallelOnHosts runs a function parallelly on the listed hosts. The outcome on each
+// host is reported to the phase manager through the phase's context. Hosts that have
+// been dropped from the run are left out.
+func RunParallelOnHosts(ctx context.Context, hosts mkeconfig.Hosts, config *mkeconfig.ClusterConfig, action func(h *mkeconfig.Host, config *mkeconfig.ClusterConfig) error) error {
+	running := runningPhaseFrom(ctx)
+	hosts = hosts.Filter(func(h *mkeconfig.Host) bool { return !h.Dropped() })
 	result := hosts.ParallelEach(func(h *mkeconfig.Host) error {
 		err := action(h, config)
 		if err != nil {
 			log.Error(err.Error())
 		}
+		running.add(h.String(), err)
+		eventstream.Emit(eventstream.Result(eventstream.Event{Type: eventstream.TypeHostResult, Phase: running.get(), Host: h.String()}, err))
 		return err
 	})
 	if result != nil {
@@ -0,0 +1,62 @@
+package phase
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// PlannedAction describes a single action a phase would perform.
+type PlannedAction struct {
+	Host   string `json:"host,omitempty"`
+	Action string `json:"action"`
+}
+
+// PlannedPhase describes what a phase would do when run.
+type PlannedPhase struct {
+	Title   string          `json:"title"`
+	Actions []PlannedAction `json:"actions,omitempty"`
+	Note    string          `json:"note,omitempty"`
+}
+
+// Plan is the result of a dry run. It lists the phases that would be run and their
+// intended actions.
+type Plan struct {
+	Phases []PlannedPhase `json:"phases"`
+}
+
+// Empty returns true when the plan has no phases.
+func (p *Plan) Empty() bool {
+	return len(p.Phases) == 0
+}
+
+// WriteTable writes the plan as a human-readable table.
+func (p *Plan) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tHOST\tACTION")
+	for _, ph := range p.Phases {
+		if ph.Note != "" {
+			fmt.Fprintf(tw, "%s\t-\t%s\n", ph.Title, ph.Note)
+		}
+		for _, a := range ph.Actions {
+			host := a.Host
+			if host == "" {
+				host = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", ph.Title, host, a.Action)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// PlannedActions HostSelectPhase default implementation which lists the phase title for each of the selected hosts.
+func (p *HostSelectPhase) PlannedActions() []PlannedAction {
+	actions := make([]PlannedAction, 0, len(p.Hosts))
+	for _, h := range p.Hosts {
+		actions = append(actions, PlannedAction{Host: h.String(), Action: "run"})
+	}
+	return actions
+}
@@ -0,0 +1,76 @@
+package phase
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type readOnlyTestPhase struct {
+	ReadOnly
+	testPhase
+}
+
+type plannedTestPhase struct {
+	testPhase
+	actions []PlannedAction
+}
+
+func (p *plannedTestPhase) PlannedActions() []PlannedAction {
+	return p.actions
+}
+
+func TestManagerDryRun(t *testing.T) {
+	facts := &readOnlyTestPhase{testPhase: testPhase{title: "facts"}}
+	install := &plannedTestPhase{testPhase: testPhase{title: "install"}, actions: []PlannedAction{{Host: "h1", Action: "install things"}}}
+	noop := &plannedTestPhase{testPhase: testPhase{title: "noop"}}
+	generic := &testPhase{title: "generic"}
+
+	m := NewManager(&testConfig{})
+	m.DryRun = true
+	m.AddPhases(facts, install, noop, generic)
+	require.NoError(t, m.Run(context.Background()))
+
+	require.Equal(t, 1, facts.runs, "read-only phase should run")
+	require.Equal(t, 0, install.runs)
+	require.Equal(t, 0, noop.runs)
+	require.Equal(t, 0, generic.runs)
+
+	plan := m.Plan()
+	require.Len(t, plan.Phases, 2)
+	require.Equal(t, "install", plan.Phases[0].Title)
+	require.Equal(t, install.actions, plan.Phases[0].Actions)
+	require.Equal(t, "generic", plan.Phases[1].Title)
+	require.Equal(t, "run", plan.Phases[1].Actions[0].Action)
+
+	var buf bytes.Buffer
+	require.NoError(t, plan.WriteTable(&buf))
+	require.Contains(t, buf.String(), "install  h1    install things")
+}
+
+func TestDriftReportWriteTable(t *testing.T) {
+	report := &DriftReport{}
+	require.True(t, report.Empty())
+	report.Add(Drift{Host: "h1", Role: "worker", Item: "mcr version", Config: "stable-25.0", Cluster: "23.0.15"})
+	report.Add(Drift{Item: "mke version", Config: "3.8.1", Cluster: "3.7.15"})
+	require.False(t, report.Empty())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteTable(&buf))
+	require.Contains(t, buf.String(), "h1    mcr version  stable-25.0  23.0.15")
+	require.Contains(t, buf.String(), "-     mke version  3.8.1        3.7.15")
+}
+
+func TestOSUpdateReportWriteTable(t *testing.T) {
+	report := &OSUpdateReport{}
+	report.Add(HostUpdate{Host: "h1", Role: "worker", Packages: []string{"openssl 3.0.2 -> 3.0.3"}, Rebooted: true})
+	report.Add(HostUpdate{Host: "h2", Role: "worker", Error: "failed to update OS packages"})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteTable(&buf))
+	require.Contains(t, buf.String(), "h1    worker  updated  yes       openssl 3.0.2 -> 3.0.3")
+	require.Contains(t, buf.String(), "h2    worker  failed   no        -")
+	require.Contains(t, buf.String(), "error: failed to update OS packages")
+}
@@ -0,0 +1,174 @@
+package phase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/eventstream"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/logrusorgru/aurora/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// scoped phases declare the resources they operate on. A run of consecutive scoped
+// phases is scheduled as a group: a phase in the group waits only for the earlier
+// phases of the group it requires or shares a resource scope with, the others run
+// concurrently. The phases of a group are prepared before any of them is run, so
+// their Prepare and ShouldRun must not rely on the outcome of the other phases of the
+// group. Phases that don't declare resource scopes, such as the swarm and MKE
+// operations, are never run concurrently with other phases.
+type scoped interface {
+	ResourceScopes() []string
+}
+
+// HostScopes returns the resource scopes for running commands on the given hosts.
+func HostScopes(hosts mkeconfig.Hosts) []string {
+	scopes := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		scopes = append(scopes, "host:"+h.Address())
+	}
+	return scopes
+}
+
+// PhaseDuration is the time it took to run a phase.
+type PhaseDuration struct {
+	Title    string
+	Duration time.Duration
+}
+
+// Durations returns the time each of the phases took in the last run, in the order
+// the phases finished.
+func (m *Manager) Durations() []PhaseDuration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]PhaseDuration(nil), m.durations...)
+}
+
+func (m *Manager) recordDuration(title string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.durations = append(m.durations, PhaseDuration{Title: title, Duration: duration})
+}
+
+func (m *Manager) logDurations() {
+	durations := m.Durations()
+	if len(durations) == 0 {
+		return
+	}
+
+	width := 0
+	for _, d := range durations {
+		width = max(width, len(d.Title))
+	}
+
+	log.Infof("Phase durations:")
+	for _, d := range durations {
+		log.Infof("  %-*s %s", width, d.Title, d.Duration.Truncate(time.Second))
+	}
+}
+
+// group returns the phases starting from index i which can be scheduled together. A
+// phase without resource scopes is always in a group of its own.
+func (m *Manager) group(i int) []phase {
+	if _, ok := m.phases[i].(scoped); !ok {
+		return m.phases[i : i+1]
+	}
+
+	j := i + 1
+	for j < len(m.phases) {
+		if _, ok := m.phases[j].(scoped); !ok {
+			break
+		}
+		j++
+	}
+	return m.phases[i:j]
+}
+
+// runGroup runs the prepared phases of a group. Each phase is started as soon as the
+// earlier phases it depends on have finished. A phase whose dependency has failed is
+// not run and fails with errNotRun. The results are returned in the order of the phases.
+func (m *Manager) runGroup(ctx context.Context, phases []phase) []phaseResult {
+	results := make([]phaseResult, len(phases))
+	if len(phases) == 1 {
+		results[0] = m.execute(ctx, phases[0], true)
+		return results
+	}
+
+	titles := make([]string, 0, len(phases))
+	for _, p := range phases {
+		titles = append(titles, p.Title())
+	}
+	log.Infof(aurora.Green("==> Running phases concurrently: %s").String(), strings.Join(titles, ", "))
+
+	deps := groupDependencies(phases)
+	done := make([]chan struct{}, len(phases))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for i, p := range phases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+
+			for _, d := range deps[i] {
+				<-done[d]
+				if results[d].err != nil {
+					log.Warnf("not running phase '%s' because phase '%s' did not complete", p.Title(), phases[d].Title())
+					eventstream.Emit(eventstream.Event{Type: eventstream.TypePhaseSkip, Phase: p.Title(), Reason: "dependency did not complete"})
+					results[i].err = fmt.Errorf("%w: phase '%s' did not complete", errNotRun, phases[d].Title())
+					return
+				}
+			}
+			results[i] = m.execute(ctx, p, false)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// groupDependencies returns, for each phase of a group, the indexes of the earlier
+// phases of the group it has to wait for.
+func groupDependencies(phases []phase) [][]int {
+	scopes := make([]map[string]struct{}, len(phases))
+	for i, p := range phases {
+		scopes[i] = make(map[string]struct{})
+		if s, ok := p.(scoped); ok {
+			for _, scope := range s.ResourceScopes() {
+				scopes[i][scope] = struct{}{}
+			}
+		}
+	}
+
+	deps := make([][]int, len(phases))
+	for i, p := range phases {
+		var requires []string
+		if d, ok := p.(dependent); ok {
+			requires = d.Requires()
+		}
+		for j := range i {
+			if containsFold(requires, phaseID(phases[j])) || overlaps(scopes[i], scopes[j]) {
+				log.Debugf("phase '%s' waits for phase '%s'", p.Title(), phases[j].Title())
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+	return deps
+}
+
+func overlaps(a, b map[string]struct{}) bool {
+	for k := range a {
+		if _, ok := b[k]; ok {
+			return true
+		}
+	}
+	return false
+}
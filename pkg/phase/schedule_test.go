@@ -0,0 +1,130 @@
+package phase
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/k0sproject/rig"
+	"github.com/stretchr/testify/require"
+)
+
+type scopedTestPhase struct {
+	title   string
+	scopes  []string
+	run     func() error
+	mu      sync.Mutex
+	started time.Time
+	ended   time.Time
+}
+
+func (p *scopedTestPhase) Title() string {
+	return p.title
+}
+
+func (p *scopedTestPhase) ResourceScopes() []string {
+	return p.scopes
+}
+
+func (p *scopedTestPhase) Run(_ context.Context) error {
+	p.mu.Lock()
+	p.started = time.Now()
+	p.mu.Unlock()
+
+	var err error
+	if p.run != nil {
+		err = p.run()
+	}
+
+	p.mu.Lock()
+	p.ended = time.Now()
+	p.mu.Unlock()
+	return err
+}
+
+func TestManagerRunsScopedPhasesConcurrently(t *testing.T) {
+	// both phases wait for each other to have started, which only works if they run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	wait := func() error {
+		started.Done()
+		ch := make(chan struct{})
+		go func() { started.Wait(); close(ch) }()
+		select {
+		case <-ch:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("phases were not run concurrently")
+		}
+	}
+
+	before := &testPhase{title: "before"}
+	a := &scopedTestPhase{title: "a", scopes: []string{"host:a"}, run: wait}
+	b := &scopedTestPhase{title: "b", scopes: []string{"host:b"}, run: wait}
+	after := &testPhase{title: "after"}
+
+	m := NewManager(&testConfig{})
+	m.AddPhases(before, a, b, after)
+	require.NoError(t, m.Run(context.Background()))
+	require.Equal(t, 1, after.runs)
+
+	titles := make([]string, 0, 4)
+	for _, d := range m.Durations() {
+		titles = append(titles, d.Title)
+	}
+	require.ElementsMatch(t, []string{"before", "a", "b", "after"}, titles)
+}
+
+func TestManagerSerializesOverlappingScopes(t *testing.T) {
+	a := &scopedTestPhase{title: "a", scopes: []string{"host:a", "host:b"}, run: func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+	b := &scopedTestPhase{title: "b", scopes: []string{"host:b"}}
+
+	m := NewManager(&testConfig{})
+	m.AddPhases(a, b)
+	require.NoError(t, m.Run(context.Background()))
+	require.False(t, b.started.Before(a.ended), "phase b should start after phase a has finished")
+}
+
+func TestManagerSkipsDependentsOfFailedScopedPhase(t *testing.T) {
+	errFailed := errors.New("failed")
+	a := &scopedTestPhase{title: "a", scopes: []string{"host:a"}, run: func() error { return errFailed }}
+	b := &scopedTestPhase{title: "b", scopes: []string{"host:a"}}
+	c := &scopedTestPhase{title: "c", scopes: []string{"host:c"}}
+
+	m := NewManager(&testConfig{})
+	m.AddPhases(a, b, c)
+	require.ErrorIs(t, m.Run(context.Background()), errFailed)
+	require.True(t, b.started.IsZero(), "phase b shares a scope with the failed phase and should not run")
+	require.False(t, c.started.IsZero(), "phase c is independent and should run")
+}
+
+func TestManagerKeepGoingReportsDependentsNotRun(t *testing.T) {
+	worker := &mkeconfig.Host{Role: "worker", Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.2"}}}
+	a := &scopedTestPhase{title: "a", scopes: []string{"host:a"}, run: func() error {
+		perr := &Error{}
+		perr.AddHostError(worker, errors.New("install failed"))
+		return perr
+	}}
+	b := &scopedTestPhase{title: "b", scopes: []string{"host:a"}}
+	c := &scopedTestPhase{title: "c", scopes: []string{"host:c"}}
+
+	m := NewManager(&testConfig{})
+	m.KeepGoing = true
+	m.Journal = NewJournal(filepath.Join(t.TempDir(), "apply.journal.json"), "hash")
+	m.AddPhases(a, b, c)
+	err := m.Run(context.Background())
+	require.ErrorIs(t, err, errNotRun)
+	require.ErrorIs(t, err, errHostsFailed)
+	require.ErrorContains(t, err, "phase failure: b => not run: phase 'a' did not complete")
+	require.True(t, b.started.IsZero(), "phase b shares a scope with the failed phase and should not run")
+	require.True(t, worker.Dropped())
+	require.False(t, m.Journal.Completed("b"), "a phase that was not run is not complete")
+	require.True(t, m.Journal.Completed("c"))
+}
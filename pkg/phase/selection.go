@@ -0,0 +1,116 @@
+package phase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type dependent interface {
+	Requires() []string
+}
+
+var (
+	errUnknownPhase   = errors.New("unknown phase")
+	errUnsafeSelected = errors.New("unsafe phase selection")
+)
+
+// phaseID returns the identifier of a phase used for selecting phases, which is the
+// name of its type, for example "LabelNodes".
+func phaseID(p phase) string {
+	t := reflect.TypeOf(p)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// IDs returns the identifiers of the added phases in the order they will be run.
+func (m *Manager) IDs() []string {
+	ids := make([]string, 0, len(m.phases))
+	seen := make(map[string]struct{}, len(m.phases))
+	for _, p := range m.phases {
+		id := phaseID(p)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// selected returns true if the phase with the given identifier should be run
+// according to the Only and Skip lists.
+func (m *Manager) selected(id string) bool {
+	if len(m.Only) > 0 && !containsFold(m.Only, id) {
+		return false
+	}
+	return !containsFold(m.Skip, id)
+}
+
+// validateSelection checks that the Only and Skip lists only name known phases and that
+// all the phases a selected phase requires are also selected. Unselected requirements
+// are only allowed when AllowUnsafeSelection is set.
+func (m *Manager) validateSelection() error {
+	if len(m.Only) == 0 && len(m.Skip) == 0 {
+		return nil
+	}
+
+	ids := m.IDs()
+	for _, id := range append(append([]string{}, m.Only...), m.Skip...) {
+		if !containsFold(ids, id) {
+			return fmt.Errorf("%w: %s (available phases: %s)", errUnknownPhase, id, strings.Join(ids, ", "))
+		}
+	}
+
+	requires := make(map[string][]string, len(m.phases))
+	for _, p := range m.phases {
+		if d, ok := p.(dependent); ok {
+			requires[phaseID(p)] = d.Requires()
+		}
+	}
+
+	for _, id := range ids {
+		if !m.selected(id) {
+			continue
+		}
+		for _, req := range requiredPhases(id, requires, map[string]struct{}{}) {
+			if !containsFold(ids, req) || m.selected(req) {
+				continue
+			}
+			if !m.AllowUnsafeSelection {
+				return fmt.Errorf("%w: phase %s requires phase %s which is not selected (use --allow-unsafe-selection to override)", errUnsafeSelected, id, req)
+			}
+			log.Warnf("phase %s requires phase %s which is not selected, continuing because of --allow-unsafe-selection", id, req)
+		}
+	}
+
+	return nil
+}
+
+// requiredPhases returns the direct and indirect requirements of a phase.
+func requiredPhases(id string, requires map[string][]string, seen map[string]struct{}) []string {
+	var result []string
+	for _, req := range requires[id] {
+		if _, ok := seen[req]; ok {
+			continue
+		}
+		seen[req] = struct{}{}
+		result = append(result, req)
+		result = append(result, requiredPhases(req, requires, seen)...)
+	}
+	return result
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
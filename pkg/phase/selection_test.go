@@ -0,0 +1,67 @@
+package phase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type GatherTestFacts struct{ testPhase }
+
+type InstallTestProduct struct{ testPhase }
+
+func (p *InstallTestProduct) Requires() []string {
+	return []string{"GatherTestFacts"}
+}
+
+type LabelTestNodes struct{ testPhase }
+
+func TestManagerPhaseSelection(t *testing.T) {
+	newPhases := func() (*GatherTestFacts, *InstallTestProduct, *LabelTestNodes) {
+		return &GatherTestFacts{testPhase{title: "facts"}}, &InstallTestProduct{testPhase{title: "install"}}, &LabelTestNodes{testPhase{title: "label"}}
+	}
+
+	t.Run("only", func(t *testing.T) {
+		facts, install, label := newPhases()
+		m := NewManager(&testConfig{})
+		m.AddPhases(facts, install, label)
+		m.Only = []string{"gathertestfacts", "InstallTestProduct"}
+		require.NoError(t, m.Run(context.Background()))
+		require.Equal(t, 1, facts.runs)
+		require.Equal(t, 1, install.runs)
+		require.Equal(t, 0, label.runs)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		facts, install, label := newPhases()
+		m := NewManager(&testConfig{})
+		m.AddPhases(facts, install, label)
+		m.Skip = []string{"LabelTestNodes"}
+		require.NoError(t, m.Run(context.Background()))
+		require.Equal(t, 1, install.runs)
+		require.Equal(t, 0, label.runs)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		facts, install, label := newPhases()
+		m := NewManager(&testConfig{})
+		m.AddPhases(facts, install, label)
+		m.Only = []string{"Foo"}
+		require.ErrorIs(t, m.Run(context.Background()), errUnknownPhase)
+	})
+
+	t.Run("unsafe", func(t *testing.T) {
+		facts, install, label := newPhases()
+		m := NewManager(&testConfig{})
+		m.AddPhases(facts, install, label)
+		m.Skip = []string{"GatherTestFacts"}
+		require.ErrorIs(t, m.Run(context.Background()), errUnsafeSelected)
+		require.Equal(t, 0, install.runs)
+
+		m.AllowUnsafeSelection = true
+		require.NoError(t, m.Run(context.Background()))
+		require.Equal(t, 0, facts.runs)
+		require.Equal(t, 1, install.runs)
+	})
+}
@@ -0,0 +1,18 @@
+package config
+
+// FieldError is an error in the value of a configuration field.
+type FieldError struct {
+	// Path is the YAML path of the field, for example spec.mke.installFlags.
+	Path string
+	Err  error
+}
+
+// Error returns the error message prefixed with the field path.
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -43,13 +44,23 @@ func (p *Connect) ShouldRun() bool {
 	return len(p.hosts) > 0
 }
 
+// IsReadOnly is true as the phase does not change anything on the hosts.
+func (p *Connect) IsReadOnly() bool {
+	return true
+}
+
+// RepeatOnResume is true as the connections need to be opened again when resuming a previous run.
+func (p *Connect) RepeatOnResume() bool {
+	return true
+}
+
 // Title for the phase.
 func (p *Connect) Title() string {
 	return "Open Remote Connection"
 }
 
 // Run connects to all the hosts in parallel.
-func (p *Connect) Run() error {
+func (p *Connect) Run(ctx context.Context) error {
 	var (
 		wg     sync.WaitGroup
 		result error
@@ -60,7 +71,7 @@ func (p *Connect) Run() error {
 		wg.Add(1)
 		go func(h connectable) {
 			defer wg.Done()
-			if err := p.connectHost(h); err != nil {
+			if err := p.connectHost(ctx, h); err != nil {
 				mu.Lock()
 				result = errors.Join(result, fmt.Errorf("connect %s: %w", h, err))
 				mu.Unlock()
@@ -79,7 +90,7 @@ func (p *Connect) Run() error {
 
 const retries = 60
 
-func (p *Connect) connectHost(host connectable) error {
+func (p *Connect) connectHost(ctx context.Context, host connectable) error {
 	err := retry.Do(
 		func() error {
 			if err := host.Connect(); err != nil {
@@ -101,6 +112,7 @@ func (p *Connect) connectHost(host connectable) error {
 		retry.MaxJitter(time.Second*2),
 		retry.Delay(time.Second*3),
 		retry.Attempts(retries),
+		retry.Context(ctx),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
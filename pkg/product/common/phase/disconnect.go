@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"reflect"
 	"sync"
 
@@ -36,13 +37,23 @@ func (p *Disconnect) ShouldRun() bool {
 	return len(p.hosts) > 0
 }
 
+// IsReadOnly is true as the phase does not change anything on the hosts.
+func (p *Disconnect) IsReadOnly() bool {
+	return true
+}
+
+// RepeatOnResume is true as the connections need to be closed again when resuming a previous run.
+func (p *Disconnect) RepeatOnResume() bool {
+	return true
+}
+
 // Title for the phase.
 func (p *Disconnect) Title() string {
 	return "Close Connection"
 }
 
 // Run disconnects from all the hosts.
-func (p *Disconnect) Run() error {
+func (p *Disconnect) Run(_ context.Context) error {
 	var wg sync.WaitGroup
 	wg.Add(len(p.hosts))
 
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -16,6 +17,11 @@ type host interface {
 	String() string
 }
 
+// droppable hosts can be dropped from the run after a failure, their hooks are not run.
+type droppable interface {
+	Dropped() bool
+}
+
 // RunHooks phase runs a set of hooks configured for the host.
 type RunHooks struct {
 	Action string
@@ -58,6 +64,11 @@ func (p *RunHooks) ShouldRun() bool {
 	return len(p.steps) > 0
 }
 
+// Requires returns the phases that need to be run before the hooks can be run.
+func (p *RunHooks) Requires() []string {
+	return []string{"Connect"}
+}
+
 func ucFirst(s string) string {
 	if s == "" {
 		return ""
@@ -72,7 +83,7 @@ func (p *RunHooks) Title() string {
 }
 
 // Run does all the prep work on the hosts in parallel.
-func (p *RunHooks) Run() error {
+func (p *RunHooks) Run(_ context.Context) error {
 	var (
 		wg     sync.WaitGroup
 		result error
@@ -81,6 +92,9 @@ func (p *RunHooks) Run() error {
 
 	for h, steps := range p.steps {
 		h, steps := h, steps // capture range variables
+		if d, ok := h.(droppable); ok && d.Dropped() {
+			continue
+		}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
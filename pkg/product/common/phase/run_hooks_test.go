@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -20,7 +21,12 @@ type testspec struct {
 type testhost struct {
 	Hooks commonconfig.Hooks
 
-	Cmds []string
+	Cmds    []string
+	dropped bool
+}
+
+func (t *testhost) Dropped() bool {
+	return t.dropped
 }
 
 func (t *testhost) String() string {
@@ -63,7 +69,7 @@ func TestRun(t *testing.T) {
 	}
 	p := RunHooks{Action: "apply", Stage: "before"}
 	require.NoError(t, p.Prepare(d))
-	require.NoError(t, p.Run())
+	require.NoError(t, p.Run(context.Background()))
 	require.Len(t, host.Cmds, 2)
 }
 
@@ -84,7 +90,7 @@ func TestRunError(t *testing.T) {
 	host.Cmds = []string{}
 	p := RunHooks{Action: "apply", Stage: "before"}
 	require.NoError(t, p.Prepare(d))
-	require.Error(t, p.Run(), "test error")
+	require.Error(t, p.Run(context.Background()), "test error")
 	require.Len(t, host.Cmds, 0)
 }
 
@@ -92,3 +98,20 @@ func TestTitle(t *testing.T) {
 	p := RunHooks{Action: "apply", Stage: "before"}
 	require.Equal(t, "Run Before Apply Hooks", p.Title())
 }
+
+func TestRunDropped(t *testing.T) {
+	hooks := commonconfig.Hooks{"apply": {"after": []string{"echo hello"}}}
+	host := &testhost{Hooks: hooks}
+	dropped := &testhost{Hooks: hooks, dropped: true}
+
+	d := &testcfg{
+		Spec: &testspec{
+			Hosts: []*testhost{host, dropped},
+		},
+	}
+	p := RunHooks{Action: "apply", Stage: "after"}
+	require.NoError(t, p.Prepare(d))
+	require.NoError(t, p.Run(context.Background()))
+	require.Len(t, host.Cmds, 1)
+	require.Empty(t, dropped.Cmds, "the hooks of a dropped host should not be run")
+}
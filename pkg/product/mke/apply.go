@@ -1,20 +1,102 @@
 package mke
 
 import (
+	"context"
 	"crypto/sha1" //nolint:gosec // sha1 is used for simple analytics id generation
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/analytics"
 	"github.com/Mirantis/launchpad/pkg/phase"
+	"github.com/Mirantis/launchpad/pkg/product"
 	common "github.com/Mirantis/launchpad/pkg/product/common/phase"
 	mke "github.com/Mirantis/launchpad/pkg/product/mke/phase"
 	event "github.com/segmentio/analytics-go/v3"
+	log "github.com/sirupsen/logrus"
 )
 
 // Apply - installs Docker Enterprise (MKE, MSR, MCR) on the hosts that are defined in the config.
-func (p *MKE) Apply(disableCleanup, force bool, concurrency int, forceUpgrade bool) error {
+func (p *MKE) Apply(ctx context.Context, opts product.ApplyOptions) error {
+	phaseManager := p.applyManager(opts, false)
+
+	journal, err := p.applyJournal(opts.Resume)
+	if err != nil {
+		return err
+	}
+	phaseManager.Journal = journal
+
+	if err := phaseManager.Run(ctx); err != nil {
+		log.Infof("Run 'launchpad apply --resume' to continue from the failed phase")
+		return fmt.Errorf("failed to apply MKE: %w", err)
+	}
+
+	if err := journal.Remove(); err != nil {
+		log.Warnf("%s", err)
+	}
+
+	windowsWorkersCount := 0
+	linuxWorkersCount := 0
+	for _, h := range p.ClusterConfig.Spec.Workers() {
+		if h.IsWindows() {
+			windowsWorkersCount++
+		} else {
+			linuxWorkersCount++
+		}
+	}
+	clusterID := p.ClusterConfig.Spec.MKE.Metadata.ClusterID
+	props := event.Properties{
+		"kind":            p.ClusterConfig.Kind,
+		"api_version":     p.ClusterConfig.APIVersion,
+		"hosts":           len(p.ClusterConfig.Spec.Hosts),
+		"managers":        len(p.ClusterConfig.Spec.Managers()),
+		"dtrs":            len(p.ClusterConfig.Spec.MSRs()),
+		"linux_workers":   linuxWorkersCount,
+		"windows_workers": windowsWorkersCount,
+		"engine_channel":  p.ClusterConfig.Spec.MCR.Channel,
+		"cluster_id":      clusterID,
+		// send mke analytics user id as ucp_instance_id property
+		"ucp_instance_id": fmt.Sprintf("%x", sha1.Sum([]byte(clusterID))), //nolint:gosec // sha1 is used for simple analytics id generation
+	}
+
+	analytics.TrackEvent("Cluster Installed", props)
+
+	return nil
+}
+
+// applyJournal returns the journal for the apply run. When resuming, the journal of the
+// previous run is loaded unless the configuration has changed since it was written.
+func (p *MKE) applyJournal(resume bool) (*phase.Journal, error) {
+	path, err := phase.DefaultJournalPath(p.ClusterName(), "apply")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve journal path: %w", err)
+	}
+
+	hash, err := phase.ConfigHash(p.ClusterConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate configuration checksum: %w", err)
+	}
+
+	if resume {
+		journal, err := phase.LoadJournal(path, hash)
+		if err == nil {
+			log.Infof("resuming a previous run from journal %s", path)
+			return journal, nil
+		}
+		log.Warnf("can't resume a previous run, starting from the beginning: %s", err)
+	}
+
+	return phase.NewJournal(path, hash), nil
+}
+
+// applyManager returns a phase manager with the phases needed for apply. With dryRun,
+// the manager only runs the read-only phases and plans the rest.
+func (p *MKE) applyManager(opts product.ApplyOptions, dryRun bool) *phase.Manager {
 	phaseManager := phase.NewManager(&p.ClusterConfig)
-	phaseManager.SkipCleanup = disableCleanup
+	phaseManager.DryRun = dryRun
+	phaseManager.SkipCleanup = opts.DisableCleanup
+	phaseManager.Only = opts.Phases
+	phaseManager.Skip = opts.SkipPhases
+	phaseManager.AllowUnsafeSelection = opts.AllowUnsafeSelection
+	phaseManager.KeepGoing = opts.KeepGoing
 
 	phaseManager.AddPhases(
 		&mke.UpgradeCheck{},
@@ -22,15 +104,15 @@ func (p *MKE) Apply(disableCleanup, force bool, concurrency int, forceUpgrade bo
 		&common.Connect{},
 		&mke.DetectOS{},
 		&mke.GatherFacts{},
-		&mke.ValidateFacts{Force: force},
-		&mke.ValidateHosts{},
+		&mke.ValidateFacts{Force: opts.Force},
+		&mke.ValidateHosts{DryRun: dryRun},
 		&common.RunHooks{Stage: "before", Action: "apply"},
 		&mke.PrepareHost{},
 
 		// begin mcr/mke phases
 		&mke.ConfigureMCR{},
 		&mke.InstallMCR{},
-		&mke.UpgradeMCR{Concurrency: concurrency, ForceUpgrade: forceUpgrade},
+		&mke.UpgradeMCR{Concurrency: opts.Concurrency, ForceUpgrade: opts.ForceUpgrade},
 		&mke.InstallMCRLicense{},
 		&mke.RestartMCR{},
 		&mke.LoadImages{},
@@ -42,6 +124,7 @@ func (p *MKE) Apply(disableCleanup, force bool, concurrency int, forceUpgrade bo
 		&mke.UpgradeMKE{},
 		&mke.JoinManagers{},
 		&mke.JoinWorkers{},
+		&mke.ChangeRoles{},
 
 		// begin MSR phases
 		&mke.PullMSRImages{},
@@ -52,41 +135,22 @@ func (p *MKE) Apply(disableCleanup, force bool, concurrency int, forceUpgrade bo
 		// end MSR phases
 
 		&mke.LabelNodes{},
-		&mke.RemoveNodes{},
+		&mke.RemoveNodes{Force: opts.Force},
 		&common.RunHooks{Stage: "after", Action: "apply"},
 		&common.Disconnect{},
 		&mke.Info{},
 	)
 
-	if err := phaseManager.Run(); err != nil {
-		return fmt.Errorf("failed to apply MKE: %w", err)
-	}
+	return phaseManager
+}
 
-	windowsWorkersCount := 0
-	linuxWorkersCount := 0
-	for _, h := range p.ClusterConfig.Spec.Workers() {
-		if h.IsWindows() {
-			windowsWorkersCount++
-		} else {
-			linuxWorkersCount++
-		}
-	}
-	clusterID := p.ClusterConfig.Spec.MKE.Metadata.ClusterID
-	props := event.Properties{
-		"kind":            p.ClusterConfig.Kind,
-		"api_version":     p.ClusterConfig.APIVersion,
-		"hosts":           len(p.ClusterConfig.Spec.Hosts),
-		"managers":        len(p.ClusterConfig.Spec.Managers()),
-		"dtrs":            len(p.ClusterConfig.Spec.MSRs()),
-		"linux_workers":   linuxWorkersCount,
-		"windows_workers": windowsWorkersCount,
-		"engine_channel":  p.ClusterConfig.Spec.MCR.Channel,
-		"cluster_id":      clusterID,
-		// send mke analytics user id as ucp_instance_id property
-		"ucp_instance_id": fmt.Sprintf("%x", sha1.Sum([]byte(clusterID))), //nolint:gosec // sha1 is used for simple analytics id generation
-	}
+// Plan runs the read-only apply phases and returns the actions the rest of the phases would perform.
+func (p *MKE) Plan(ctx context.Context, opts product.ApplyOptions) (*phase.Plan, error) {
+	phaseManager := p.applyManager(opts, true)
 
-	analytics.TrackEvent("Cluster Installed", props)
+	if err := phaseManager.Run(ctx); err != nil {
+		return nil, fmt.Errorf("failed to plan MKE apply: %w", err)
+	}
 
-	return nil
+	return phaseManager.Plan(), nil
 }
@@ -1,6 +1,7 @@
 package mke
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
@@ -27,7 +28,7 @@ func (p *MKE) ClientConfig() error {
 		&common.Disconnect{},
 	)
 
-	if err := phaseManager.Run(); err != nil {
+	if err := phaseManager.Run(context.Background()); err != nil {
 		return fmt.Errorf("failed to download client bundle: %w", err)
 	}
 	return nil
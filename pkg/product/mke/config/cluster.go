@@ -1,13 +1,16 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Mirantis/launchpad/pkg/constant"
 	"github.com/Mirantis/launchpad/pkg/docker/hub"
 	common "github.com/Mirantis/launchpad/pkg/product/common/config"
 	validator "github.com/go-playground/validator/v10"
 	"github.com/k0sproject/rig"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // ClusterMeta defines cluster metadata.
@@ -40,11 +43,20 @@ func (c *ClusterConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// SetContext binds the context to the commands run on all of the hosts.
+func (c *ClusterConfig) SetContext(ctx context.Context) {
+	for _, h := range c.Spec.Hosts {
+		h.SetContext(ctx)
+	}
+}
+
 // Validate validates that everything in the config makes sense
 // Currently we do only very "static" validation using https://github.com/go-playground/validator
 func (c *ClusterConfig) Validate() error {
 	validator := validator.New(validator.WithRequiredStructEnabled())
 	validator.RegisterStructValidation(roleChecks, ClusterSpec{})
+	_ = validator.RegisterValidation("labelkey", labelKeyCheck)
+	_ = validator.RegisterValidation("labelvalue", labelValueCheck)
 	if err := validator.Struct(c); err != nil {
 		return fmt.Errorf("cluster config validation failed: %w", err)
 	}
@@ -62,6 +74,21 @@ func roleChecks(sl validator.StructLevel) {
 	}
 }
 
+// labelKeyCheck checks that the node label or taint key is valid in Kubernetes and isn't
+// one of the labels launchpad uses itself.
+func labelKeyCheck(fl validator.FieldLevel) bool {
+	key := fl.Field().String()
+	if strings.HasPrefix(key, constant.LabelPrefix) {
+		return false
+	}
+	return len(validation.IsQualifiedName(key)) == 0
+}
+
+// labelValueCheck checks that the node label or taint value is valid in Kubernetes.
+func labelValueCheck(fl validator.FieldLevel) bool {
+	return len(validation.IsValidLabelValue(fl.Field().String())) == 0
+}
+
 // Init returns an example of configuration file contents.
 func Init(kind string) *ClusterConfig {
 	mkeV, err := hub.LatestTag("mirantis", "ucp", false)
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/inventory"
 	common "github.com/Mirantis/launchpad/pkg/product/common/config"
 	retry "github.com/avast/retry-go"
 	"github.com/creasty/defaults"
@@ -25,11 +26,16 @@ type Cluster struct {
 
 // ClusterSpec defines cluster spec.
 type ClusterSpec struct {
-	Hosts   Hosts            `yaml:"hosts" validate:"required,min=1,dive"`
-	MKE     MKEConfig        `yaml:"mke,omitempty"`
-	MSR     *MSRConfig       `yaml:"msr,omitempty"`
-	MCR     common.MCRConfig `yaml:"mcr,omitempty"`
-	Cluster Cluster          `yaml:"cluster"`
+	Hosts Hosts `yaml:"hosts" validate:"required,min=1,dive"`
+	// HostDefaults are merged into the Hosts in UnmarshalYAML.
+	HostDefaults HostDefaults `yaml:"hostDefaults,omitempty" validate:"dive,keys,oneof=all manager worker msr,endkeys" jsonschema:"description=Settings shared by the hosts, under all for every host or under a role for the hosts of that role"`
+	// Inventory lists the external sources of hosts. Their hosts are added to Hosts when
+	// the configuration is loaded and the key is removed, see pkg/config/inventory.go.
+	Inventory []inventory.Source `yaml:"inventory,omitempty" validate:"dive" jsonschema:"description=External sources the hosts are read from, the entries of hosts override the hosts with the same address"`
+	MKE       MKEConfig          `yaml:"mke,omitempty"`
+	MSR       *MSRConfig         `yaml:"msr,omitempty"`
+	MCR       common.MCRConfig   `yaml:"mcr,omitempty"`
+	Cluster   Cluster            `yaml:"cluster"`
 }
 
 // Workers filters only the workers from the cluster config.
@@ -54,9 +60,10 @@ func (c *ClusterSpec) WorkersAndMSRs() Hosts {
 	})
 }
 
-// SwarmLeader resolves the current swarm leader host.
+// SwarmLeader resolves the current swarm leader host. The hosts dropped from the run
+// are left out.
 func (c *ClusterSpec) SwarmLeader() *Host {
-	m := c.Managers()
+	m := c.Hosts.Filter(func(h *Host) bool { return h.Role == "manager" && !h.Dropped() })
 	leader := m.Find(isSwarmLeader)
 	if leader != nil {
 		log.Debugf("%s: is the swarm leader", leader)
@@ -173,9 +180,29 @@ func (c *ClusterSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	c.MCR = common.MCRConfig{}
 	c.MKE = NewMKEConfig()
 
+	raw := make(map[string]interface{})
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
 	if err := unmarshal(specAlias); err != nil {
 		return err
 	}
+
+	rawHosts, _ := raw["hosts"].([]interface{})
+	hostDefaults, _ := raw["hostDefaults"].(map[interface{}]interface{})
+	expanded, changed, err := expandHosts(rawHosts, hostDefaults)
+	if err != nil {
+		return err
+	}
+	if changed {
+		hosts, err := unmarshalExpandedHosts(expanded)
+		if err != nil {
+			return err
+		}
+		c.Hosts = hosts
+	}
+
 	if c.MCR.Channel == "" {
 		return fmt.Errorf("%w: missing spec.mcr.channel — the mcr block is required; set a channel (e.g. channel: stable-29.4)", errInvalidConfig)
 	}
@@ -244,8 +271,9 @@ func IsMSRInstalled(h *Host) bool {
 func (c *ClusterSpec) MSRLeader() *Host {
 	// MSR doesn't have the concept of leaders during the installation phase,
 	// but we need to make sure we have a Host to reference during our other
-	// bootstrap operations: Upgrade and Join
-	msrs := c.MSRs()
+	// bootstrap operations: Upgrade and Join. The hosts dropped from the run are
+	// left out, nil is returned when there are no MSR hosts left.
+	msrs := c.Hosts.Filter(func(h *Host) bool { return h.Role == "msr" && !h.Dropped() })
 	h := msrs.Find(IsMSRInstalled)
 	if h != nil {
 		log.Debugf("%s: found MSR installed, using as leader", h)
@@ -183,3 +183,23 @@ func TestMKEClusterSpecMKEURLFromMSRMKEUrl(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "https://mke.acme.com:5555/", url.String())
 }
+
+func TestMKEClusterSpecMSRLeaderDropped(t *testing.T) {
+	installed := &Host{
+		Connection:  rig.Connection{SSH: &rig.SSH{Address: "192.168.1.4"}},
+		Role:        "msr",
+		MSRMetadata: &MSRMetadata{Installed: true},
+	}
+	other := &Host{
+		Connection: rig.Connection{SSH: &rig.SSH{Address: "192.168.1.5"}},
+		Role:       "msr",
+	}
+	spec := ClusterSpec{Hosts: []*Host{manager, installed, other}}
+	require.Equal(t, installed, spec.MSRLeader())
+
+	installed.Drop()
+	require.Equal(t, other, spec.MSRLeader())
+
+	other.Drop()
+	require.Nil(t, spec.MSRLeader())
+}
@@ -35,4 +35,12 @@ type HostConfigurer interface {
 	Reboot(os.Host) error
 	AuthorizeDocker(os.Host) error
 	PrepareHost(os.Host) error
+	UpdatePackages(os.Host, bool) ([]string, error)
+	RebootRequired(os.Host) (bool, error)
+}
+
+// rebootCleaner is implemented by the configurers that leave something behind on the
+// host to reboot it, such as the scheduled task of the windows configurer.
+type rebootCleaner interface {
+	CleanUpReboot(os.Host) error
 }
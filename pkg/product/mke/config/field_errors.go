@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Mirantis/launchpad/pkg/constant"
+	common "github.com/Mirantis/launchpad/pkg/product/common/config"
+	validator "github.com/go-playground/validator/v10"
+)
+
+var errFieldInvalid = errors.New("invalid value")
+
+// ValidationErrors runs Validate and returns the failed validations as
+// *common.FieldError, which carry the YAML paths of the fields.
+func (c *ClusterConfig) ValidationErrors() []error {
+	err := c.Validate()
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []error{err}
+	}
+
+	errs := make([]error, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		errs = append(errs, &common.FieldError{
+			Path: yamlPath(reflect.TypeOf(c).Elem(), fe.StructNamespace()),
+			Err:  fmt.Errorf("%w: %s", errFieldInvalid, describeFieldError(fe)),
+		})
+	}
+	return errs
+}
+
+// describeFieldError returns a readable description of a failed validation.
+func describeFieldError(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "a value is required"
+	case "oneof":
+		return fmt.Sprintf("%v is not one of: %s", fe.Value(), fe.Param())
+	case "eq":
+		return fmt.Sprintf("%v must be %s", fe.Value(), fe.Param())
+	case "file":
+		return fmt.Sprintf("file %v does not exist", fe.Value())
+	case "labelkey":
+		return fmt.Sprintf("%v is not a valid label key or uses the reserved %s prefix", fe.Value(), constant.LabelPrefix)
+	case "labelvalue":
+		return fmt.Sprintf("%v is not a valid label value", fe.Value())
+	case "manager required":
+		return "at least one host with the manager role is required"
+	}
+	check := fe.Tag()
+	if fe.Param() != "" {
+		check += "=" + fe.Param()
+	}
+	switch fe.Kind() { //nolint:exhaustive
+	case reflect.String, reflect.Int, reflect.Bool:
+		return fmt.Sprintf("%v fails the %s check", fe.Value(), check)
+	default:
+		return "fails the " + check + " check"
+	}
+}
+
+// yamlPath converts the namespace of a validation error, such as
+// ClusterConfig.Spec.Hosts[0].Connection.SSH.Address, to the YAML path of the field,
+// such as spec.hosts[0].ssh.address.
+func yamlPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	path := make([]string, 0, len(segments))
+
+	// the first segment is the name of the type itself
+	for _, segment := range segments[1:] {
+		name, index, hasIndex := strings.Cut(segment, "[")
+
+		var field reflect.StructField
+		found := false
+		if t != nil {
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Struct {
+				field, found = t.FieldByName(name)
+			}
+		}
+
+		switch {
+		case !found:
+			// struct level validations report fields that aren't struct fields
+			path = append(path, name)
+			t = nil
+		default:
+			tagName, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			switch {
+			case strings.Contains(opts, "inline"):
+			case tagName != "":
+				path = append(path, tagName)
+			default:
+				path = append(path, strings.ToLower(name))
+			}
+			t = field.Type
+		}
+
+		if hasIndex {
+			path[len(path)-1] += "[" + index
+			if t != nil {
+				for t.Kind() == reflect.Pointer {
+					t = t.Elem()
+				}
+				switch t.Kind() { //nolint:exhaustive
+				case reflect.Slice, reflect.Array, reflect.Map:
+					t = t.Elem()
+				default:
+					t = nil
+				}
+			}
+		}
+	}
+
+	return strings.Join(path, ".")
+}
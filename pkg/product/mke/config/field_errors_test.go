@@ -0,0 +1,82 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	common "github.com/Mirantis/launchpad/pkg/product/common/config"
+	"github.com/k0sproject/rig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAMLPath(t *testing.T) {
+	typ := reflect.TypeOf(ClusterConfig{})
+	require.Equal(t, "spec.hosts[1].ssh.address", yamlPath(typ, "ClusterConfig.Spec.Hosts[1].Connection.SSH.Address"))
+	require.Equal(t, "spec.mke.caCertPath", yamlPath(typ, "ClusterConfig.Spec.MKE.CACertPath"))
+	require.Equal(t, "apiVersion", yamlPath(typ, "ClusterConfig.APIVersion"))
+	require.Equal(t, "spec.hosts", yamlPath(typ, "ClusterConfig.Spec.hosts"))
+}
+
+func TestValidationErrors(t *testing.T) {
+	c := &ClusterConfig{
+		APIVersion: "launchpad.mirantis.com/mke/v1.6",
+		Kind:       "mke",
+		Metadata:   &ClusterMeta{Name: "test"},
+		Spec: &ClusterSpec{
+			Hosts: Hosts{
+				{Role: "banana", Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.1", Port: 22}}},
+			},
+		},
+	}
+	errs := c.ValidationErrors()
+	paths := make([]string, 0, len(errs))
+	for _, err := range errs {
+		var fieldErr *common.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		paths = append(paths, fieldErr.Path)
+	}
+	require.ElementsMatch(t, []string{"spec.hosts[0].ssh.user", "spec.hosts[0].role", "spec.mke.version", "spec.hosts"}, paths)
+}
+
+func TestValidationErrorsLabels(t *testing.T) {
+	c := &ClusterConfig{
+		APIVersion: "launchpad.mirantis.com/mke/v1.6",
+		Kind:       "mke",
+		Metadata:   &ClusterMeta{Name: "test"},
+		Spec: &ClusterSpec{
+			MKE: MKEConfig{Version: "3.8.0"},
+			Hosts: Hosts{
+				{
+					Role:       "manager",
+					Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.1", User: "root", Port: 22}},
+					Labels: map[string]string{
+						"zone":                     "eu-1",
+						"example.com/gpu":          "true",
+						"com.mirantis.launchpad.x": "true",
+						"bad key":                  "true",
+						"example.com/valid-value":  "not valid!",
+					},
+					Taints: []Taint{
+						{Key: "dedicated", Value: "db", Effect: "NoSchedule"},
+						{Key: "dedicated", Effect: "Sometimes"},
+						{Key: "bad/key/here", Effect: "NoExecute"},
+					},
+				},
+			},
+		},
+	}
+	errs := c.ValidationErrors()
+	paths := make([]string, 0, len(errs))
+	for _, err := range errs {
+		var fieldErr *common.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		paths = append(paths, fieldErr.Path)
+	}
+	require.ElementsMatch(t, []string{
+		"spec.hosts[0].labels[com.mirantis.launchpad.x]",
+		"spec.hosts[0].labels[bad key]",
+		"spec.hosts[0].labels[example.com/valid-value]",
+		"spec.hosts[0].taints[1].effect",
+		"spec.hosts[0].taints[2].key",
+	}, paths)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,9 +10,12 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
+	mcclog "github.com/Mirantis/launchpad/pkg/log"
 	common "github.com/Mirantis/launchpad/pkg/product/common/config"
+	"github.com/Mirantis/launchpad/pkg/tracing"
 	"github.com/Mirantis/launchpad/pkg/util/byteutil"
 	retry "github.com/avast/retry-go"
 	"github.com/creasty/defaults"
@@ -20,6 +24,7 @@ import (
 	"github.com/k0sproject/rig/exec"
 	"github.com/k0sproject/rig/os/registry"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // HostMetadata resolved metadata for host.
@@ -31,7 +36,9 @@ type HostMetadata struct {
 	MCRRestartRequired bool
 	ImagesToUpload     []string
 	TotalImageBytes    uint64
-	MCRInstalled       bool // Indicates that in this run an MCR install has been executed (not that in installation has been discovered)
+	MCRInstalled       bool        // Indicates that in this run an MCR install has been executed (not that in installation has been discovered)
+	DaemonConfig       dig.Mapping // The daemon.json on the host when the facts were gathered
+	SwarmRole          string      // The swarm role of the node of the host when the facts were gathered, empty when not in a swarm
 }
 
 // MSRMetadata is metadata needed by MSR for configuration and is gathered at
@@ -75,6 +82,8 @@ type Host struct {
 	PrivateInterface string            `yaml:"privateInterface,omitempty" validate:"omitempty,gt=2"`
 	DaemonConfig     dig.Mapping       `yaml:"mcrConfig,flow,omitempty" default:"{}"`
 	Environment      map[string]string `yaml:"environment,flow,omitempty" default:"{}"`
+	Labels           map[string]string `yaml:"labels,omitempty" validate:"dive,keys,labelkey,endkeys,labelvalue" jsonschema:"description=Labels of the swarm and Kubernetes node of the host"`
+	Taints           []Taint           `yaml:"taints,omitempty" validate:"dive" jsonschema:"description=Taints of the Kubernetes node of the host"`
 	Hooks            common.Hooks      `yaml:"hooks,omitempty" validate:"dive,keys,oneof=apply reset,endkeys,dive,keys,oneof=before after,endkeys,omitempty"`
 	ImageDir         string            `yaml:"imageDir,omitempty"`
 	SudoDocker       bool              `yaml:"sudodocker"`
@@ -91,6 +100,28 @@ type Host struct {
 	MSRMetadata *MSRMetadata   `yaml:"-"`
 	Configurer  HostConfigurer `yaml:"-"`
 	Errors      errs           `yaml:"-"`
+
+	// mu guards ctx and interrupted, the commands of a host can run from several
+	// goroutines, for example on the swarm leader from the actions of the other hosts.
+	mu          sync.Mutex
+	ctx         context.Context
+	interrupted bool
+	dropped     bool
+}
+
+// Taint is a taint of the Kubernetes node of a host.
+type Taint struct {
+	Key    string `yaml:"key" validate:"required,labelkey"`
+	Value  string `yaml:"value,omitempty" validate:"labelvalue"`
+	Effect string `yaml:"effect" validate:"oneof=NoSchedule PreferNoSchedule NoExecute"`
+}
+
+// String returns the taint in the key=value:effect format of kubectl taint.
+func (t Taint) String() string {
+	if t.Value == "" {
+		return t.Key + ":" + t.Effect
+	}
+	return t.Key + "=" + t.Value + ":" + t.Effect
 }
 
 // UnmarshalYAML sets in some sane defaults when unmarshaling the data from yaml.
@@ -158,20 +189,194 @@ func (h *Host) ExecAll(cmds []string) error {
 	return nil
 }
 
+// Drop marks the host as failed. Dropped hosts are left out of the rest of the run.
+func (h *Host) Drop() {
+	h.dropped = true
+}
+
+// Dropped returns true when the host has been dropped from the run after a failure.
+func (h *Host) Dropped() bool {
+	return h.dropped
+}
+
+// SetContext sets the context for the commands run on the host. Commands that are
+// running when the context is cancelled are interrupted by closing the connection.
+func (h *Host) SetContext(ctx context.Context) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ctx = ctx
+}
+
+func (h *Host) context() context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.ctx
+}
+
+// traceAction runs fn for the host inside a span. The span is a sibling of the spans
+// of the commands fn runs, which have the host as an attribute: the host is shared by
+// the goroutines of the other hosts, so its context is never replaced with the span.
+func (h *Host) traceAction(fn func(h *Host) error) error {
+	ctx := h.context()
+	if ctx == nil {
+		return fn(h)
+	}
+	_, span := tracing.Start(ctx, "host", attribute.String("host", h.Address()), attribute.String("role", h.Role))
+	err := fn(h)
+	tracing.End(span, err)
+	return err
+}
+
+type execResult struct {
+	output string
+	err    error
+}
+
+// prepareExec returns the context of the host after checking that it hasn't been
+// cancelled, and reconnects when a command was interrupted by closing the connection.
+func (h *Host) prepareExec() (context.Context, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ctx == nil {
+		return nil, nil
+	}
+	if err := h.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: command not started: %w", h, context.Cause(h.ctx))
+	}
+
+	if h.interrupted {
+		// the connection was closed to interrupt a command, reconnect for the cleanup
+		log.Debugf("%s: reconnecting after an interrupted command", h)
+		if err := h.Connect(); err != nil {
+			return nil, fmt.Errorf("%s: reconnect after interrupt: %w", h, err)
+		}
+		h.interrupted = false
+	}
+	return h.ctx, nil
+}
+
+// interrupt closes the connection to interrupt the running commands, the next command
+// reconnects.
+func (h *Host) interrupt() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.interrupted {
+		return
+	}
+	log.Warnf("%s: interrupting the running command", h)
+	h.interrupted = true
+	h.Disconnect()
+}
+
+// withContext runs fn, which executes a remote command, bound to the host's context.
+func (h *Host) withContext(fn func() (string, error)) (string, error) {
+	ctx, err := h.prepareExec()
+	if err != nil {
+		return "", err
+	}
+	if ctx == nil || ctx.Done() == nil {
+		return fn()
+	}
+
+	done := make(chan execResult, 1)
+	go func() {
+		output, err := fn()
+		done <- execResult{output: output, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.output, res.err
+	case <-ctx.Done():
+		h.interrupt()
+		// closing an SSH connection ends the command right away, a WinRM command can't
+		// be interrupted and is waited for, so that it never runs on behind the cleanup
+		<-done
+		return "", fmt.Errorf("%s: command interrupted: %w", h, context.Cause(ctx))
+	}
+}
+
+// traceCommand runs fn bound to the host's context and records the command as a span.
+func (h *Host) traceCommand(cmd string, opts []exec.Option, fn func() (string, error)) (string, error) {
+	ctx := h.context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := tracing.Start(ctx, "exec",
+		attribute.String("host", h.Address()),
+		attribute.String("command", commandAttribute(cmd, opts)),
+	)
+	output, err := h.withContext(fn)
+	tracing.End(span, err)
+	return output, err
+}
+
+// commandAttribute returns the command with any sensitive parts redacted, or a
+// placeholder when the command is not allowed to be logged at all.
+func commandAttribute(cmd string, opts []exec.Option) string {
+	o := exec.Build(opts...)
+	if !o.LogCommand {
+		return "[hidden]"
+	}
+	return mcclog.Redact(o.Redact(cmd))
+}
+
 // ExecStreams executes a command on the remote host and uses the passed in streams for stdin, stdout and stderr. It returns a Waiter with a .Wait() function that
-// blocks until the command finishes and returns an error if the exit code is not zero.
+// blocks until the command finishes and returns an error if the exit code is not zero. The command is interrupted when the host's context is cancelled.
 func (h *Host) ExecStreams(cmd string, stdin io.ReadCloser, stdout, stderr io.Writer, opts ...exec.Option) (exec.Waiter, error) { //nolint:ireturn
-	return h.Connection.ExecStreams(cmd, stdin, stdout, stderr, h.sudoCommandOptions(cmd, opts)...) //nolint:wrapcheck
+	ctx, err := h.prepareExec()
+	if err != nil {
+		return nil, err
+	}
+	waiter, err := h.Connection.ExecStreams(cmd, stdin, stdout, stderr, h.sudoCommandOptions(cmd, opts)...)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if ctx == nil || ctx.Done() == nil {
+		return waiter, nil
+	}
+	return &contextWaiter{host: h, ctx: ctx, waiter: waiter}, nil
+}
+
+// contextWaiter interrupts a command started with ExecStreams when the context of the
+// host is cancelled.
+type contextWaiter struct {
+	host   *Host
+	ctx    context.Context
+	waiter exec.Waiter
+}
+
+// Wait blocks until the command finishes or is interrupted.
+func (w *contextWaiter) Wait() error {
+	done := make(chan error, 1)
+	go func() {
+		done <- w.waiter.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err //nolint:wrapcheck
+	case <-w.ctx.Done():
+		w.host.interrupt()
+		<-done
+		return fmt.Errorf("%s: command interrupted: %w", w.host, context.Cause(w.ctx))
+	}
 }
 
 // Exec runs a command on the host.
 func (h *Host) Exec(cmd string, opts ...exec.Option) error {
-	return h.Connection.Exec(cmd, h.sudoCommandOptions(cmd, opts)...) //nolint:wrapcheck
+	_, err := h.traceCommand(cmd, opts, func() (string, error) {
+		return "", h.Connection.Exec(cmd, h.sudoCommandOptions(cmd, opts)...) //nolint:wrapcheck
+	})
+	return err
 }
 
 // ExecOutput runs a command on the host and returns the output as a String.
 func (h *Host) ExecOutput(cmd string, opts ...exec.Option) (string, error) {
-	return h.Connection.ExecOutput(cmd, h.sudoCommandOptions(cmd, opts)...) //nolint:wrapcheck
+	return h.traceCommand(cmd, opts, func() (string, error) {
+		return h.Connection.ExecOutput(cmd, h.sudoCommandOptions(cmd, opts)...) //nolint:wrapcheck
+	})
 }
 
 var errAuthFailed = errors.New("authentication failed")
@@ -311,6 +516,12 @@ func (h *Host) Reboot() error {
 		return fmt.Errorf("unable to reconnect after reboot: %w", err)
 	}
 
+	if c, ok := h.Configurer.(rebootCleaner); ok {
+		if err := c.CleanUpReboot(h); err != nil {
+			log.Warnf("%s: failed to clean up after reboot: %s", h, err)
+		}
+	}
+
 	return nil
 }
 
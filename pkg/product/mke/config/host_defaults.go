@@ -0,0 +1,282 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/k0sproject/dig"
+	"gopkg.in/yaml.v2"
+)
+
+// HostDefaults are settings shared by the hosts, in the format of the spec.hosts
+// entries. The settings under all apply to every host and the ones under a role to the
+// hosts of that role. The settings of a host override the role defaults, which
+// override the ones under all.
+type HostDefaults map[string]dig.Mapping
+
+// hostDefaultsAll is the HostDefaults key of the settings for all the roles.
+const hostDefaultsAll = "all"
+
+// connectionKeys are the keys of the host connection types, a host has only one of them.
+var connectionKeys = []string{"ssh", "winRM", "openSSH", "localhost"}
+
+var (
+	// addressRangeRe matches a numeric range in a host address, such as [10:59] in
+	// 10.0.1.[10:59] or [01:40] in worker-[01:40].example.com.
+	addressRangeRe = regexp.MustCompile(`\[(\d+):(\d+)\]`)
+
+	errInvalidAddressRange = errors.New("invalid address range")
+)
+
+// expandHosts expands the address ranges of the spec.hosts entries to one entry per
+// address and merges the host defaults into each entry. It returns false when there
+// are no defaults or ranges and the hosts can be used as is.
+func expandHosts(hosts []interface{}, defaults map[interface{}]interface{}) ([]interface{}, bool, error) {
+	hasRanges := false
+	for _, h := range hosts {
+		if len(hostAddressRanges(h)) > 0 {
+			hasRanges = true
+			break
+		}
+	}
+	if len(defaults) == 0 && !hasRanges {
+		return hosts, false, nil
+	}
+
+	for k := range defaults {
+		switch fmt.Sprint(k) {
+		case hostDefaultsAll, "manager", "worker", "msr":
+		default:
+			return nil, false, fmt.Errorf("%w: spec.hostDefaults.%v: must be one of all, manager, worker or msr", errInvalidConfig, k)
+		}
+	}
+
+	var result []interface{}
+	for i, h := range hosts {
+		expanded, err := expandHostRanges(h)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: spec.hosts[%d]: %w", errInvalidConfig, i, err)
+		}
+		for _, host := range expanded {
+			result = append(result, withHostDefaults(host, defaults))
+		}
+	}
+	return result, true, nil
+}
+
+// withHostDefaults merges the host on top of the defaults for its role.
+func withHostDefaults(host interface{}, defaults map[interface{}]interface{}) interface{} {
+	h, ok := host.(map[interface{}]interface{})
+	if !ok || len(defaults) == 0 {
+		return host
+	}
+
+	base := withoutOtherConnections(defaults[hostDefaultsAll], h)
+	role, ok := h["role"].(string)
+	if !ok {
+		if all, ok := base.(map[interface{}]interface{}); ok {
+			role, _ = all["role"].(string)
+		}
+	}
+	if roleDefaults, ok := defaults[role]; ok {
+		base = mergeDefaults(base, withoutOtherConnections(roleDefaults, h))
+	}
+	return mergeDefaults(base, h)
+}
+
+// withoutOtherConnections leaves out the connection settings of the defaults that are
+// of a different type than the connection of the host.
+func withoutOtherConnections(defaults interface{}, host map[interface{}]interface{}) interface{} {
+	d, ok := defaults.(map[interface{}]interface{})
+	if !ok {
+		return defaults
+	}
+	hostConnection := ""
+	for _, key := range connectionKeys {
+		if _, ok := host[key]; ok {
+			hostConnection = key
+			break
+		}
+	}
+	if hostConnection == "" {
+		return d
+	}
+	result := make(map[interface{}]interface{}, len(d))
+	for k, v := range d {
+		key := fmt.Sprint(k)
+		if key != hostConnection && isConnectionKey(key) {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func isConnectionKey(key string) bool {
+	for _, k := range connectionKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDefaults returns a copy of the base with the overlay merged on top of it.
+// Mappings are merged key by key, other values are replaced.
+func mergeDefaults(base, overlay interface{}) interface{} {
+	o, ok := overlay.(map[interface{}]interface{})
+	if !ok {
+		if overlay == nil {
+			return copyValue(base)
+		}
+		return copyValue(overlay)
+	}
+	b, ok := base.(map[interface{}]interface{})
+	if !ok {
+		return copyValue(overlay)
+	}
+	result := make(map[interface{}]interface{}, len(b)+len(o))
+	for k, v := range b {
+		result[k] = copyValue(v)
+	}
+	for k, v := range o {
+		result[k] = mergeDefaults(result[k], v)
+	}
+	return result
+}
+
+// copyValue copies the mappings and lists of a value, so that the hosts don't share them.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			result[k] = copyValue(val)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, val := range v {
+			result[i] = copyValue(val)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// hostAddressRanges returns the connection types of the host whose address has a range.
+func hostAddressRanges(host interface{}) []string {
+	h, ok := host.(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, key := range connectionKeys {
+		conn, ok := h[key].(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		if address, ok := conn["address"].(string); ok && addressRangeRe.MatchString(address) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// expandHostRanges returns a copy of the host for each address in the range of its
+// address, or the host itself when its address has no range.
+func expandHostRanges(host interface{}) ([]interface{}, error) {
+	keys := hostAddressRanges(host)
+	if len(keys) == 0 {
+		return []interface{}{host}, nil
+	}
+	if len(keys) > 1 {
+		return nil, fmt.Errorf("%w: ranges in more than one connection (%s)", errInvalidAddressRange, strings.Join(keys, ", "))
+	}
+	key := keys[0]
+
+	h, _ := host.(map[interface{}]interface{})
+	conn, _ := h[key].(map[interface{}]interface{})
+	pattern, _ := conn["address"].(string)
+	addresses, err := expandAddress(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0, len(addresses))
+	for _, address := range addresses {
+		c, _ := copyValue(h).(map[interface{}]interface{})
+		c[key].(map[interface{}]interface{})["address"] = address
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// maxAddressRange is the largest number of addresses an address can expand to, with all
+// its ranges combined.
+const maxAddressRange = 10000
+
+// expandAddress expands the numeric ranges in an address. When the start of a range has
+// leading zeros, the numbers are padded to the same width.
+func expandAddress(pattern string) ([]string, error) {
+	loc := addressRangeRe.FindStringSubmatchIndex(pattern)
+	if loc == nil {
+		return []string{pattern}, nil
+	}
+
+	startText, endText := pattern[loc[2]:loc[3]], pattern[loc[4]:loc[5]]
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", errInvalidAddressRange, pattern, err)
+	}
+	end, err := strconv.Atoi(endText)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", errInvalidAddressRange, pattern, err)
+	}
+	if end < start {
+		return nil, fmt.Errorf("%w %s: the end is before the start", errInvalidAddressRange, pattern)
+	}
+	if end-start >= maxAddressRange {
+		return nil, fmt.Errorf("%w %s: more than %d addresses", errInvalidAddressRange, pattern, maxAddressRange)
+	}
+
+	width := 0
+	if len(startText) > 1 && startText[0] == '0' {
+		width = len(startText)
+	}
+
+	prefix, rest := pattern[:loc[0]], pattern[loc[1]:]
+	suffixes, err := expandAddress(rest)
+	if err != nil {
+		return nil, err
+	}
+	// both counts are at most maxAddressRange, the product can't overflow
+	if (end-start+1)*len(suffixes) > maxAddressRange {
+		return nil, fmt.Errorf("%w %s: more than %d addresses", errInvalidAddressRange, pattern, maxAddressRange)
+	}
+
+	addresses := make([]string, 0, (end-start+1)*len(suffixes))
+	for n := start; n <= end; n++ {
+		for _, suffix := range suffixes {
+			addresses = append(addresses, fmt.Sprintf("%s%0*d%s", prefix, width, n, suffix))
+		}
+	}
+	return addresses, nil
+}
+
+// unmarshalExpandedHosts decodes the hosts after expandHosts.
+func unmarshalExpandedHosts(hosts []interface{}) (Hosts, error) {
+	data, err := yaml.Marshal(hosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hosts: %w", err)
+	}
+	var result Hosts
+	if err := yaml.UnmarshalStrict(data, &result); err != nil {
+		return nil, fmt.Errorf("spec.hosts: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostDefaults(t *testing.T) {
+	c := loadYaml(t, `
+apiVersion: "launchpad.mirantis.com/mke/v1.6"
+kind: mke
+spec:
+  mcr:
+    channel: stable
+  hostDefaults:
+    all:
+      ssh:
+        user: ubuntu
+        keyPath: /keys/cluster
+      environment:
+        HTTP_PROXY: http://proxy.example.com:3128
+      mcrConfig:
+        debug: true
+    worker:
+      mcrConfig:
+        log-level: warn
+      environment:
+        NO_PROXY: 10.0.0.0/8
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+        user: root
+    - role: worker
+      ssh:
+        address: 10.0.0.2
+      mcrConfig:
+        debug: false
+    - role: worker
+      winRM:
+        address: 10.0.0.3
+`)
+
+	require.Len(t, c.Spec.Hosts, 3)
+
+	m := c.Spec.Hosts[0]
+	require.Equal(t, "root", m.SSH.User)
+	require.Equal(t, "/keys/cluster", *m.SSH.KeyPath)
+	require.Equal(t, map[string]string{"HTTP_PROXY": "http://proxy.example.com:3128"}, m.Environment)
+	require.Equal(t, true, m.DaemonConfig["debug"])
+	require.NotContains(t, m.DaemonConfig, "log-level")
+
+	w := c.Spec.Hosts[1]
+	require.Equal(t, "ubuntu", w.SSH.User)
+	require.Equal(t, false, w.DaemonConfig["debug"])
+	require.Equal(t, "warn", w.DaemonConfig["log-level"])
+	require.Equal(t, map[string]string{"HTTP_PROXY": "http://proxy.example.com:3128", "NO_PROXY": "10.0.0.0/8"}, w.Environment)
+
+	// the ssh defaults don't apply to a winRM host
+	win := c.Spec.Hosts[2]
+	require.Nil(t, win.SSH)
+	require.Equal(t, "10.0.0.3", win.WinRM.Address)
+	require.Equal(t, "warn", win.DaemonConfig["log-level"])
+
+	// the hosts don't share the default mappings
+	w.Environment["FOO"] = "bar"
+	require.NotContains(t, win.Environment, "FOO")
+}
+
+func TestHostAddressRanges(t *testing.T) {
+	c := loadYaml(t, `
+apiVersion: "launchpad.mirantis.com/mke/v1.6"
+kind: mke
+spec:
+  mcr:
+    channel: stable
+  hostDefaults:
+    all:
+      ssh:
+        user: ubuntu
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.1.[10:12]
+    - role: worker
+      ssh:
+        address: worker-[08:10].example.com
+        port: 2222
+`)
+
+	addresses := make([]string, 0, len(c.Spec.Hosts))
+	for _, h := range c.Spec.Hosts {
+		addresses = append(addresses, h.Address())
+		require.Equal(t, "ubuntu", h.SSH.User)
+	}
+	require.Equal(t, []string{
+		"10.0.1.10", "10.0.1.11", "10.0.1.12",
+		"worker-08.example.com", "worker-09.example.com", "worker-10.example.com",
+	}, addresses)
+	require.Len(t, c.Spec.Managers(), 3)
+	require.Equal(t, 2222, c.Spec.Hosts[5].SSH.Port)
+}
+
+func TestExpandAddress(t *testing.T) {
+	addresses, err := expandAddress("rack[1:2]-node[01:02]")
+	require.NoError(t, err)
+	require.Equal(t, []string{"rack1-node01", "rack1-node02", "rack2-node01", "rack2-node02"}, addresses)
+
+	_, err = expandAddress("10.0.0.[20:10]")
+	require.ErrorIs(t, err, errInvalidAddressRange)
+
+	_, err = expandAddress("10.[0:10000].0.1")
+	require.ErrorIs(t, err, errInvalidAddressRange)
+
+	// the limit applies to all the ranges of the address combined
+	_, err = expandAddress("10.[1:200].[1:200].[1:200]")
+	require.ErrorContains(t, err, "more than 10000 addresses")
+}
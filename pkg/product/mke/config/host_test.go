@@ -1,9 +1,11 @@
 package config
 
 import (
+	"context"
 	"testing"
 
 	"github.com/k0sproject/rig"
+	"github.com/k0sproject/rig/exec"
 	"github.com/stretchr/testify/require"
 )
 
@@ -74,3 +76,47 @@ func TestHostAddress(t *testing.T) {
 
 	require.Equal(t, "1.2.3.4", h.Address())
 }
+
+func TestHostExecCancelled(t *testing.T) {
+	h := &Host{
+		Connection: rig.Connection{
+			SSH: &rig.SSH{
+				Address: "1.2.3.4",
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h.SetContext(ctx)
+
+	require.ErrorIs(t, h.Exec("true"), context.Canceled)
+	_, err := h.ExecOutput("true")
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = h.ExecStreams("true", nil, nil, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestHostTraceActionConcurrent(t *testing.T) {
+	leader := &Host{Connection: rig.Connection{SSH: &rig.SSH{Address: "1.2.3.4"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	leader.SetContext(ctx)
+
+	// the actions of the other hosts run commands on the leader while its own runs
+	hosts := Hosts{leader, {Connection: rig.Connection{SSH: &rig.SSH{Address: "1.2.3.5"}}}, {Connection: rig.Connection{SSH: &rig.SSH{Address: "1.2.3.6"}}}}
+	for _, h := range hosts[1:] {
+		h.SetContext(ctx)
+	}
+	err := hosts.ParallelEach(func(_ *Host) error {
+		return leader.Exec("true")
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Same(t, ctx, leader.context())
+}
+
+func TestCommandAttribute(t *testing.T) {
+	require.Equal(t, "docker login -p [REDACTED]", commandAttribute("docker login -p secret", []exec.Option{exec.RedactString("secret")}))
+	require.Equal(t, "[hidden]", commandAttribute("docker login -p secret", []exec.Option{exec.HideCommand()}))
+	require.Equal(t, "uname", commandAttribute("uname", nil))
+}
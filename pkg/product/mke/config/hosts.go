@@ -96,7 +96,7 @@ func (hosts *Hosts) ParallelEach(filter func(h *Host) error) error {
 		wg.Add(1)
 		go func(h *Host) {
 			defer wg.Done()
-			if err := filter(h); err != nil {
+			if err := h.traceAction(filter); err != nil {
 				mu.Lock()
 				result = errors.Join(result, fmt.Errorf("%s: %w", h, err))
 				mu.Unlock()
@@ -0,0 +1,368 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// schemaJSON is the published JSON Schema of the configuration. TestSchema fails when it
+// doesn't match the one generated from the structs, run the test with -update to
+// regenerate it.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+// SchemaJSON returns the JSON Schema of the configuration for the current apiVersion.
+func SchemaJSON() []byte {
+	return schemaJSON
+}
+
+// GenerateSchema generates the JSON Schema of the configuration from the ClusterConfig
+// struct. The yaml tags give the property names, the validate tags the constraints and
+// the default tags the defaults.
+func GenerateSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]any)}
+	root := g.structSchema(reflect.TypeOf(ClusterConfig{}))
+
+	apiVersion := ""
+	if field, ok := reflect.TypeOf(ClusterConfig{}).FieldByName("APIVersion"); ok {
+		_, apiVersion, _ = strings.Cut(field.Tag.Get("validate"), "eq=")
+	}
+
+	// extends is handled when the files are loaded, see pkg/config/merge.go
+	if properties, ok := root["properties"].(map[string]any); ok {
+		properties["extends"] = map[string]any{
+			"description": "Configuration files this file is layered on, relative to this file",
+			"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+		}
+	}
+
+	// the hosts can come from spec.inventory, see pkg/config/inventory.go
+	if spec, ok := g.defs["ClusterSpec"].(map[string]any); ok {
+		if required, ok := spec["required"].([]string); ok {
+			kept := make([]string, 0, len(required))
+			for _, r := range required {
+				if r != "hosts" {
+					kept = append(kept, r)
+				}
+			}
+			if len(kept) > 0 {
+				spec["required"] = kept
+			} else {
+				delete(spec, "required")
+			}
+		}
+		spec["anyOf"] = []any{
+			map[string]any{"required": []string{"hosts"}},
+			map[string]any{"required": []string{"inventory"}},
+		}
+	}
+
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["title"] = fmt.Sprintf("Launchpad cluster configuration (%s)", apiVersion)
+	root["$defs"] = g.defs
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+// typeSchema returns the schema of a type, with the validations of the field it belongs to.
+func (g *schemaGenerator) typeSchema(t reflect.Type, rules []string) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// validations after dive apply to the elements, the ones between keys and endkeys
+	// to the map keys
+	own, elem, keys := splitDive(rules)
+
+	schema := map[string]any{}
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = nil // placeholder for recursive types
+			g.defs[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema["type"] = "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+		schema["minimum"] = 0
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		schema["type"] = "array"
+		schema["items"] = g.typeSchema(t.Elem(), elem)
+	case reflect.Map:
+		schema["type"] = "object"
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = g.typeSchema(t.Elem(), elem)
+		}
+		if len(keys) > 0 {
+			names := map[string]any{"type": "string"}
+			applyRules(names, reflect.String, keys)
+			schema["propertyNames"] = names
+		}
+	}
+
+	applyRules(schema, t.Kind(), own)
+
+	// string values can also be given as secret references, see pkg/config/secret.go
+	if t.Kind() == reflect.String && schema["enum"] == nil && schema["const"] == nil {
+		g.defs["SecretReference"] = secretReferenceSchema()
+		return map[string]any{"anyOf": []any{schema, map[string]any{"$ref": "#/$defs/SecretReference"}}}
+	}
+	return schema
+}
+
+func secretReferenceSchema() map[string]any {
+	return map[string]any{
+		"description": "A value read from a file, an environment variable or the output of a command when the configuration is loaded",
+		"type":        "object",
+		"oneOf": []any{
+			map[string]any{"required": []string{"fromFile"}},
+			map[string]any{"required": []string{"fromEnv"}},
+			map[string]any{"required": []string{"fromCommand"}},
+		},
+		"properties": map[string]any{
+			"fromFile":    map[string]any{"type": "string", "description": "Path of a file that contains the value"},
+			"fromEnv":     map[string]any{"type": "string", "description": "Name of an environment variable that contains the value"},
+			"fromCommand": map[string]any{"type": "string", "description": "Command that prints the value, for example pass show mke/admin"},
+		},
+		"additionalProperties": false,
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	g.addProperties(t, properties, &required)
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addProperties adds the fields of a struct to the properties, descending into the
+// inlined structs.
+func (g *schemaGenerator) addProperties(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			g.addProperties(ft, properties, required)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		var rules []string
+		if v := field.Tag.Get("validate"); v != "" {
+			rules = strings.Split(v, ",")
+		}
+
+		schema := g.typeSchema(field.Type, rules)
+		if description := tagOption(field.Tag.Get("jsonschema"), "description"); description != "" {
+			schema = withKeyword(schema, "description", description)
+		}
+		def, hasDefault := field.Tag.Lookup("default")
+		if hasDefault {
+			if value, ok := defaultValue(field.Type, def); ok {
+				schema = withKeyword(schema, "default", value)
+			}
+		}
+		properties[name] = schema
+
+		if !hasDefault && isRequired(rules) {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// withKeyword adds a keyword to a schema. A $ref can't have siblings in all the
+// editors, so it is wrapped in allOf.
+func withKeyword(schema map[string]any, key string, value any) map[string]any {
+	if ref, ok := schema["$ref"]; ok {
+		schema = map[string]any{"allOf": []any{map[string]any{"$ref": ref}}}
+	}
+	schema[key] = value
+	return schema
+}
+
+// splitDive splits the validate rules of a field to the rules of the field itself, its
+// elements and its map keys.
+func splitDive(rules []string) (own, elem, keys []string) {
+	for i, rule := range rules {
+		if rule != "dive" {
+			continue
+		}
+		own = rules[:i]
+		elem = rules[i+1:]
+		if len(elem) > 0 && elem[0] == "keys" {
+			for j, r := range elem {
+				if r == "endkeys" {
+					keys = elem[1:j]
+					elem = elem[j+1:]
+					break
+				}
+			}
+		}
+		return own, elem, keys
+	}
+	return rules, nil, nil
+}
+
+// isRequired returns true when the rules don't allow the field to be left out.
+func isRequired(rules []string) bool {
+	own, _, _ := splitDive(rules)
+	for _, rule := range own {
+		if rule == "omitempty" {
+			return false
+		}
+	}
+	for _, rule := range own {
+		name, _, _ := strings.Cut(rule, "=")
+		if name == "required" || name == "oneof" || name == "eq" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules converts the validate rules to schema keywords. Rules that have no
+// equivalent, such as file or hostname_rfc1123|ip, are left out.
+func applyRules(schema map[string]any, kind reflect.Kind, rules []string) {
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "oneof":
+			values := make([]any, 0)
+			for _, v := range strings.Fields(param) {
+				values = append(values, ruleValue(kind, v))
+			}
+			schema["enum"] = values
+		case "eq":
+			schema["const"] = ruleValue(kind, param)
+		case "min", "gte", "gt", "max", "lte", "lt":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyBound(schema, kind, name, n)
+		}
+	}
+}
+
+func applyBound(schema map[string]any, kind reflect.Kind, rule string, n int) {
+	lower := rule == "min" || rule == "gte" || rule == "gt"
+	if rule == "gt" {
+		n++
+	}
+	if rule == "lt" {
+		n--
+	}
+
+	var keyword string
+	switch kind { //nolint:exhaustive
+	case reflect.String:
+		keyword = map[bool]string{true: "minLength", false: "maxLength"}[lower]
+	case reflect.Slice, reflect.Array:
+		keyword = map[bool]string{true: "minItems", false: "maxItems"}[lower]
+	case reflect.Map:
+		keyword = map[bool]string{true: "minProperties", false: "maxProperties"}[lower]
+	default:
+		keyword = map[bool]string{true: "minimum", false: "maximum"}[lower]
+	}
+	schema[keyword] = n
+}
+
+// ruleValue converts a value in a validate rule to the type of the field.
+func ruleValue(kind reflect.Kind, value string) any {
+	switch kind { //nolint:exhaustive
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// defaultValue converts a default tag to the type of the field.
+func defaultValue(t reflect.Type, value string) (any, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return value, true
+	case reflect.Map, reflect.Slice, reflect.Struct:
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, false
+		}
+		return v, true
+	default:
+		v := ruleValue(t.Kind(), value)
+		if _, ok := v.(string); ok {
+			return nil, false
+		}
+		return v, true
+	}
+}
+
+// tagOption returns the value of a key=value option of a struct tag. The description
+// option is the last one, so its value runs to the end of the tag.
+func tagOption(tag, key string) string {
+	_, value, found := strings.Cut(tag, key+"=")
+	if !found {
+		return ""
+	}
+	if key != "description" {
+		value, _, _ = strings.Cut(value, ",")
+	}
+	return value
+}
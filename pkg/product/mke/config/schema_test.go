@@ -0,0 +1,165 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+var updateSchema = flag.Bool("update", false, "regenerate schema.json")
+
+func TestSchema(t *testing.T) {
+	generated, err := GenerateSchema()
+	require.NoError(t, err)
+
+	if *updateSchema {
+		require.NoError(t, os.WriteFile("schema.json", generated, 0o644))
+		return
+	}
+
+	require.True(t, bytes.Equal(generated, SchemaJSON()), "schema.json is out of date, run: go test ./pkg/product/mke/config -run TestSchema -update")
+}
+
+func compileSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(SchemaJSON()))
+	require.NoError(t, err)
+	c := jsonschema.NewCompiler()
+	require.NoError(t, c.AddResource("schema.json", doc))
+	schema, err := c.Compile("schema.json")
+	require.NoError(t, err)
+	return schema
+}
+
+// yamlToJSON converts a YAML document to the generic JSON types the validator expects.
+func yamlToJSON(t *testing.T, data string) any {
+	t.Helper()
+	var v any
+	require.NoError(t, yaml.Unmarshal([]byte(data), &v))
+	v = convertYAML(v)
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	require.NoError(t, err)
+	return doc
+}
+
+func convertYAML(v any) any {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[k.(string)] = convertYAML(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = convertYAML(val)
+		}
+	}
+	return v
+}
+
+func TestSchemaValidatesConfig(t *testing.T) {
+	schema := compileSchema(t)
+
+	valid := `
+apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+metadata:
+  name: test
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+        keyPath: ~/.ssh/id_rsa
+        bastion:
+          address: 10.0.0.100
+      hooks:
+        apply:
+          before:
+            - ls -al
+    - role: worker
+      winRM:
+        address: 10.0.0.2
+        password: foo
+  mke:
+    version: 3.7.0
+    adminPassword:
+      fromCommand: pass show mke/admin
+    installFlags:
+      - --admin-username=admin
+  mcr:
+    channel: stable
+`
+	require.NoError(t, schema.Validate(yamlToJSON(t, valid)))
+
+	invalid := map[string]string{
+		"unknown field": `
+apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+      foo: bar
+`,
+		"invalid role": `
+apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - role: banana
+      ssh:
+        address: 10.0.0.1
+`,
+		"invalid hook": `
+apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+      hooks:
+        apply:
+          during:
+            - ls
+`,
+		"wrong apiVersion": `
+apiVersion: launchpad.mirantis.com/v1
+kind: mke
+`,
+		"invalid secret reference": `
+apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: 10.0.0.1
+  mke:
+    adminPassword:
+      fromVault: mke/admin
+`,
+		"no hosts": `
+apiVersion: launchpad.mirantis.com/mke/v1.6
+kind: mke
+spec:
+  hosts: []
+`,
+	}
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, schema.Validate(yamlToJSON(t, data)))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package mke
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -43,7 +44,7 @@ func (p *MKE) Describe(reportName string) error {
 		&de.Describe{MKE: mke, MSR: msr},
 	)
 
-	if err := phaseManager.Run(); err != nil {
+	if err := phaseManager.Run(context.Background()); err != nil {
 		return fmt.Errorf("failed to describe cluster: %w", err)
 	}
 	return nil
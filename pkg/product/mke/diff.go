@@ -0,0 +1,32 @@
+package mke
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	common "github.com/Mirantis/launchpad/pkg/product/common/phase"
+	mke "github.com/Mirantis/launchpad/pkg/product/mke/phase"
+)
+
+// Diff gathers the facts of the cluster and returns the differences to the configuration
+// without changing anything.
+func (p *MKE) Diff(ctx context.Context) (*phase.DriftReport, error) {
+	report := &phase.DriftReport{}
+
+	phaseManager := phase.NewManager(&p.ClusterConfig)
+	phaseManager.AddPhases(
+		&mke.OverrideHostSudo{},
+		&common.Connect{},
+		&mke.DetectOS{},
+		&mke.GatherFacts{},
+		&mke.DetectDrift{Report: report},
+		&common.Disconnect{},
+	)
+
+	if err := phaseManager.Run(ctx); err != nil {
+		return nil, fmt.Errorf("failed to diff MKE: %w", err)
+	}
+
+	return report, nil
+}
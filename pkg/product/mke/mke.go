@@ -1,9 +1,11 @@
 package mke
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/product/mke/phase"
 	"gopkg.in/yaml.v2"
 )
 
@@ -34,3 +36,16 @@ func NewMKE(data []byte) (*MKE, error) {
 func Init(kind string) *config.ClusterConfig {
 	return config.Init(kind)
 }
+
+// ValidateConfig parses the configuration and runs the validations that don't need a
+// connection to the hosts.
+func ValidateConfig(data []byte) error {
+	c := config.ClusterConfig{}
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return fmt.Errorf("failed to parse cluster config: %w", err)
+	}
+
+	errs := c.ValidationErrors()
+	errs = append(errs, phase.ValidateConfig(&c)...)
+	return errors.Join(errs...)
+}
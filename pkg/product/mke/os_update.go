@@ -0,0 +1,37 @@
+package mke
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	"github.com/Mirantis/launchpad/pkg/product"
+	common "github.com/Mirantis/launchpad/pkg/product/common/phase"
+	mke "github.com/Mirantis/launchpad/pkg/product/mke/phase"
+)
+
+// OSUpdate upgrades the OS packages of the hosts one manager at a time and the workers
+// in batches, draining their swarm nodes and rebooting the hosts that need it. The
+// report is returned also when the update fails, with the hosts updated until then.
+func (p *MKE) OSUpdate(ctx context.Context, opts product.OSUpdateOptions) (*phase.OSUpdateReport, error) {
+	if err := p.checkHostSelection(opts.Roles, opts.Hosts); err != nil {
+		return nil, err
+	}
+
+	report := &phase.OSUpdateReport{}
+
+	phaseManager := phase.NewManager(&p.ClusterConfig)
+	phaseManager.AddPhases(
+		&mke.OverrideHostSudo{},
+		&common.Connect{},
+		&mke.DetectOS{},
+		&mke.GatherFacts{},
+		&mke.UpdateOS{Roles: opts.Roles, Addresses: opts.Hosts, Concurrency: opts.Concurrency, IncludeMCR: opts.IncludeMCR, Report: report},
+		&common.Disconnect{},
+	)
+
+	if err := phaseManager.Run(ctx); err != nil {
+		return report, fmt.Errorf("failed to update OS packages: %w", err)
+	}
+	return report, nil
+}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -73,10 +74,15 @@ func (p *AuthenticateDocker) Title() string {
 	return "Authenticate docker"
 }
 
+// ResourceScopes returns all of the hosts as docker is authenticated on each of them.
+func (p *AuthenticateDocker) ResourceScopes() []string {
+	return phase.HostScopes(p.Config.Spec.Hosts)
+}
+
 // Run authenticates docker on hosts.
-func (p *AuthenticateDocker) Run() error {
+func (p *AuthenticateDocker) Run(ctx context.Context) error {
 	// now run logins to each required registry on each of the hosts.
-	if err := phase.RunParallelOnHosts(p.Config.Spec.Hosts, p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
+	if err := phase.RunParallelOnHosts(ctx, p.Config.Spec.Hosts, p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
 		errs := []error{}
 		for repo, lc := range p.logins { // running sequentially shouldn't be a problem for performance.
 			log.Infof("%s: authenticating docker for image repo %s", h, repo)
@@ -0,0 +1,207 @@
+package phase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/retry"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	retrygo "github.com/avast/retry-go"
+	log "github.com/sirupsen/logrus"
+)
+
+var errUnsafeRoleChange = errors.New("unsafe role change")
+
+// ChangeRoles phase promotes and demotes the swarm nodes of the hosts whose role in the
+// configuration differs from their role in the swarm. MKE deploys and removes its
+// manager components when the swarm role of a node changes, the phase waits for them.
+type ChangeRoles struct {
+	phase.Analytics
+	phase.BasicPhase
+
+	promote []*mkeconfig.Host
+	demote  []*mkeconfig.Host
+}
+
+// Title for the phase.
+func (p *ChangeRoles) Title() string {
+	return "Change node roles"
+}
+
+// Prepare collects the hosts to promote and demote.
+func (p *ChangeRoles) Prepare(config interface{}) error {
+	if err := p.BasicPhase.Prepare(config); err != nil {
+		return err
+	}
+	p.promote, p.demote = roleChanges(p.Config.Spec.Hosts)
+	return nil
+}
+
+// ShouldRun is true when the role of a host has changed.
+func (p *ChangeRoles) ShouldRun() bool {
+	return len(p.promote)+len(p.demote) > 0
+}
+
+// PlannedActions lists the hosts to promote and demote.
+func (p *ChangeRoles) PlannedActions() []phase.PlannedAction {
+	actions := make([]phase.PlannedAction, 0, len(p.promote)+len(p.demote))
+	for _, h := range p.promote {
+		actions = append(actions, phase.PlannedAction{Host: h.String(), Action: "promote to swarm manager"})
+	}
+	for _, h := range p.demote {
+		actions = append(actions, phase.PlannedAction{Host: h.String(), Action: "demote to swarm worker"})
+	}
+	return actions
+}
+
+// Run promotes the new managers first and then demotes the former managers one at a
+// time, so that the quorum is kept throughout.
+func (p *ChangeRoles) Run(ctx context.Context) error {
+	swarmLeader := p.Config.Spec.SwarmLeader()
+
+	for _, h := range p.promote {
+		if err := p.changeRole(ctx, swarmLeader, h, "promote"); err != nil {
+			return err
+		}
+		h.Metadata.SwarmRole = "manager"
+		if err := p.waitMKE(mkeconfig.Hosts{h}); err != nil {
+			return err
+		}
+	}
+
+	for _, h := range p.demote {
+		if err := p.changeRole(ctx, swarmLeader, h, "demote"); err != nil {
+			return err
+		}
+		h.Metadata.SwarmRole = "worker"
+		if err := removeSANsLabel(swarmLeader, h); err != nil {
+			return err
+		}
+		if err := p.waitMKE(p.Config.Spec.Managers()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeRole runs docker node promote or demote for the host and waits until the swarm
+// managers are healthy again.
+func (p *ChangeRoles) changeRole(ctx context.Context, swarmLeader, h *mkeconfig.Host, action string) error {
+	nodeID, err := swarm.NodeID(h)
+	if err != nil {
+		return fmt.Errorf("%s: %w", h, err)
+	}
+	log.Infof("%s: changing swarm role to %s", h, swarmRole(h))
+	if err := swarmLeader.Exec(swarmLeader.Configurer.DockerCommandf("node %s %s", action, nodeID)); err != nil {
+		return fmt.Errorf("%s: failed to %s node: %w", h, action, err)
+	}
+
+	if err := waitSwarmManagers(ctx, swarmLeader); err != nil {
+		return fmt.Errorf("%s: %s: %w", h, action, err)
+	}
+	return nil
+}
+
+// waitSwarmManagers waits until all the swarm managers are reachable and raft has a
+// leader.
+func waitSwarmManagers(ctx context.Context, swarmLeader *mkeconfig.Host) error {
+	log.Infof("%s: waiting for the swarm managers to become healthy", swarmLeader)
+	err := retry.Periodic(
+		func() error {
+			return swarm.CheckManagers(swarmLeader)
+		},
+		retrygo.Context(ctx),
+		retrygo.Delay(5*time.Second),
+	)
+	if err != nil {
+		return fmt.Errorf("swarm managers not healthy: %w", err)
+	}
+	return nil
+}
+
+// waitMKE waits for the MKE manager components on the managers when MKE is installed.
+func (p *ChangeRoles) waitMKE(managers mkeconfig.Hosts) error {
+	if p.Config.Spec.MKE.Metadata == nil || !p.Config.Spec.MKE.Metadata.Installed {
+		return nil
+	}
+	if err := p.Config.Spec.CheckMKEHealthLocal(managers); err != nil {
+		return fmt.Errorf("MKE not healthy after role change: %w", err)
+	}
+	return nil
+}
+
+// removeSANsLabel removes the SANs label LabelNodes sets on the managers from a
+// demoted node.
+func removeSANsLabel(swarmLeader, h *mkeconfig.Host) error {
+	nodeID, err := swarm.NodeID(h)
+	if err != nil {
+		return fmt.Errorf("%s: %w", h, err)
+	}
+	labels, err := swarmLeader.ExecOutput(swarmLeader.Configurer.DockerCommandf(`node inspect %s --format "{{json .Spec.Labels}}"`, nodeID))
+	if err != nil {
+		return fmt.Errorf("%s: failed to get node labels: %w", h, err)
+	}
+	if !strings.Contains(labels, `"`+constant.SANsLabel+`"`) {
+		return nil
+	}
+	if err := swarmLeader.Exec(swarmLeader.Configurer.DockerCommandf("node update --label-rm %s %s", constant.SANsLabel, nodeID)); err != nil {
+		return fmt.Errorf("%s: failed to remove the SANs label: %w", h, err)
+	}
+	return nil
+}
+
+// roleChanges returns the hosts in a swarm whose swarm role differs from their role in
+// the configuration.
+func roleChanges(hosts mkeconfig.Hosts) (promote, demote []*mkeconfig.Host) {
+	for _, h := range hosts {
+		if h.Dropped() || h.Metadata == nil || h.Metadata.SwarmRole == "" {
+			continue
+		}
+		switch want := swarmRole(h); {
+		case want == h.Metadata.SwarmRole:
+		case want == "manager":
+			promote = append(promote, h)
+		default:
+			demote = append(demote, h)
+		}
+	}
+	return promote, demote
+}
+
+// validateRoleChanges refuses role changes that would leave the swarm with an even
+// number of managers, or that keep less than a majority of the current managers, which
+// the raft quorum depends on while the roles change.
+func validateRoleChanges(hosts mkeconfig.Hosts) error {
+	promote, demote := roleChanges(hosts)
+	if len(promote)+len(demote) == 0 {
+		return nil
+	}
+
+	var current, kept, desired int
+	for _, h := range hosts {
+		if h.Role == "manager" {
+			desired++
+		}
+		if h.Metadata == nil || h.Metadata.SwarmRole != "manager" {
+			continue
+		}
+		current++
+		if h.Role == "manager" {
+			kept++
+		}
+	}
+
+	if desired%2 == 0 {
+		return fmt.Errorf("%w: the cluster would have %d managers, an odd number of managers is needed for a fault tolerant quorum", errUnsafeRoleChange, desired)
+	}
+	if kept <= current/2 {
+		return fmt.Errorf("%w: %d of the %d current managers would remain managers, a majority of them is needed to keep the quorum", errUnsafeRoleChange, kept, current)
+	}
+	return nil
+}
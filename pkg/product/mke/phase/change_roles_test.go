@@ -0,0 +1,88 @@
+package phase
+
+import (
+	"testing"
+
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/stretchr/testify/require"
+)
+
+func roleHost(address, role, swarmRole string) *mkeconfig.Host {
+	h := driftHost(address, role, "25.0.8")
+	h.Metadata.SwarmRole = swarmRole
+	return h
+}
+
+func TestRoleChanges(t *testing.T) {
+	promoted := roleHost("10.0.0.2", "manager", "worker")
+	demoted := roleHost("10.0.0.3", "worker", "manager")
+	hosts := mkeconfig.Hosts{
+		roleHost("10.0.0.1", "manager", "manager"),
+		promoted,
+		demoted,
+		roleHost("10.0.0.4", "msr", "worker"),
+		roleHost("10.0.0.5", "manager", ""),
+	}
+	promote, demote := roleChanges(hosts)
+	require.Equal(t, []*mkeconfig.Host{promoted}, promote)
+	require.Equal(t, []*mkeconfig.Host{demoted}, demote)
+}
+
+func TestValidateRoleChanges(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		hosts := mkeconfig.Hosts{
+			roleHost("10.0.0.1", "manager", "manager"),
+			roleHost("10.0.0.2", "manager", "manager"),
+		}
+		require.NoError(t, validateRoleChanges(hosts))
+	})
+
+	t.Run("promote to three managers", func(t *testing.T) {
+		hosts := mkeconfig.Hosts{
+			roleHost("10.0.0.1", "manager", "manager"),
+			roleHost("10.0.0.2", "manager", "worker"),
+			roleHost("10.0.0.3", "manager", "worker"),
+		}
+		require.NoError(t, validateRoleChanges(hosts))
+	})
+
+	t.Run("even managers", func(t *testing.T) {
+		hosts := mkeconfig.Hosts{
+			roleHost("10.0.0.1", "manager", "manager"),
+			roleHost("10.0.0.2", "manager", "worker"),
+		}
+		require.ErrorIs(t, validateRoleChanges(hosts), errUnsafeRoleChange)
+		require.ErrorContains(t, validateRoleChanges(hosts), "would have 2 managers")
+	})
+
+	t.Run("swap managers", func(t *testing.T) {
+		hosts := mkeconfig.Hosts{
+			roleHost("10.0.0.1", "worker", "manager"),
+			roleHost("10.0.0.2", "worker", "manager"),
+			roleHost("10.0.0.3", "manager", "manager"),
+			roleHost("10.0.0.4", "manager", "worker"),
+			roleHost("10.0.0.5", "manager", "worker"),
+		}
+		require.ErrorContains(t, validateRoleChanges(hosts), "1 of the 3 current managers")
+	})
+
+	t.Run("demote to one manager", func(t *testing.T) {
+		hosts := mkeconfig.Hosts{
+			roleHost("10.0.0.1", "manager", "manager"),
+			roleHost("10.0.0.2", "worker", "manager"),
+			roleHost("10.0.0.3", "worker", "manager"),
+		}
+		require.ErrorContains(t, validateRoleChanges(hosts), "1 of the 3 current managers")
+	})
+
+	t.Run("demote five to three", func(t *testing.T) {
+		hosts := mkeconfig.Hosts{
+			roleHost("10.0.0.1", "manager", "manager"),
+			roleHost("10.0.0.2", "manager", "manager"),
+			roleHost("10.0.0.3", "manager", "manager"),
+			roleHost("10.0.0.4", "worker", "manager"),
+			roleHost("10.0.0.5", "worker", "manager"),
+		}
+		require.NoError(t, validateRoleChanges(hosts))
+	})
+}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
@@ -18,8 +19,8 @@ func (p *CleanUp) Title() string {
 }
 
 // Run does all the prep work on the hosts in parallel.
-func (p *CleanUp) Run() error {
-	err := phase.RunParallelOnHosts(p.Config.Spec.Hosts, p.Config, p.cleanupEnv)
+func (p *CleanUp) Run(ctx context.Context) error {
+	err := phase.RunParallelOnHosts(ctx, p.Config.Spec.Hosts, p.Config, p.cleanupEnv)
 	if err != nil {
 		return fmt.Errorf("failed to cleanup environment: %w", err)
 	}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
@@ -16,7 +17,7 @@ type ConfigureMCR struct {
 
 // HostFilterFunc returns true for hosts that need their engine to be restarted.
 func (p *ConfigureMCR) HostFilterFunc(h *mkeconfig.Host) bool {
-	return len(h.DaemonConfig) > 0
+	return !h.Dropped() && len(h.DaemonConfig) > 0
 }
 
 // Prepare collects the hosts.
@@ -39,7 +40,7 @@ func (p *ConfigureMCR) Title() string {
 }
 
 // Run installs the engine on each host.
-func (p *ConfigureMCR) Run() error {
+func (p *ConfigureMCR) Run(_ context.Context) error {
 	p.EventProperties = map[string]interface{}{
 		"engine_channel": p.Config.Spec.MCR.Channel,
 	}
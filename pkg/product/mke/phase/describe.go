@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"text/tabwriter"
@@ -23,7 +24,7 @@ func (p *Describe) Title() string {
 }
 
 // Run does the actual saving of the local state file.
-func (p *Describe) Run() error {
+func (p *Describe) Run(_ context.Context) error {
 	switch {
 	case p.MKE:
 		p.mkeReport()
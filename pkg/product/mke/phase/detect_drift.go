@@ -0,0 +1,239 @@
+package phase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	log "github.com/sirupsen/logrus"
+)
+
+// notInstalled is the cluster side of a drift when a component is missing.
+const notInstalled = "not installed"
+
+// DetectDrift phase compares the facts gathered from the cluster to the configuration
+// and records the differences in the report.
+type DetectDrift struct {
+	phase.BasicPhase
+	phase.ReadOnly
+
+	Report *phase.DriftReport
+}
+
+// Title for the phase.
+func (p *DetectDrift) Title() string {
+	return "Detect drift"
+}
+
+// Run compares the cluster to the configuration.
+func (p *DetectDrift) Run(_ context.Context) error {
+	if p.Report == nil {
+		p.Report = &phase.DriftReport{}
+	}
+
+	var nodes []swarm.Node
+	swarmLeader := p.Config.Spec.SwarmLeader()
+	switch {
+	case swarmLeader.Metadata == nil || swarmLeader.Metadata.MCRVersion == "":
+		p.Report.Add(phase.Drift{Item: "swarm", Config: "initialized", Cluster: "MCR not installed on " + swarmLeader.String()})
+	case !swarm.IsSwarmNode(swarmLeader):
+		p.Report.Add(phase.Drift{Item: "swarm", Config: "initialized", Cluster: "not initialized"})
+	default:
+		n, err := swarm.Nodes(swarmLeader)
+		if err != nil {
+			return fmt.Errorf("failed to get the swarm nodes: %w", err)
+		}
+		nodes = n
+	}
+
+	p.mkeDrift()
+
+	known := make(map[string]bool, len(p.Config.Spec.Hosts))
+	for _, h := range p.Config.Spec.Hosts {
+		if h.Metadata == nil || h.Metadata.MCRVersion == "" {
+			p.Report.Add(hostDrift(h, "mcr version", p.Config.Spec.MCR.Channel, notInstalled))
+			continue
+		}
+		p.mcrDrift(h)
+		p.daemonConfigDrift(h)
+		p.msrDrift(h)
+
+		if nodes == nil {
+			continue
+		}
+		nodeID, err := swarm.NodeID(h)
+		if err != nil {
+			return fmt.Errorf("%s: %w", h, err)
+		}
+		node := findNode(nodes, nodeID)
+		if node == nil {
+			p.Report.Add(hostDrift(h, "swarm node", "joined as "+swarmRole(h), "not joined"))
+			continue
+		}
+		known[node.ID] = true
+		p.nodeDrift(h, node)
+	}
+
+	for _, node := range nodes {
+		if known[node.ID] {
+			continue
+		}
+		d := phase.Drift{
+			Host:    fmt.Sprintf("%s (%s)", node.Address(), node.Description.Hostname),
+			Role:    node.Spec.Role,
+			Item:    "swarm node",
+			Cluster: "not in configuration",
+		}
+		if !p.Config.Spec.Cluster.Prune {
+			// apply only removes the nodes that are not in the configuration with prune
+			d.NotApplied = "spec.cluster.prune is not enabled"
+		}
+		p.Report.Add(d)
+	}
+
+	log.Infof("found %d differences between the configuration and the cluster", len(p.Report.Drift))
+	return nil
+}
+
+func (p *DetectDrift) mkeDrift() {
+	meta := p.Config.Spec.MKE.Metadata
+	switch {
+	case meta == nil || !meta.Installed:
+		p.Report.Add(phase.Drift{Item: "mke version", Config: p.Config.Spec.MKE.Version, Cluster: notInstalled})
+	case meta.InstalledVersion != p.Config.Spec.MKE.Version:
+		p.Report.Add(phase.Drift{Item: "mke version", Config: p.Config.Spec.MKE.Version, Cluster: meta.InstalledVersion})
+	}
+}
+
+func (p *DetectDrift) msrDrift(h *mkeconfig.Host) {
+	if h.Role != "msr" || p.Config.Spec.MSR == nil {
+		return
+	}
+	switch {
+	case h.MSRMetadata == nil || !h.MSRMetadata.Installed:
+		p.Report.Add(hostDrift(h, "msr version", p.Config.Spec.MSR.Version, notInstalled))
+	case h.MSRMetadata.InstalledVersion != p.Config.Spec.MSR.Version:
+		p.Report.Add(hostDrift(h, "msr version", p.Config.Spec.MSR.Version, h.MSRMetadata.InstalledVersion))
+	}
+}
+
+// mcrDrift compares the MCR version of the host to the version the channel pins, such
+// as 25.0 in stable-25.0. A channel without a version, such as stable, allows any version.
+func (p *DetectDrift) mcrDrift(h *mkeconfig.Host) {
+	if installed := h.Metadata.MCRVersion; !channelMatches(p.Config.Spec.MCR.Channel, installed) {
+		p.Report.Add(hostDrift(h, "mcr version", p.Config.Spec.MCR.Channel, installed))
+	}
+}
+
+// daemonConfigDrift compares the mcrConfig of the host to the daemon.json on the host.
+// The keys only found in the daemon.json are not drift, launchpad keeps them.
+func (p *DetectDrift) daemonConfigDrift(h *mkeconfig.Host) {
+	keys := make([]string, 0, len(h.DaemonConfig))
+	for k := range h.DaemonConfig {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		want := jsonValue(h.DaemonConfig[k])
+		got := ""
+		if v, ok := h.Metadata.DaemonConfig[k]; ok {
+			got = jsonValue(v)
+		}
+		if want != got {
+			p.Report.Add(hostDrift(h, "mcrConfig."+k, want, got))
+		}
+	}
+}
+
+// nodeDrift compares the swarm role and the labels of the node of the host. The
+// taints are on the Kubernetes node, they are compared to the list of the taints
+// launchpad has set.
+func (p *DetectDrift) nodeDrift(h *mkeconfig.Host, node *swarm.Node) {
+	if role := swarmRole(h); node.Spec.Role != role {
+		p.Report.Add(hostDrift(h, "swarm role", role, node.Spec.Role))
+	}
+
+	labels := make(map[string]string, len(h.Labels)+3)
+	for k, v := range h.Labels {
+		labels[k] = v
+	}
+	labels[constant.ManagedLabel] = "true"
+	if h.Role == "msr" {
+		labels[constant.ManagedMSRLabel] = "true"
+	}
+	if sans := sanLabel(p.Config); h.Role == "manager" && sans != "" {
+		labels[constant.SANsLabel] = sans
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if got := node.Spec.Labels[k]; got != labels[k] {
+			p.Report.Add(hostDrift(h, "label "+k, labels[k], got))
+		}
+	}
+
+	for _, k := range missing(splitList(node.Spec.Labels[constant.ManagedLabelsLabel]), keys) {
+		if got, ok := node.Spec.Labels[k]; ok {
+			p.Report.Add(hostDrift(h, "label "+k, "", got))
+		}
+	}
+
+	want := strings.Join(hostTaintKeys(h), ",")
+	if got := node.Spec.Labels[constant.ManagedTaintsLabel]; got != want {
+		p.Report.Add(hostDrift(h, "taints", want, got))
+	}
+}
+
+func hostDrift(h *mkeconfig.Host, item, config, cluster string) phase.Drift {
+	return phase.Drift{Host: h.String(), Role: h.Role, Item: item, Config: config, Cluster: cluster}
+}
+
+// swarmRole returns the swarm role of the host, msr hosts are swarm workers.
+func swarmRole(h *mkeconfig.Host) string {
+	if h.Role == "manager" {
+		return "manager"
+	}
+	return "worker"
+}
+
+func findNode(nodes []swarm.Node, id string) *swarm.Node {
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+// channelMatches returns true when the version belongs to the channel. Channels
+// without a version, such as stable, match all versions.
+func channelMatches(channel, version string) bool {
+	i := strings.LastIndex(channel, "-")
+	if i < 0 {
+		return true
+	}
+	channelVersion := channel[i+1:]
+	if channelVersion == "" || channelVersion[0] < '0' || channelVersion[0] > '9' {
+		return true
+	}
+	return version == channelVersion || strings.HasPrefix(version, channelVersion+".")
+}
+
+// jsonValue formats a daemon.json value for comparison and display.
+func jsonValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
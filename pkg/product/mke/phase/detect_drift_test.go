@@ -0,0 +1,142 @@
+package phase
+
+import (
+	"testing"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	commonconfig "github.com/Mirantis/launchpad/pkg/product/common/config"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	"github.com/k0sproject/dig"
+	"github.com/k0sproject/rig"
+	"github.com/stretchr/testify/require"
+)
+
+func driftHost(address, role, mcrVersion string) *mkeconfig.Host {
+	return &mkeconfig.Host{
+		Connection: rig.Connection{SSH: &rig.SSH{Address: address}},
+		Role:       role,
+		Metadata:   &mkeconfig.HostMetadata{MCRVersion: mcrVersion},
+	}
+}
+
+func TestChannelMatches(t *testing.T) {
+	require.True(t, channelMatches("stable", "25.0.8"))
+	require.True(t, channelMatches("stable-25.0", "25.0.8"))
+	require.True(t, channelMatches("stable-25.0.8", "25.0.8"))
+	require.True(t, channelMatches("test-fips", "25.0.8"))
+	require.False(t, channelMatches("stable-25.0", "23.0.15"))
+	require.False(t, channelMatches("stable-25.0", "25.01.1"))
+}
+
+func TestDetectDriftVersions(t *testing.T) {
+	p := DetectDrift{Report: &phase.DriftReport{}}
+	p.Config = &mkeconfig.ClusterConfig{
+		Spec: &mkeconfig.ClusterSpec{
+			MCR: commonconfig.MCRConfig{Channel: "stable-25.0"},
+			MKE: mkeconfig.MKEConfig{
+				Version:  "3.8.1",
+				Metadata: &mkeconfig.MKEMetadata{Installed: true, InstalledVersion: "3.7.15"},
+			},
+			MSR: &mkeconfig.MSRConfig{Version: "2.9.20"},
+		},
+	}
+	manager := driftHost("10.0.0.1", "manager", "25.0.8")
+	worker := driftHost("10.0.0.2", "worker", "25.0.9")
+	old := driftHost("10.0.0.3", "worker", "23.0.15")
+	msrHost := driftHost("10.0.0.4", "msr", "25.0.8")
+	msrHost.MSRMetadata = &mkeconfig.MSRMetadata{Installed: true, InstalledVersion: "2.9.19"}
+
+	p.mkeDrift()
+	for _, h := range []*mkeconfig.Host{manager, worker, old, msrHost} {
+		p.mcrDrift(h)
+		p.msrDrift(h)
+	}
+
+	require.Equal(t, []phase.Drift{
+		{Item: "mke version", Config: "3.8.1", Cluster: "3.7.15"},
+		{Host: old.String(), Role: old.Role, Item: "mcr version", Config: "stable-25.0", Cluster: "23.0.15"},
+		{Host: msrHost.String(), Role: msrHost.Role, Item: "msr version", Config: "2.9.20", Cluster: "2.9.19"},
+	}, p.Report.Drift)
+
+	// a channel without a version allows any version
+	p.Config.Spec.MCR.Channel = "stable"
+	p.Report = &phase.DriftReport{}
+	p.mcrDrift(old)
+	require.True(t, p.Report.Empty())
+}
+
+func TestDetectDriftDaemonConfig(t *testing.T) {
+	p := DetectDrift{Report: &phase.DriftReport{}}
+	h := driftHost("10.0.0.1", "worker", "25.0.8")
+	h.DaemonConfig = dig.Mapping{
+		"debug":     true,
+		"log-opts":  dig.Mapping{"max-size": "10m"},
+		"max-con":   5,
+		"data-root": "/data",
+		"from-disk": "kept",
+	}
+	h.Metadata.DaemonConfig = dig.Mapping{
+		"debug":     true,
+		"log-opts":  map[string]interface{}{"max-size": "20m"},
+		"max-con":   float64(5),
+		"from-disk": "kept",
+	}
+
+	p.daemonConfigDrift(h)
+	require.Equal(t, []phase.Drift{
+		{Host: h.String(), Role: h.Role, Item: "mcrConfig.data-root", Config: `"/data"`, Cluster: ""},
+		{Host: h.String(), Role: h.Role, Item: "mcrConfig.log-opts", Config: `{"max-size":"10m"}`, Cluster: `{"max-size":"20m"}`},
+	}, p.Report.Drift)
+}
+
+func TestDetectDriftNode(t *testing.T) {
+	p := DetectDrift{Report: &phase.DriftReport{}}
+	p.Config = &mkeconfig.ClusterConfig{
+		Spec: &mkeconfig.ClusterSpec{
+			MKE: mkeconfig.MKEConfig{InstallFlags: commonconfig.Flags{"--san=mke.example.com"}},
+		},
+	}
+	h := driftHost("10.0.0.1", "manager", "25.0.8")
+	node := &swarm.Node{}
+	node.Spec.Role = "worker"
+	node.Spec.Labels = map[string]string{"com.mirantis.launchpad.managed": "true"}
+
+	p.nodeDrift(h, node)
+	require.Equal(t, []phase.Drift{
+		{Host: h.String(), Role: h.Role, Item: "swarm role", Config: "manager", Cluster: "worker"},
+		{Host: h.String(), Role: h.Role, Item: "label com.docker.ucp.SANs", Config: "mke.example.com", Cluster: ""},
+	}, p.Report.Drift)
+
+	p.Report = &phase.DriftReport{}
+	msrHost := driftHost("10.0.0.2", "msr", "25.0.8")
+	p.nodeDrift(msrHost, node)
+	require.Equal(t, []phase.Drift{
+		{Host: msrHost.String(), Role: msrHost.Role, Item: "label com.mirantis.launchpad.managed.dtr", Config: "true", Cluster: ""},
+	}, p.Report.Drift)
+}
+
+func TestDetectDriftNodeLabels(t *testing.T) {
+	p := DetectDrift{Report: &phase.DriftReport{}}
+	p.Config = &mkeconfig.ClusterConfig{Spec: &mkeconfig.ClusterSpec{}}
+	h := driftHost("10.0.0.2", "worker", "25.0.8")
+	h.Labels = map[string]string{"zone": "eu-1", "gpu": "true"}
+	h.Taints = []mkeconfig.Taint{{Key: "dedicated", Value: "db", Effect: "NoSchedule"}}
+	node := &swarm.Node{}
+	node.Spec.Role = "worker"
+	node.Spec.Labels = map[string]string{
+		"com.mirantis.launchpad.managed": "true",
+		"com.mirantis.launchpad.labels":  "old,zone",
+		"zone":                           "eu-2",
+		"old":                            "value",
+		"other":                          "kept",
+	}
+
+	p.nodeDrift(h, node)
+	require.Equal(t, []phase.Drift{
+		{Host: h.String(), Role: h.Role, Item: "label gpu", Config: "true", Cluster: ""},
+		{Host: h.String(), Role: h.Role, Item: "label zone", Config: "eu-1", Cluster: "eu-2"},
+		{Host: h.String(), Role: h.Role, Item: "label old", Config: "", Cluster: "value"},
+		{Host: h.String(), Role: h.Role, Item: "taints", Config: "dedicated:NoSchedule", Cluster: ""},
+	}, p.Report.Drift)
+}
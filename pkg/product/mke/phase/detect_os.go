@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	// anonymous import is needed to load the os configurers.
@@ -19,6 +20,7 @@ import (
 type DetectOS struct {
 	phase.Analytics
 	phase.BasicPhase
+	phase.ReadOnly
 }
 
 // Title for the phase.
@@ -27,7 +29,7 @@ func (p *DetectOS) Title() string {
 }
 
 // Run the phase.
-func (p *DetectOS) Run() error {
+func (p *DetectOS) Run(_ context.Context) error {
 	err := p.Config.Spec.Hosts.ParallelEach(func(h *mkeconfig.Host) error {
 		if err := h.ResolveConfigurer(); err != nil {
 			return fmt.Errorf("failed to resolve configurer for %s: %w", h, err)
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/mke"
@@ -20,7 +21,7 @@ func (p *DownloadBundle) Title() string {
 var errInvalidConfig = fmt.Errorf("invalid config")
 
 // Run collect all the facts from hosts in parallel.
-func (p *DownloadBundle) Run() error {
+func (p *DownloadBundle) Run(_ context.Context) error {
 	if err := mke.DownloadBundle(p.Config); err != nil {
 		return fmt.Errorf("failed to download client bundle: %w", err)
 	}
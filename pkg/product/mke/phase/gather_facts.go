@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -31,6 +32,7 @@ import (
 type GatherFacts struct {
 	phase.Analytics
 	phase.BasicPhase
+	phase.ReadOnly
 }
 
 // Title for the phase.
@@ -39,8 +41,8 @@ func (p *GatherFacts) Title() string {
 }
 
 // Run collect all the facts from hosts in parallel.
-func (p *GatherFacts) Run() error {
-	err := phase.RunParallelOnHosts(p.Config.Spec.Hosts, p.Config, p.investigateHost)
+func (p *GatherFacts) Run(ctx context.Context) error {
+	err := phase.RunParallelOnHosts(ctx, p.Config.Spec.Hosts, p.Config, p.investigateHost)
 	if err != nil {
 		return fmt.Errorf("failed to gather facts: %w", err)
 	}
@@ -109,6 +111,7 @@ func (p *GatherFacts) investigateHost(h *mkeconfig.Host, _ *mkeconfig.ClusterCon
 		if err == nil {
 			var newCfg dig.Mapping
 			if err = json.Unmarshal([]byte(configData), &newCfg); err == nil {
+				h.Metadata.DaemonConfig = newCfg
 				for k, v := range newCfg {
 					if _, ok := h.DaemonConfig[k]; !ok {
 						log.Debugf("%s: set %s = %t for spec.hosts[].daemonConfig from existing daemon.json", h, k, v)
@@ -117,6 +120,11 @@ func (p *GatherFacts) investigateHost(h *mkeconfig.Host, _ *mkeconfig.ClusterCon
 				}
 			}
 		}
+		if role, err := swarm.LocalRole(h); err != nil {
+			log.Warnf("%s: %s", h, err)
+		} else {
+			h.Metadata.SwarmRole = role
+		}
 	}
 
 	h.Metadata.MCRVersion = version
@@ -1,6 +1,8 @@
 package phase
 
 import (
+	"context"
+	"github.com/Mirantis/launchpad/pkg/eventstream"
 	"github.com/Mirantis/launchpad/pkg/phase"
 	log "github.com/sirupsen/logrus"
 )
@@ -8,6 +10,7 @@ import (
 // Info shows information about the configured clusters.
 type Info struct {
 	phase.BasicPhase
+	phase.Repeatable
 }
 
 // Title for the phase.
@@ -15,20 +18,34 @@ func (p *Info) Title() string {
 	return "MKE cluster info"
 }
 
+// PlannedActions returns nothing as the phase only prints information.
+func (p *Info) PlannedActions() []phase.PlannedAction {
+	return nil
+}
+
 // Run ...
-func (p *Info) Run() error {
+func (p *Info) Run(_ context.Context) error {
 	log.Info("Cluster is now configured.")
 
+	info := map[string]interface{}{
+		"name":        p.Config.Metadata.Name,
+		"mke_version": p.Config.Spec.MKE.Version,
+	}
+
 	mkeurl, err := p.Config.Spec.MKEURL()
 	if err == nil {
 		log.Infof("MKE cluster admin UI: %s", mkeurl)
+		info["mke_url"] = mkeurl.String()
 	}
 
 	msrurl, err := p.Config.Spec.MSRURL()
 	if err == nil {
 		log.Infof("MSR cluster admin UI: %s", msrurl)
+		info["msr_url"] = msrurl.String()
 	}
 
+	eventstream.Emit(eventstream.Event{Type: eventstream.TypeClusterInfo, Data: info})
+
 	log.Info("You can download the admin client bundle with the command 'launchpad client-config'")
 
 	return nil
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
@@ -13,6 +14,7 @@ import (
 type InitSwarm struct {
 	phase.Analytics
 	phase.BasicPhase
+	phase.Repeatable
 }
 
 // Title for the phase.
@@ -21,7 +23,7 @@ func (p *InitSwarm) Title() string {
 }
 
 // Run initializes the swarm on the leader or skips if swarm is already initialized.
-func (p *InitSwarm) Run() error {
+func (p *InitSwarm) Run(_ context.Context) error {
 	swarmLeader := p.Config.Spec.SwarmLeader()
 
 	if !swarm.IsSwarmNode(swarmLeader) {
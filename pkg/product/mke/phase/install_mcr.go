@@ -1,7 +1,9 @@
 package phase
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
 	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
@@ -16,7 +18,7 @@ type InstallMCR struct {
 
 // HostFilterFunc returns true for hosts that do not have engine installed.
 func (p *InstallMCR) HostFilterFunc(h *mkeconfig.Host) bool {
-	return h.Metadata.MCRVersion == ""
+	return !h.Dropped() && h.Metadata.MCRVersion == ""
 }
 
 // Prepare collects the hosts.
@@ -34,19 +36,38 @@ func (p *InstallMCR) Prepare(config any) error {
 	return nil
 }
 
+// PlannedActions lists the hosts that will get the container runtime installed.
+func (p *InstallMCR) PlannedActions() []phase.PlannedAction {
+	actions := make([]phase.PlannedAction, 0, len(p.Hosts))
+	for _, h := range p.Hosts {
+		actions = append(actions, phase.PlannedAction{Host: h.String(), Action: fmt.Sprintf("install MCR (channel %s)", p.Config.Spec.MCR.Channel)})
+	}
+	return actions
+}
+
 // Title for the phase.
 func (p *InstallMCR) Title() string {
 	return "Install Mirantis Container Runtime on the hosts"
 }
 
 // Run installs the engine on each host.
-func (p *InstallMCR) Run() error {
+func (p *InstallMCR) Run(_ context.Context) error {
 	p.EventProperties = map[string]any{
 		"engine_channel": p.Config.Spec.MCR.Channel,
 	}
 
-	if err := p.Hosts.ParallelEach(p.installMCR); err != nil {
-		return fmt.Errorf("failed to install container runtime: %w", err)
+	var mu sync.Mutex
+	installErrors := &phase.Error{}
+	_ = p.Hosts.ParallelEach(func(h *mkeconfig.Host) error {
+		if err := p.installMCR(h); err != nil {
+			mu.Lock()
+			installErrors.AddHostError(h, err)
+			mu.Unlock()
+		}
+		return nil
+	})
+	if installErrors.Count() > 0 {
+		return installErrors
 	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
@@ -25,7 +26,7 @@ func (p *InstallMCRLicense) Title() string {
 }
 
 // Run installs the engine on each host.
-func (p *InstallMCRLicense) Run() error {
+func (p *InstallMCRLicense) Run(_ context.Context) error {
 	if err := p.Hosts.ParallelEach(p.installMCRLicense); err != nil {
 		return fmt.Errorf("failed to install MCR license: %w", err)
 	}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
@@ -31,8 +32,16 @@ func (p *InstallMKE) Title() string {
 	return "Install MKE components"
 }
 
+// PlannedActions describes the MKE installation when MKE is not yet installed.
+func (p *InstallMKE) PlannedActions() []phase.PlannedAction {
+	if p.Config.Spec.MKE.Metadata.Installed {
+		return nil
+	}
+	return []phase.PlannedAction{{Host: p.Config.Spec.SwarmLeader().String(), Action: fmt.Sprintf("install MKE %s", p.Config.Spec.MKE.Version)}}
+}
+
 // Run the installer container.
-func (p *InstallMKE) Run() error {
+func (p *InstallMKE) Run(ctx context.Context) error {
 	p.leader = p.Config.Spec.SwarmLeader()
 
 	p.EventProperties = map[string]interface{}{
@@ -81,7 +90,7 @@ func (p *InstallMKE) Run() error {
 			installFlags.AddUnlessExist("--cloud-provider " + p.Config.Spec.MKE.Cloud.Provider)
 		}
 		if p.Config.Spec.MKE.Cloud.ConfigData != "" {
-			if err := applyCloudConfig(p.Config); err != nil {
+			if err := applyCloudConfig(ctx, p.Config); err != nil {
 				return err
 			}
 		}
@@ -128,7 +137,7 @@ func (p *InstallMKE) Run() error {
 
 var errUnsupportedProvider = errors.New("unsupported cloud provider")
 
-func applyCloudConfig(config *mkeconfig.ClusterConfig) error {
+func applyCloudConfig(ctx context.Context, config *mkeconfig.ClusterConfig) error {
 	configData := config.Spec.MKE.Cloud.ConfigData
 	provider := config.Spec.MKE.Cloud.Provider
 
@@ -142,7 +151,7 @@ func applyCloudConfig(config *mkeconfig.ClusterConfig) error {
 		return fmt.Errorf("%w: spec.Cloud.configData is only supported with Azure and OpenStack cloud providers", errUnsupportedProvider)
 	}
 
-	err := phase.RunParallelOnHosts(config.Spec.Hosts, config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
+	err := phase.RunParallelOnHosts(ctx, config.Spec.Hosts, config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
 		if h.IsWindows() {
 			log.Warnf("%s: cloud provider configuration is not suppported on windows", h)
 			return nil
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
@@ -28,7 +29,7 @@ func (p *InstallMKECerts) ShouldRun() bool {
 	return true
 }
 
-func (p *InstallMKECerts) Run() (err error) {
+func (p *InstallMKECerts) Run(_ context.Context) (err error) {
 	log.Debug("adding install flag '--external-server-cert'. If this is an upgrade, then external certs must already be enabled.")
 	p.Config.Spec.MKE.InstallFlags.AddUnlessExist("--external-server-cert")
 
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"al.essio.dev/pkg/shellescape"
@@ -29,11 +30,16 @@ func (p *InstallMSR) Title() string {
 // ShouldRun should return true only when there is an installation to be performed.
 func (p *InstallMSR) ShouldRun() bool {
 	p.leader = p.Config.Spec.MSRLeader()
-	return p.Config.Spec.ContainsMSR() && (p.leader.MSRMetadata == nil || !p.leader.MSRMetadata.Installed)
+	return p.Config.Spec.ContainsMSR() && p.leader != nil && (p.leader.MSRMetadata == nil || !p.leader.MSRMetadata.Installed)
+}
+
+// PlannedActions describes the MSR installation.
+func (p *InstallMSR) PlannedActions() []phase.PlannedAction {
+	return []phase.PlannedAction{{Host: p.leader.String(), Action: fmt.Sprintf("install MSR %s", p.Config.Spec.MSR.Version)}}
 }
 
 // Run the installer container.
-func (p *InstallMSR) Run() error {
+func (p *InstallMSR) Run(_ context.Context) error {
 	h := p.leader
 	if h.MSRMetadata == nil {
 		h.MSRMetadata = &mkeconfig.MSRMetadata{}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
@@ -20,8 +21,13 @@ func (p *JoinManagers) Title() string {
 	return "Join managers to swarm"
 }
 
+// PlannedActions lists the managers that are not yet part of the swarm.
+func (p *JoinManagers) PlannedActions() []phase.PlannedAction {
+	return plannedJoins(p.Config.Spec.Managers(), "join swarm as manager")
+}
+
 // Run joins the manager nodes into swarm.
-func (p *JoinManagers) Run() error {
+func (p *JoinManagers) Run(_ context.Context) error {
 	swarmLeader := p.Config.Spec.SwarmLeader()
 
 	for _, h := range p.Config.Spec.Managers() {
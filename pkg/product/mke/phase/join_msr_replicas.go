@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"al.essio.dev/pkg/shellescape"
@@ -21,7 +22,7 @@ type JoinMSRReplicas struct {
 
 // HostFilterFunc returns true for hosts that don't have MSR configured.
 func (p *JoinMSRReplicas) HostFilterFunc(h *mkeconfig.Host) bool {
-	return h.MSRMetadata == nil || !h.MSRMetadata.Installed
+	return !h.Dropped() && (h.MSRMetadata == nil || !h.MSRMetadata.Installed)
 }
 
 // Prepare collects the hosts.
@@ -48,7 +49,7 @@ func (p *JoinMSRReplicas) Title() string {
 }
 
 // Run joins all the workers nodes to swarm if not already part of it.
-func (p *JoinMSRReplicas) Run() error {
+func (p *JoinMSRReplicas) Run(_ context.Context) error {
 	msrLeader := p.Config.Spec.MSRLeader()
 	mkeFlags := msr.BuildMKEFlags(p.Config)
 
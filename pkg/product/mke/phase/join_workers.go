@@ -1,10 +1,12 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
 	"github.com/Mirantis/launchpad/pkg/swarm"
 	retry "github.com/avast/retry-go"
 	"github.com/k0sproject/rig/exec"
@@ -22,13 +24,22 @@ func (p *JoinWorkers) Title() string {
 	return "Join workers"
 }
 
+// PlannedActions lists the workers and MSR nodes that are not yet part of the swarm.
+func (p *JoinWorkers) PlannedActions() []phase.PlannedAction {
+	return plannedJoins(p.Config.Spec.WorkersAndMSRs(), "join swarm as worker")
+}
+
 // Run joins all the workers nodes to swarm if not already part of it.
-func (p *JoinWorkers) Run() error {
+func (p *JoinWorkers) Run(ctx context.Context) error {
 	swarmLeader := p.Config.Spec.SwarmLeader()
 
 	hosts := p.Config.Spec.WorkersAndMSRs()
+	joinErrors := &phase.Error{}
 
 	for _, h := range hosts {
+		if h.Dropped() {
+			continue
+		}
 		if swarm.IsSwarmNode(h) {
 			log.Infof("%s: already a swarm node", h)
 			continue
@@ -37,7 +48,8 @@ func (p *JoinWorkers) Run() error {
 		log.Debugf("%s: joining as worker", h)
 		err := h.Exec(joinCmd, exec.RedactString(p.Config.Spec.MCR.Metadata.WorkerJoinToken))
 		if err != nil {
-			return fmt.Errorf("failed to join worker %s node to swarm: %w", h, err)
+			joinErrors.AddHostError(h, fmt.Errorf("failed to join worker node to swarm: %w", err))
+			continue
 		}
 		log.Infof("%s: joined successfully", h)
 		if h.IsWindows() {
@@ -54,12 +66,30 @@ func (p *JoinWorkers) Run() error {
 						return fmt.Errorf("error reconnecting host %s: %w", h, err)
 					}
 					return nil
-				})
+				},
+				retry.Context(ctx),
+			)
 			if err != nil {
-				return fmt.Errorf("retry count exceeded: %w", err)
+				joinErrors.AddHostError(h, fmt.Errorf("reconnect after swarm join: retry count exceeded: %w", err))
+				continue
 			}
 			log.Infof("%s: reconnected", h)
 		}
 	}
+	if joinErrors.Count() > 0 {
+		return joinErrors
+	}
 	return nil
 }
+
+// plannedJoins returns an action for each of the hosts that is not yet a swarm node.
+func plannedJoins(hosts mkeconfig.Hosts, action string) []phase.PlannedAction {
+	var actions []phase.PlannedAction
+	for _, h := range hosts {
+		if h.Metadata.MCRVersion != "" && swarm.IsSwarmNode(h) {
+			continue
+		}
+		actions = append(actions, phase.PlannedAction{Host: h.String(), Action: action})
+	}
+	return actions
+}
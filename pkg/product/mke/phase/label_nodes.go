@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -23,8 +24,8 @@ func (p *LabelNodes) Title() string {
 	return "Label nodes"
 }
 
-// Run labels all nodes with launchpad label.
-func (p *LabelNodes) Run() error {
+// Run labels all nodes with launchpad label and the labels and taints of the hosts.
+func (p *LabelNodes) Run(ctx context.Context) error {
 	swarmLeader := p.Config.Spec.SwarmLeader()
 
 	err := p.labelCurrentNodes(p.Config, swarmLeader)
@@ -32,44 +33,65 @@ func (p *LabelNodes) Run() error {
 		return err
 	}
 
-	return nil
+	return p.reconcileNodeLabels(ctx, swarmLeader)
 }
 
 func (p *LabelNodes) labelCurrentNodes(config *mkeconfig.ClusterConfig, swarmLeader *mkeconfig.Host) error {
+	sanList := sanLabel(p.Config)
+
+	labelErrors := &phase.Error{}
+	for _, h := range config.Spec.Hosts {
+		if h.Dropped() {
+			continue
+		}
+		if err := labelNode(h, swarmLeader, sanList); err != nil {
+			labelErrors.AddHostError(h, err)
+		}
+	}
+	if labelErrors.Count() > 0 {
+		return labelErrors
+	}
+	return nil
+}
+
+// sanLabel returns the value of the SANs label of the managers, the --san addresses of
+// the MKE install flags.
+func sanLabel(config *mkeconfig.ClusterConfig) string {
 	var sans []string
-	for _, flag := range p.Config.Spec.MKE.InstallFlags {
+	for _, flag := range config.Spec.MKE.InstallFlags {
 		if strings.HasPrefix(flag, "--san") {
 			sans = append(sans, commonconfig.FlagValue(flag))
 		}
 	}
-	sanList := strings.Join(sans, ",")
+	return strings.Join(sans, ",")
+}
 
-	for _, h := range config.Spec.Hosts {
-		nodeID, err := swarm.NodeID(h)
+// labelNode adds the launchpad labels to the swarm node of the host.
+func labelNode(h, swarmLeader *mkeconfig.Host, sanList string) error {
+	nodeID, err := swarm.NodeID(h)
+	if err != nil {
+		return fmt.Errorf("failed to get node ID for %s: %w", h, err)
+	}
+	log.Infof("%s: labeling node", h)
+	if h.Role == "manager" && len(sanList) > 0 {
+		sanLabelCmd := swarmLeader.Configurer.DockerCommandf("node update --label-add %s=%s %s", constant.SANsLabel, sanList, nodeID)
+		err = swarmLeader.Exec(sanLabelCmd)
 		if err != nil {
-			return fmt.Errorf("failed to get node ID for %s: %w", h, err)
-		}
-		log.Infof("%s: labeling node", h)
-		if h.Role == "manager" && len(sanList) > 0 {
-			sanLabelCmd := swarmLeader.Configurer.DockerCommandf("node update --label-add com.docker.ucp.SANs=%s %s", sanList, nodeID)
-			err = swarmLeader.Exec(sanLabelCmd)
-			if err != nil {
-				return fmt.Errorf("failed to add SANs label for node %s: %w", h, err)
-			}
+			return fmt.Errorf("failed to add SANs label for node %s: %w", h, err)
 		}
-		if h.Role == "msr" {
-			// Add the MSR label in addition to the managed label
-			msrLabelCmd := swarmLeader.Configurer.DockerCommandf("%s %s", constant.ManagedMSRLabelCmd, nodeID)
-			err = swarmLeader.Exec(msrLabelCmd)
-			if err != nil {
-				return fmt.Errorf("failed to label node %s as MSR (%s): %w", h, nodeID, err)
-			}
-		}
-		labelCmd := swarmLeader.Configurer.DockerCommandf("%s %s", constant.ManagedLabelCmd, nodeID)
-		err = swarmLeader.Exec(labelCmd)
+	}
+	if h.Role == "msr" {
+		// Add the MSR label in addition to the managed label
+		msrLabelCmd := swarmLeader.Configurer.DockerCommandf("%s %s", constant.ManagedMSRLabelCmd, nodeID)
+		err = swarmLeader.Exec(msrLabelCmd)
 		if err != nil {
-			return fmt.Errorf("failed to label node %s (%s): %w", h, nodeID, err)
+			return fmt.Errorf("failed to label node %s as MSR (%s): %w", h, nodeID, err)
 		}
 	}
+	labelCmd := swarmLeader.Configurer.DockerCommandf("%s %s", constant.ManagedLabelCmd, nodeID)
+	err = swarmLeader.Exec(labelCmd)
+	if err != nil {
+		return fmt.Errorf("failed to label node %s (%s): %w", h, nodeID, err)
+	}
 	return nil
 }
@@ -9,7 +9,8 @@ type MSRPhase struct {
 	phase.BasicPhase
 }
 
-// ShouldRun default implementation for MSR phase returns true when the config has MSR nodes.
+// ShouldRun default implementation for MSR phase returns true when the config has MSR nodes
+// that have not been dropped from the run.
 func (p *MSRPhase) ShouldRun() bool {
-	return p.Config.Spec.ContainsMSR()
+	return p.Config.Spec.ContainsMSR() && p.Config.Spec.MSRLeader() != nil
 }
@@ -0,0 +1,244 @@
+package phase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/kubeclient"
+	"github.com/Mirantis/launchpad/pkg/mke"
+	"github.com/Mirantis/launchpad/pkg/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/retry"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	retrygo "github.com/avast/retry-go"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var errNodeNotFound = errors.New("node not found")
+
+// nodeLabels are the changes to the labels and taints of a node. The keys of the labels
+// and taints launchpad has set are kept in the ManagedLabelsLabel and ManagedTaintsLabel
+// swarm labels, so that the ones removed from the configuration can be removed from the
+// node without touching the labels and taints set by others.
+type nodeLabels struct {
+	labels       map[string]string
+	removeLabels []string
+	taints       []mkeconfig.Taint
+	removeTaints []string
+
+	// swarmArgs are the docker node update arguments for the swarm node
+	swarmArgs []string
+}
+
+// newNodeLabels compares the labels and taints of the host to the labels of its swarm node.
+func newNodeLabels(h *mkeconfig.Host, current map[string]string) *nodeLabels {
+	n := &nodeLabels{labels: h.Labels, taints: h.Taints}
+	labelKeys := hostLabelKeys(h)
+	taintKeys := hostTaintKeys(h)
+
+	n.removeLabels = missing(splitList(current[constant.ManagedLabelsLabel]), labelKeys)
+	n.removeTaints = missing(splitList(current[constant.ManagedTaintsLabel]), taintKeys)
+
+	for _, k := range n.removeLabels {
+		if _, ok := current[k]; ok {
+			n.swarmArgs = append(n.swarmArgs, "--label-rm "+shellescape.Quote(k))
+		}
+	}
+	for _, k := range labelKeys {
+		if v, ok := current[k]; !ok || v != h.Labels[k] {
+			n.swarmArgs = append(n.swarmArgs, "--label-add "+shellescape.Quote(k+"="+h.Labels[k]))
+		}
+	}
+	n.track(current, constant.ManagedLabelsLabel, labelKeys)
+	n.track(current, constant.ManagedTaintsLabel, taintKeys)
+	return n
+}
+
+// track updates the swarm label that lists the managed keys.
+func (n *nodeLabels) track(current map[string]string, label string, keys []string) {
+	value := strings.Join(keys, ",")
+	old, ok := current[label]
+	switch {
+	case value == "" && ok:
+		n.swarmArgs = append(n.swarmArgs, "--label-rm "+label)
+	case value != "" && old != value:
+		n.swarmArgs = append(n.swarmArgs, "--label-add "+shellescape.Quote(label+"="+value))
+	}
+}
+
+// kubeChanges returns the changes to the Kubernetes node, false when there are no
+// labels or taints to set or remove.
+func (n *nodeLabels) kubeChanges() (kubeclient.NodeChanges, bool) {
+	changes := kubeclient.NodeChanges{
+		Labels:       n.labels,
+		RemoveLabels: n.removeLabels,
+	}
+	for _, t := range n.taints {
+		changes.Taints = append(changes.Taints, corev1.Taint{Key: t.Key, Value: t.Value, Effect: corev1.TaintEffect(t.Effect)})
+	}
+	for _, t := range n.removeTaints {
+		key, effect, _ := strings.Cut(t, ":")
+		changes.RemoveTaints = append(changes.RemoveTaints, corev1.Taint{Key: key, Effect: corev1.TaintEffect(effect)})
+	}
+	ok := len(changes.Labels)+len(changes.RemoveLabels)+len(changes.Taints)+len(changes.RemoveTaints) > 0
+	return changes, ok
+}
+
+// hostLabelKeys returns the sorted keys of the labels of the host.
+func hostLabelKeys(h *mkeconfig.Host) []string {
+	keys := make([]string, 0, len(h.Labels))
+	for k := range h.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hostTaintKeys returns the sorted key:effect of the taints of the host.
+func hostTaintKeys(h *mkeconfig.Host) []string {
+	keys := make([]string, 0, len(h.Taints))
+	for _, t := range h.Taints {
+		keys = append(keys, t.Key+":"+t.Effect)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// missing returns the items of a that are not in b.
+func missing(a, b []string) []string {
+	var result []string
+	for _, item := range a {
+		found := false
+		for _, other := range b {
+			if item == other {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// reconcileNodeLabels sets the spec.hosts[*].labels and taints on the swarm and
+// Kubernetes nodes of the hosts and removes the ones launchpad set earlier that are no
+// longer in the configuration.
+func (p *LabelNodes) reconcileNodeLabels(ctx context.Context, swarmLeader *mkeconfig.Host) error {
+	nodes, err := swarm.Nodes(swarmLeader)
+	if err != nil {
+		return fmt.Errorf("failed to get swarm nodes: %w", err)
+	}
+
+	var kube *kubeclient.KubeClient
+	labelErrors := &phase.Error{}
+	for _, h := range p.Config.Spec.Hosts {
+		if h.Dropped() {
+			continue
+		}
+		nodeID, err := swarm.NodeID(h)
+		if err != nil {
+			labelErrors.AddHostError(h, err)
+			continue
+		}
+		node := findNode(nodes, nodeID)
+		if node == nil {
+			labelErrors.AddHostError(h, fmt.Errorf("%w: swarm node %s not found", errNodeNotFound, nodeID))
+			continue
+		}
+
+		n := newNodeLabels(h, node.Spec.Labels)
+		changes, kubeChanged := n.kubeChanges()
+		switch {
+		case kubeChanged && !isKubeNode(h, node):
+			log.Warnf("%s: not updating kubernetes node labels and taints, the host is not a kubernetes node", h)
+		case kubeChanged:
+			if kube == nil {
+				if kube, err = p.kubeClient(); err != nil {
+					return err
+				}
+			}
+			log.Infof("%s: updating kubernetes node labels and taints", h)
+			if err := updateKubeNode(ctx, kube, node.Description.Hostname, changes); err != nil {
+				labelErrors.AddHostError(h, err)
+				continue
+			}
+		}
+		if len(n.swarmArgs) == 0 {
+			continue
+		}
+		log.Infof("%s: updating swarm node labels", h)
+		cmd := swarmLeader.Configurer.DockerCommandf("node update %s %s", strings.Join(n.swarmArgs, " "), nodeID)
+		if err := swarmLeader.Exec(cmd); err != nil {
+			labelErrors.AddHostError(h, fmt.Errorf("failed to update swarm node labels: %w", err))
+		}
+	}
+	if labelErrors.Count() > 0 {
+		return labelErrors
+	}
+	return nil
+}
+
+// kubeClient downloads the admin client bundle and returns a client for the MKE
+// Kubernetes API.
+func (p *LabelNodes) kubeClient() (*kubeclient.KubeClient, error) {
+	if err := mke.DownloadBundle(p.Config); err != nil {
+		return nil, fmt.Errorf("failed to download client bundle: %w", err)
+	}
+	bundleDir, err := mke.BundleDir(p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client bundle directory: %w", err)
+	}
+	kube, err := kubeclient.NewFromBundle(bundleDir, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return kube, nil
+}
+
+// isKubeNode returns false for the hosts that never become Kubernetes nodes: the
+// Windows hosts and the nodes MKE has set to the swarm orchestrator only.
+func isKubeNode(h *mkeconfig.Host, node *swarm.Node) bool {
+	if h.IsWindows() {
+		return false
+	}
+	labels := node.Spec.Labels
+	return labels[constant.KubernetesOrchestratorTaint] == "true" || labels[swarmOrchestratorLabel] != "true"
+}
+
+// swarmOrchestratorLabel is the swarm node label MKE sets on the nodes that run swarm
+// workloads.
+const swarmOrchestratorLabel = "com.docker.ucp.orchestrator.swarm"
+
+// updateKubeNode updates the Kubernetes node, retrying the transient errors such as the
+// node not being registered yet after it just joined.
+func updateKubeNode(ctx context.Context, kube *kubeclient.KubeClient, name string, changes kubeclient.NodeChanges) error {
+	err := retry.Periodic(
+		func() error {
+			return kube.UpdateNode(ctx, name, changes)
+		},
+		retrygo.Context(ctx),
+		retrygo.Attempts(20),
+		retrygo.Delay(5*time.Second),
+		retrygo.RetryIf(kubeclient.IsTransient),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update kubernetes node labels and taints: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package phase
+
+import (
+	"testing"
+
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/kubeclient"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	"github.com/k0sproject/rig"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNodeLabels(t *testing.T) {
+	h := &mkeconfig.Host{
+		Labels: map[string]string{"zone": "eu 1", "gpu": "true"},
+		Taints: []mkeconfig.Taint{{Key: "dedicated", Value: "db", Effect: "NoSchedule"}},
+	}
+	n := newNodeLabels(h, map[string]string{
+		"com.mirantis.launchpad.labels": "gpu,old,removed",
+		"com.mirantis.launchpad.taints": "gone:NoExecute",
+		"gpu":                           "true",
+		"old":                           "value",
+		"other":                         "kept",
+	})
+
+	require.Equal(t, []string{"old", "removed"}, n.removeLabels)
+	require.Equal(t, []string{"gone:NoExecute"}, n.removeTaints)
+	require.Equal(t, []string{
+		"--label-rm old",
+		"--label-add 'zone=eu 1'",
+		"--label-add com.mirantis.launchpad.labels=gpu,zone",
+		"--label-add com.mirantis.launchpad.taints=dedicated:NoSchedule",
+	}, n.swarmArgs)
+
+	changes, ok := n.kubeChanges()
+	require.True(t, ok)
+	require.Equal(t, kubeclient.NodeChanges{
+		Labels:       h.Labels,
+		RemoveLabels: []string{"old", "removed"},
+		Taints:       []corev1.Taint{{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule}},
+		RemoveTaints: []corev1.Taint{{Key: "gone", Effect: corev1.TaintEffectNoExecute}},
+	}, changes)
+}
+
+func TestNodeLabelsUnchanged(t *testing.T) {
+	n := newNodeLabels(&mkeconfig.Host{}, map[string]string{"other": "kept"})
+	require.Empty(t, n.swarmArgs)
+	_, ok := n.kubeChanges()
+	require.False(t, ok)
+
+	n = newNodeLabels(&mkeconfig.Host{}, map[string]string{"com.mirantis.launchpad.labels": "zone", "zone": "eu-1"})
+	require.Equal(t, []string{"--label-rm zone", "--label-rm com.mirantis.launchpad.labels"}, n.swarmArgs)
+}
+
+func TestIsKubeNode(t *testing.T) {
+	node := func(labels map[string]string) *swarm.Node {
+		n := &swarm.Node{}
+		n.Spec.Labels = labels
+		return n
+	}
+	h := driftHost("10.0.0.1", "worker", "25.0.8")
+	h.OSVersion = &rig.OSVersion{ID: "ubuntu"}
+
+	require.True(t, isKubeNode(h, node(nil)))
+	require.True(t, isKubeNode(h, node(map[string]string{constant.KubernetesOrchestratorTaint: "true", swarmOrchestratorLabel: "true"})))
+	require.False(t, isKubeNode(h, node(map[string]string{swarmOrchestratorLabel: "true"})))
+
+	h.OSVersion = &rig.OSVersion{ID: "windows"}
+	require.False(t, isKubeNode(h, node(map[string]string{constant.KubernetesOrchestratorTaint: "true"})))
+}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 type OverrideHostSudo struct {
 	phase.Analytics
 	phase.HostSelectPhase
+	phase.ReadOnly
 
 	overrideHosts mkeconfig.Hosts
 }
@@ -35,7 +37,7 @@ func (p *OverrideHostSudo) ShouldRun() bool {
 }
 
 // Run the phase.
-func (p *OverrideHostSudo) Run() error {
+func (p *OverrideHostSudo) Run(_ context.Context) error {
 	err := p.Hosts.ParallelEach(func(h *mkeconfig.Host) error {
 		if h.SudoOverride {
 			log.Warnf("%s: overriding sudo for host", h)
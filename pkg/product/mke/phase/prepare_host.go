@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/msr"
@@ -21,16 +22,16 @@ func (p *PrepareHost) Title() string {
 }
 
 // Run does all the prep work on the hosts in parallel.
-func (p *PrepareHost) Run() error {
-	if err := phase.RunParallelOnHosts(p.Config.Spec.Hosts, p.Config, p.updateEnvironment); err != nil {
+func (p *PrepareHost) Run(ctx context.Context) error {
+	if err := phase.RunParallelOnHosts(ctx, p.Config.Spec.Hosts, p.Config, p.updateEnvironment); err != nil {
 		return fmt.Errorf("failed to update environment variables: %w", err)
 	}
 
-	if err := phase.RunParallelOnHosts(p.Config.Spec.Hosts, p.Config, p.prepareHost); err != nil {
+	if err := phase.RunParallelOnHosts(ctx, p.Config.Spec.Hosts, p.Config, p.prepareHost); err != nil {
 		return fmt.Errorf("failed to install base packages: %w", err)
 	}
 
-	if err := phase.RunParallelOnHosts(p.Config.Spec.Hosts, p.Config, p.authorizeDocker); err != nil {
+	if err := phase.RunParallelOnHosts(ctx, p.Config.Spec.Hosts, p.Config, p.authorizeDocker); err != nil {
 		return fmt.Errorf("failed to authorize docker: %w", err)
 	}
 
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/docker"
@@ -22,6 +23,16 @@ func (p *PullMKEImages) Title() string {
 	return "Pull MKE images"
 }
 
+// ResourceScopes returns the hosts the images are pulled on. The image list is
+// obtained on the swarm leader, which is one of the managers.
+func (p *PullMKEImages) ResourceScopes() []string {
+	if mkeconfig.IsCustomImageRepo(p.Config.Spec.MKE.ImageRepo) {
+		return phase.HostScopes(p.Config.Spec.Hosts)
+	}
+	hosts := p.Config.Spec.Hosts.Filter(func(h *mkeconfig.Host) bool { return h.Role == "manager" || h.IsWindows() })
+	return phase.HostScopes(hosts)
+}
+
 func (p *PullMKEImages) isMKESwarmOnly() bool {
 	for _, flag := range p.Config.Spec.MKE.InstallFlags {
 		if flag == "--swarm-only" {
@@ -33,7 +44,7 @@ func (p *PullMKEImages) isMKESwarmOnly() bool {
 }
 
 // Run pulls images in parallel across nodes via a workerpool of 5.
-func (p *PullMKEImages) Run() error {
+func (p *PullMKEImages) Run(ctx context.Context) error {
 	swarmOnly := p.isMKESwarmOnly()
 
 	images, err := p.ListImages(false, swarmOnly)
@@ -58,7 +69,7 @@ func (p *PullMKEImages) Run() error {
 	if mkeconfig.IsCustomImageRepo(imageRepo) {
 		pullList := docker.AllToRepository(images, imageRepo)
 		pullListWin := docker.AllToRepository(winImages, imageRepo)
-		err := phase.RunParallelOnHosts(p.Config.Spec.Hosts, p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
+		err := phase.RunParallelOnHosts(ctx, p.Config.Spec.Hosts, p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
 			var list []*docker.Image
 
 			if h.IsWindows() {
@@ -83,7 +94,7 @@ func (p *PullMKEImages) Run() error {
 		return nil
 	}
 
-	err = phase.RunParallelOnHosts(p.Config.Spec.Managers(), p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
+	err = phase.RunParallelOnHosts(ctx, p.Config.Spec.Managers(), p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
 		log.Infof("%s: pulling linux images", h)
 		if err := docker.PullImages(h, images); err != nil {
 			return fmt.Errorf("%s: failed to pull linux images: %w", h, err)
@@ -95,7 +106,7 @@ func (p *PullMKEImages) Run() error {
 	}
 
 	if len(winHosts) > 0 {
-		err := phase.RunParallelOnHosts(winHosts, p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
+		err := phase.RunParallelOnHosts(ctx, winHosts, p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
 			log.Infof("%s: pulling windows images", h)
 			if err := docker.PullImages(h, winImages); err != nil {
 				return fmt.Errorf("%s: failed to pull windows images: %w", h, err)
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/docker"
@@ -21,8 +22,16 @@ func (p *PullMSRImages) Title() string {
 	return "Pull MSR images"
 }
 
+// ResourceScopes returns the MSR hosts the images are pulled on.
+func (p *PullMSRImages) ResourceScopes() []string {
+	if mkeconfig.IsCustomImageRepo(p.Config.Spec.MSR.ImageRepo) {
+		return phase.HostScopes(p.Config.Spec.MSRs())
+	}
+	return phase.HostScopes(mkeconfig.Hosts{p.Config.Spec.MSRLeader()})
+}
+
 // Run pulls images in parallel across nodes via a workerpool of 5.
-func (p *PullMSRImages) Run() error {
+func (p *PullMSRImages) Run(ctx context.Context) error {
 	images, err := p.ListImages()
 	if err != nil {
 		return fmt.Errorf("failed to get MSR images list: %w", err)
@@ -33,7 +42,7 @@ func (p *PullMSRImages) Run() error {
 	if mkeconfig.IsCustomImageRepo(imageRepo) {
 		pullList := docker.AllToRepository(images, imageRepo)
 		// In case of custom image repo, we need to pull and retag all the images on all MSR hosts
-		err := phase.RunParallelOnHosts(p.Config.Spec.MSRs(), p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
+		err := phase.RunParallelOnHosts(ctx, p.Config.Spec.MSRs(), p.Config, func(h *mkeconfig.Host, _ *mkeconfig.ClusterConfig) error {
 			if err := docker.PullImages(h, pullList); err != nil {
 				return fmt.Errorf("failed to pull MSR images: %w", err)
 			}
@@ -0,0 +1,91 @@
+package phase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// RebootHosts phase reboots the hosts one manager at a time and the workers in
+// batches, draining their swarm nodes while they are down.
+type RebootHosts struct {
+	phase.Analytics
+	phase.HostSelectPhase
+
+	// Roles and Addresses limit the reboot to the hosts with the roles and addresses,
+	// all the hosts are rebooted when both are empty.
+	Roles       []string
+	Addresses   []string
+	Concurrency int
+}
+
+// Title for the phase.
+func (p *RebootHosts) Title() string {
+	return "Reboot hosts"
+}
+
+// HostFilterFunc returns true for the hosts with the selected roles and addresses.
+func (p *RebootHosts) HostFilterFunc(h *mkeconfig.Host) bool {
+	return hostSelected(h, p.Roles, p.Addresses)
+}
+
+// Prepare collects the hosts.
+func (p *RebootHosts) Prepare(config interface{}) error {
+	cfg, ok := config.(*mkeconfig.ClusterConfig)
+	if !ok {
+		return errInvalidConfig
+	}
+	p.Config = cfg
+	p.Hosts = p.Config.Spec.Hosts.Filter(p.HostFilterFunc)
+	return nil
+}
+
+// PlannedActions lists the hosts to reboot.
+func (p *RebootHosts) PlannedActions() []phase.PlannedAction {
+	actions := make([]phase.PlannedAction, 0, len(p.Hosts))
+	for _, h := range p.Hosts {
+		actions = append(actions, phase.PlannedAction{Host: h.String(), Action: "drain, reboot and activate"})
+	}
+	return actions
+}
+
+// Run reboots the hosts.
+func (p *RebootHosts) Run(ctx context.Context) error {
+	p.EventProperties = map[string]interface{}{
+		"hosts": len(p.Hosts),
+	}
+	r := &rollout{
+		config:      p.Config,
+		concurrency: p.Concurrency,
+		drain:       true,
+		action: func(_ context.Context, h *mkeconfig.Host) error {
+			return rebootHost(h)
+		},
+	}
+	return r.run(ctx, p.Hosts)
+}
+
+// hostSelected returns true when the host is not dropped and matches the roles and
+// addresses, an empty list matches every host.
+func hostSelected(h *mkeconfig.Host, roles, addresses []string) bool {
+	if h.Dropped() {
+		return false
+	}
+	if len(roles) > 0 && !slices.Contains(roles, h.Role) {
+		return false
+	}
+	return len(addresses) == 0 || slices.Contains(addresses, h.Address())
+}
+
+// rebootHost reboots the host with its configurer and waits for it to come back.
+func rebootHost(h *mkeconfig.Host) error {
+	if err := h.Reboot(); err != nil {
+		return fmt.Errorf("failed to reboot: %w", err)
+	}
+	log.Infof("%s: rebooted", h)
+	return nil
+}
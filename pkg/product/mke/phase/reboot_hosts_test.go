@@ -0,0 +1,62 @@
+package phase
+
+import (
+	"testing"
+
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebootHostsSelection(t *testing.T) {
+	config := &mkeconfig.ClusterConfig{
+		Spec: &mkeconfig.ClusterSpec{
+			Hosts: mkeconfig.Hosts{
+				roleHost("10.0.0.1", "manager", "manager"),
+				roleHost("10.0.0.2", "worker", "worker"),
+				roleHost("10.0.0.3", "worker", "worker"),
+				roleHost("10.0.0.4", "msr", "worker"),
+			},
+		},
+	}
+	addresses := func(p *RebootHosts) []string {
+		require.NoError(t, p.Prepare(config))
+		var result []string
+		for _, h := range p.Hosts {
+			result = append(result, h.Address())
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}, addresses(&RebootHosts{}))
+	require.Equal(t, []string{"10.0.0.2", "10.0.0.3", "10.0.0.4"}, addresses(&RebootHosts{Roles: []string{"worker", "msr"}}))
+	require.Equal(t, []string{"10.0.0.3"}, addresses(&RebootHosts{Addresses: []string{"10.0.0.3", "10.0.0.1"}, Roles: []string{"worker"}}))
+}
+
+func TestRolloutOtherManager(t *testing.T) {
+	m1 := roleHost("10.0.0.1", "manager", "manager")
+	m2 := roleHost("10.0.0.2", "manager", "manager")
+	m3 := roleHost("10.0.0.3", "manager", "worker")
+	r := &rollout{config: &mkeconfig.ClusterConfig{Spec: &mkeconfig.ClusterSpec{Hosts: mkeconfig.Hosts{m1, m2, m3}}}}
+
+	require.Same(t, m2, r.otherManager(m1))
+	require.Same(t, m1, r.otherManager(m2))
+	require.Same(t, m1, r.otherManager(m3))
+
+	r.config.Spec.Hosts = mkeconfig.Hosts{m1}
+	require.Same(t, m1, r.otherManager(m1))
+}
+
+func TestRolloutMKEManagers(t *testing.T) {
+	joined := roleHost("10.0.0.1", "manager", "manager")
+	unjoined := roleHost("10.0.0.2", "manager", "")
+	promoted := roleHost("10.0.0.3", "manager", "worker")
+	r := &rollout{config: &mkeconfig.ClusterConfig{Spec: &mkeconfig.ClusterSpec{
+		Hosts: mkeconfig.Hosts{joined, unjoined, promoted, roleHost("10.0.0.4", "worker", "worker")},
+		MKE:   mkeconfig.MKEConfig{Metadata: &mkeconfig.MKEMetadata{Installed: true}},
+	}}}
+
+	require.Equal(t, mkeconfig.Hosts{joined}, r.mkeManagers())
+
+	r.config.Spec.MKE.Metadata.Installed = false
+	require.Empty(t, r.mkeManagers())
+}
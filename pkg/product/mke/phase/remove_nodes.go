@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Mirantis/launchpad/pkg/constant"
 	"github.com/Mirantis/launchpad/pkg/mke"
 	"github.com/Mirantis/launchpad/pkg/msr"
 	"github.com/Mirantis/launchpad/pkg/phase"
@@ -27,9 +29,20 @@ type RemoveNodes struct {
 	phase.BasicPhase
 	phase.CleanupDisabling
 
+	// Force allows removing more managers than the quorum tolerates.
+	Force bool
+
+	// dropped is set when hosts have been dropped from the run, their swarm nodes
+	// can't be told apart from the nodes to remove.
+	dropped bool
+
 	cleanupMSRs   []*mkeconfig.Host
 	msrReplicaIDs []string
 	removeNodeIDs []string
+	// managers is the number of swarm managers and removeManagers the number of them
+	// to be removed.
+	managers       int
+	removeManagers int
 }
 
 type isManaged struct {
@@ -68,6 +81,9 @@ func (p *RemoveNodes) ShouldRun() bool {
 		log.Warnf("There are nodes present which are not present in configuration Spec.Hosts - to remove them, set Spec.Cluster.Prune to true")
 	}
 
+	if p.dropped {
+		return false
+	}
 	return p.Config.Spec.Cluster.Prune
 }
 
@@ -79,6 +95,12 @@ func (p *RemoveNodes) Prepare(config interface{}) error {
 	}
 	p.Config = cfg
 
+	if p.Config.Spec.Hosts.Include(func(h *mkeconfig.Host) bool { return h.Dropped() }) {
+		log.Warnf("not looking for nodes to remove as hosts have been dropped from the run")
+		p.dropped = true
+		return nil
+	}
+
 	swarmLeader := p.Config.Spec.SwarmLeader()
 
 	nodeIDs, err := p.currentNodeIDs(p.Config)
@@ -124,11 +146,79 @@ func (p *RemoveNodes) Prepare(config interface{}) error {
 			p.removeNodeIDs = append(p.removeNodeIDs, nodeID)
 		}
 	}
+
+	nodes, err := swarm.Nodes(swarmLeader)
+	if err != nil {
+		return fmt.Errorf("failed to get swarm nodes: %w", err)
+	}
+	p.managers, p.removeManagers = managerRemovals(nodes, nodeIDs)
+	return nil
+}
+
+var errQuorum = errors.New("removing the nodes would break the manager quorum")
+
+// managerRemovals returns the number of swarm managers and the number of them pruning
+// would remove, the managers launchpad manages that are not among the node IDs of the
+// configured hosts.
+func managerRemovals(nodes []swarm.Node, nodeIDs []string) (int, int) {
+	var managers, remove int
+	for _, n := range nodes {
+		if n.Spec.Role != "manager" {
+			continue
+		}
+		managers++
+		if n.Spec.Labels[constant.ManagedLabel] == "true" && !stringutil.StringSliceContains(nodeIDs, n.ID) {
+			remove++
+		}
+	}
+	return managers, remove
+}
+
+// checkManagerQuorum refuses to remove more managers than the swarm tolerates losing,
+// which is less than half of the managers.
+func checkManagerQuorum(managers, remove int) error {
+	if tolerated := (managers - 1) / 2; remove > tolerated {
+		return fmt.Errorf("%w: %d of the %d managers would be removed, at most %d can be removed safely (use --force to remove them anyway)", errQuorum, remove, managers, tolerated)
+	}
 	return nil
 }
 
+// PlannedActions lists the nodes that would be pruned.
+func (p *RemoveNodes) PlannedActions() []phase.PlannedAction {
+	swarmLeader := p.Config.Spec.SwarmLeader()
+	actions := make([]phase.PlannedAction, 0, len(p.msrReplicaIDs)+len(p.removeNodeIDs)+1)
+	if err := checkManagerQuorum(p.managers, p.removeManagers); err != nil && !p.Force {
+		return append(actions, phase.PlannedAction{Action: "refuse to remove nodes: " + err.Error()})
+	}
+	for _, replicaID := range p.msrReplicaIDs {
+		actions = append(actions, phase.PlannedAction{Action: fmt.Sprintf("remove MSR replica %s", replicaID)})
+	}
+	for _, nodeID := range p.removeNodeIDs {
+		node := nodeID
+		if addr, err := swarmLeader.ExecOutput(swarmLeader.Configurer.DockerCommandf(`node inspect %s --format {{.Status.Addr}}`, nodeID)); err == nil {
+			node = fmt.Sprintf("%s (%s)", addr, nodeID)
+		}
+		actions = append(actions, phase.PlannedAction{Action: fmt.Sprintf("remove orphan node %s", node)})
+	}
+	return actions
+}
+
 // Run removes all nodes from swarm that are labeled and not part of the current config.
-func (p *RemoveNodes) Run() error {
+func (p *RemoveNodes) Run(ctx context.Context) error {
+	if p.removeManagers > 0 {
+		remain := p.managers - p.removeManagers
+		log.Infof("removing %d of the %d swarm managers, %d managers will remain", p.removeManagers, p.managers, remain)
+		if err := checkManagerQuorum(p.managers, p.removeManagers); err != nil {
+			if !p.Force {
+				return err
+			}
+			log.Warnf("%s: continuing anyway because --force given, the swarm may lose its quorum and become unmanageable", err)
+		}
+		if remain%2 == 0 {
+			log.Warnf("%d managers will remain, an odd number of managers is recommended", remain)
+		}
+	}
+
 	swarmLeader := p.Config.Spec.SwarmLeader()
 	if len(p.cleanupMSRs) > 0 {
 		err := msr.Cleanup(p.cleanupMSRs, swarmLeader, p.Config)
@@ -147,7 +237,7 @@ func (p *RemoveNodes) Run() error {
 	}
 	if len(p.removeNodeIDs) > 0 {
 		for _, nodeID := range p.removeNodeIDs {
-			err := p.removeNode(swarmLeader, nodeID)
+			err := p.removeNode(ctx, swarmLeader, nodeID)
 			if err != nil {
 				return err
 			}
@@ -178,7 +268,9 @@ func (p *RemoveNodes) swarmNodeIDs(h *mkeconfig.Host) ([]string, error) {
 	return strings.Split(output, "\n"), nil
 }
 
-func (p *RemoveNodes) removeNode(h *mkeconfig.Host, nodeID string) error {
+// removeNode demotes, drains and removes the node. Managers are demoted first and the
+// swarm managers must be healthy before the node is removed and again after it.
+func (p *RemoveNodes) removeNode(ctx context.Context, h *mkeconfig.Host, nodeID string) error {
 	nodeAddr, err := h.ExecOutput(h.Configurer.DockerCommandf(`node inspect %s --format {{.Status.Addr}}`, nodeID))
 	if err != nil {
 		return fmt.Errorf("failed to get node address for node %s: %w", nodeID, err)
@@ -194,6 +286,9 @@ func (p *RemoveNodes) removeNode(h *mkeconfig.Host, nodeID string) error {
 			return fmt.Errorf("failed to demote node %s: %w", nodeID, err)
 		}
 		log.Infof("%s: orphan node %s demoted", h, nodeAddr)
+		if err := waitSwarmManagers(ctx, h); err != nil {
+			return fmt.Errorf("failed to remove node %s: %w", nodeID, err)
+		}
 	}
 
 	log.Infof("%s: draining orphan node %s", h, nodeAddr)
@@ -210,6 +305,11 @@ func (p *RemoveNodes) removeNode(h *mkeconfig.Host, nodeID string) error {
 		return fmt.Errorf("failed to remove node %s: %w", nodeID, err)
 	}
 	log.Infof("%s: removed orphan node %s", h, nodeAddr)
+	if nodeRole == "manager" {
+		if err := waitSwarmManagers(ctx, h); err != nil {
+			return fmt.Errorf("after removing node %s: %w", nodeID, err)
+		}
+	}
 	return nil
 }
 
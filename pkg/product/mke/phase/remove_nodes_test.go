@@ -0,0 +1,51 @@
+package phase
+
+import (
+	"testing"
+
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckManagerQuorum(t *testing.T) {
+	require.NoError(t, checkManagerQuorum(5, 0))
+	require.NoError(t, checkManagerQuorum(5, 2))
+	require.NoError(t, checkManagerQuorum(3, 1))
+	require.NoError(t, checkManagerQuorum(1, 0))
+
+	err := checkManagerQuorum(5, 3)
+	require.ErrorIs(t, err, errQuorum)
+	require.ErrorContains(t, err, "3 of the 5 managers would be removed, at most 2")
+	require.ErrorIs(t, checkManagerQuorum(4, 2), errQuorum)
+	require.ErrorIs(t, checkManagerQuorum(2, 1), errQuorum)
+	require.ErrorIs(t, checkManagerQuorum(1, 1), errQuorum)
+}
+
+func TestManagerRemovals(t *testing.T) {
+	node := func(id, role string, managed bool) swarm.Node {
+		var n swarm.Node
+		n.ID = id
+		n.Spec.Role = role
+		if managed {
+			n.Spec.Labels = map[string]string{constant.ManagedLabel: "true"}
+		}
+		return n
+	}
+	nodes := []swarm.Node{
+		node("m1", "manager", true),
+		node("m2", "manager", true),
+		node("m3", "manager", true),
+		node("m4", "manager", false),
+		node("w1", "worker", true),
+	}
+
+	managers, remove := managerRemovals(nodes, []string{"m1", "m2", "m3", "w1"})
+	require.Equal(t, 4, managers)
+	require.Equal(t, 0, remove)
+
+	// m4 is not managed by launchpad and w1 is not a manager
+	managers, remove = managerRemovals(nodes, []string{"m1"})
+	require.Equal(t, 4, managers)
+	require.Equal(t, 2, remove)
+}
@@ -0,0 +1,94 @@
+package phase
+
+// The Requires methods list the phases a phase relies on. The phase manager refuses to
+// run a phase without the phases it requires unless forced. Most of the phases rely on
+// the host facts, which in turn need the hosts to be connected and their OS detected.
+
+// Requires returns the phases DetectOS needs.
+func (p *DetectOS) Requires() []string { return []string{"Connect"} }
+
+// Requires returns the phases GatherFacts needs.
+func (p *GatherFacts) Requires() []string { return []string{"DetectOS"} }
+
+// Requires returns the phases ValidateFacts needs.
+func (p *ValidateFacts) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases ValidateHosts needs.
+func (p *ValidateHosts) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases PrepareHost needs.
+func (p *PrepareHost) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases ConfigureMCR needs.
+func (p *ConfigureMCR) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases InstallMCR needs.
+func (p *InstallMCR) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases UpgradeMCR needs.
+func (p *UpgradeMCR) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases InstallMCRLicense needs.
+func (p *InstallMCRLicense) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases RestartMCR needs.
+func (p *RestartMCR) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases LoadImages needs.
+func (p *LoadImages) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases AuthenticateDocker needs.
+func (p *AuthenticateDocker) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases PullMKEImages needs.
+func (p *PullMKEImages) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases InitSwarm needs.
+func (p *InitSwarm) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases InstallMKECerts needs.
+func (p *InstallMKECerts) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases InstallMKE needs.
+func (p *InstallMKE) Requires() []string { return []string{"GatherFacts", "InitSwarm"} }
+
+// Requires returns the phases UpgradeMKE needs.
+func (p *UpgradeMKE) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases JoinManagers needs. The swarm join tokens are
+// collected by InitSwarm.
+func (p *JoinManagers) Requires() []string { return []string{"GatherFacts", "InitSwarm"} }
+
+// Requires returns the phases JoinWorkers needs. The swarm join tokens are
+// collected by InitSwarm.
+func (p *JoinWorkers) Requires() []string { return []string{"GatherFacts", "InitSwarm"} }
+
+// Requires returns the phases ChangeRoles needs.
+func (p *ChangeRoles) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases MSR phases need.
+func (p *MSRPhase) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases ValidateMKEHealth needs.
+func (p *ValidateMKEHealth) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases JoinMSRReplicas needs.
+func (p *JoinMSRReplicas) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases LabelNodes needs.
+func (p *LabelNodes) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases RemoveNodes needs.
+func (p *RemoveNodes) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases Info needs.
+func (p *Info) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases UninstallMKE needs.
+func (p *UninstallMKE) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases UninstallMCR needs.
+func (p *UninstallMCR) Requires() []string { return []string{"GatherFacts"} }
+
+// Requires returns the phases CleanUp needs.
+func (p *CleanUp) Requires() []string { return []string{"GatherFacts"} }
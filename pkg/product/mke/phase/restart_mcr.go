@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sync"
@@ -19,7 +20,7 @@ type RestartMCR struct {
 
 // HostFilterFunc returns true for hosts that need their engine to be restarted.
 func (p *RestartMCR) HostFilterFunc(h *mkeconfig.Host) bool {
-	return h.Metadata.MCRRestartRequired
+	return !h.Dropped() && h.Metadata.MCRRestartRequired
 }
 
 // Prepare collects the hosts.
@@ -42,7 +43,7 @@ func (p *RestartMCR) Title() string {
 }
 
 // Run installs the engine on each host.
-func (p *RestartMCR) Run() error {
+func (p *RestartMCR) Run(_ context.Context) error {
 	p.EventProperties = map[string]interface{}{
 		"engine_channel": p.Config.Spec.MCR.Channel,
 	}
@@ -0,0 +1,226 @@
+package phase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/mcr"
+	"github.com/Mirantis/launchpad/pkg/msr"
+	"github.com/Mirantis/launchpad/pkg/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/retry"
+	"github.com/Mirantis/launchpad/pkg/swarm"
+	retrygo "github.com/avast/retry-go"
+	"github.com/gammazero/workerpool"
+	log "github.com/sirupsen/logrus"
+)
+
+// rollout runs a disruptive action, such as a reboot or an MCR upgrade, on the hosts.
+// The managers go one at a time and the swarm managers and MKE must be healthy before
+// the next one, then the MSR replicas one at a time with the replica ready before the
+// next one, and then the workers in batches of concurrency.
+type rollout struct {
+	config      *mkeconfig.ClusterConfig
+	concurrency int
+	// drain drains the swarm node of each host for the action and activates it again.
+	drain bool
+	// action is run on each host, while its node is drained when drain is set.
+	action func(ctx context.Context, h *mkeconfig.Host) error
+
+	// availability is the availability of the swarm nodes by their ID before the rollout,
+	// only the nodes that were active are drained and activated.
+	availability map[string]string
+}
+
+func (r *rollout) run(ctx context.Context, hosts mkeconfig.Hosts) error {
+	var managers, msrs, workers mkeconfig.Hosts
+	for _, h := range hosts {
+		switch h.Role {
+		case "manager":
+			managers = append(managers, h)
+		case "msr":
+			msrs = append(msrs, h)
+		case "worker":
+			workers = append(workers, h)
+		default:
+			return fmt.Errorf("%s: %w: %s", h, errUnknownRole, h.Role)
+		}
+	}
+
+	swarmLeader := r.config.Spec.SwarmLeader()
+	r.availability = make(map[string]string)
+	if r.drain && r.inSwarm(swarmLeader) {
+		nodes, err := swarm.Nodes(swarmLeader)
+		if err != nil {
+			return fmt.Errorf("failed to get swarm nodes: %w", err)
+		}
+		for _, n := range nodes {
+			r.availability[n.ID] = n.Spec.Availability
+		}
+	}
+
+	for _, h := range managers {
+		lead := r.otherManager(h)
+		if r.inSwarm(lead) {
+			if err := waitSwarmManagers(ctx, lead); err != nil {
+				return fmt.Errorf("%s: %w", h, err)
+			}
+		}
+		if err := r.runOnHost(ctx, lead, h); err != nil {
+			return err
+		}
+		if r.inSwarm(lead) {
+			if err := waitSwarmManagers(ctx, lead); err != nil {
+				return fmt.Errorf("%s: %w", h, err)
+			}
+		}
+		if managers := r.mkeManagers(); len(managers) > 0 {
+			if err := r.config.Spec.CheckMKEHealthLocal(managers); err != nil {
+				return fmt.Errorf("%s: %w", h, err)
+			}
+		}
+	}
+
+	port := msrReplicaPort(r.config)
+	for _, h := range msrs {
+		installed := mkeconfig.IsMSRInstalled(h)
+		if installed {
+			if err := msr.WaitMSRNodeReady(h, port); err != nil {
+				return fmt.Errorf("%s: check msr node ready state: %w", h, err)
+			}
+		}
+		if err := r.runOnHost(ctx, swarmLeader, h); err != nil {
+			return err
+		}
+		if installed {
+			if err := waitMSRReplica(ctx, h, port); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Debugf("running on workers in batches of %d", r.concurrency)
+	pool := workerpool.New(r.concurrency)
+	mu := sync.Mutex{}
+	workerErrors := &phase.Error{}
+	for _, w := range workers {
+		h := w
+		pool.Submit(func() {
+			if err := r.runOnHost(ctx, swarmLeader, h); err != nil {
+				mu.Lock()
+				workerErrors.AddHostError(h, err)
+				mu.Unlock()
+			}
+		})
+	}
+	pool.StopWait()
+	if workerErrors.Count() > 0 {
+		return workerErrors
+	}
+	return nil
+}
+
+// runOnHost drains the node of the host through lead when drain is set, runs the
+// action, waits for MCR and activates the node again.
+func (r *rollout) runOnHost(ctx context.Context, lead, h *mkeconfig.Host) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", h, err)
+	}
+
+	active := false
+	if r.drain && r.inSwarm(h) {
+		nodeID, err := swarm.NodeID(h)
+		if err != nil {
+			return fmt.Errorf("%s: %w", h, err)
+		}
+		switch availability := r.availability[nodeID]; availability {
+		case "active":
+			active = true
+			if err := mcr.DrainNode(lead, h); err != nil {
+				return err
+			}
+		default:
+			log.Infof("%s: node availability is %s, leaving it as is", h, availability)
+		}
+	}
+
+	if err := r.action(ctx, h); err != nil {
+		return fmt.Errorf("%s: %w", h, err)
+	}
+
+	if h.Metadata != nil && h.Metadata.MCRVersion != "" {
+		log.Infof("%s: waiting for the container runtime", h)
+		err := retry.Periodic(
+			func() error {
+				return mcr.EnsureMCRRunning(h, r.config.Spec.MCR)
+			},
+			retrygo.Context(ctx),
+			retrygo.Delay(5*time.Second),
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", h, err)
+		}
+	}
+
+	if active {
+		if err := mcr.ActivateNode(lead, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitMSRReplica waits for the MSR replica on the host to be ready and for its facts to
+// be collectable again after the action.
+func waitMSRReplica(ctx context.Context, h *mkeconfig.Host, port int) error {
+	if err := msr.WaitMSRNodeReady(h, port); err != nil {
+		return fmt.Errorf("%s: check msr node ready state: %w", h, err)
+	}
+	err := retrygo.Do(
+		func() error {
+			if _, err := msr.CollectFacts(h); err != nil {
+				return fmt.Errorf("%s: collect msr facts: %w", h, err)
+			}
+			return nil
+		},
+		retrygo.DelayType(retrygo.CombineDelay(retrygo.FixedDelay, retrygo.RandomDelay)),
+		retrygo.MaxJitter(time.Second*2),
+		retrygo.Delay(time.Second*5),
+		retrygo.Attempts(3),
+		retrygo.Context(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("retry count exceeded: %w", err)
+	}
+	return nil
+}
+
+// otherManager returns a swarm manager other than the host to run the swarm commands
+// on while the host is down, or the host itself when it is the only manager.
+func (r *rollout) otherManager(h *mkeconfig.Host) *mkeconfig.Host {
+	for _, m := range r.config.Spec.Managers() {
+		if m != h && r.inSwarm(m) && m.Metadata.SwarmRole == "manager" {
+			return m
+		}
+	}
+	return h
+}
+
+func (r *rollout) inSwarm(h *mkeconfig.Host) bool {
+	return h.Metadata != nil && h.Metadata.SwarmRole != ""
+}
+
+// mkeManagers returns the managers that run an MKE controller, the swarm managers of a
+// cluster with MKE installed. The managers that are not joined yet and the workers that
+// are to be promoted to managers have none until JoinManagers and ChangeRoles run.
+func (r *rollout) mkeManagers() mkeconfig.Hosts {
+	if r.config.Spec.MKE.Metadata == nil || !r.config.Spec.MKE.Metadata.Installed {
+		return nil
+	}
+	managers := r.config.Spec.Managers()
+	return managers.Filter(func(h *mkeconfig.Host) bool {
+		return r.inSwarm(h) && h.Metadata.SwarmRole == "manager"
+	})
+}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/mcr"
@@ -21,7 +22,7 @@ func (p *UninstallMCR) Title() string {
 }
 
 // Run installs the engine on each host.
-func (p *UninstallMCR) Run() error {
+func (p *UninstallMCR) Run(ctx context.Context) error {
 	workers := p.Config.Spec.Workers()
 	managers := p.Config.Spec.Managers()
 	swarmLeader := p.Config.Spec.SwarmLeader()
@@ -48,7 +49,7 @@ func (p *UninstallMCR) Run() error {
 		return fmt.Errorf("%s: drain leader node: %w", swarmLeader, err)
 	}
 
-	if err := phase.RunParallelOnHosts(p.Config.Spec.Hosts, p.Config, p.uninstallMCR); err != nil {
+	if err := phase.RunParallelOnHosts(ctx, p.Config.Spec.Hosts, p.Config, p.uninstallMCR); err != nil {
 		return fmt.Errorf("uninstall container runtime: %w", err)
 	}
 	return nil
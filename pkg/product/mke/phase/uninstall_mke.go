@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -25,7 +26,7 @@ func (p *UninstallMKE) Title() string {
 }
 
 // Run the installer container.
-func (p *UninstallMKE) Run() error {
+func (p *UninstallMKE) Run(_ context.Context) error {
 	leader := p.Config.Spec.SwarmLeader()
 	if !p.Config.Spec.MKE.Metadata.Installed {
 		log.Infof("%s: MKE is not installed, skipping", leader)
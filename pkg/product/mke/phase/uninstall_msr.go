@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/msr"
@@ -21,7 +22,7 @@ func (p *UninstallMSR) Title() string {
 }
 
 // Run an uninstall via msr.Cleanup.
-func (p *UninstallMSR) Run() error {
+func (p *UninstallMSR) Run(_ context.Context) error {
 	swarmLeader := p.Config.Spec.SwarmLeader()
 	msrLeader := p.Config.Spec.MSRLeader()
 	if msrLeader == nil || !msrLeader.MSRMetadata.Installed {
@@ -0,0 +1,116 @@
+package phase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// UpdateOS phase upgrades the OS packages of the hosts with their package manager, or
+// Windows Update, and reboots the hosts that need it. The hosts go through the same
+// rollout as RebootHosts, one manager at a time and the workers in batches, with their
+// swarm nodes drained.
+type UpdateOS struct {
+	phase.Analytics
+	phase.HostSelectPhase
+
+	// Roles and Addresses limit the update to the hosts with the roles and addresses,
+	// all the hosts are updated when both are empty.
+	Roles       []string
+	Addresses   []string
+	Concurrency int
+	// IncludeMCR lets the package manager upgrade the MCR packages, which are held back
+	// by default.
+	IncludeMCR bool
+	Report     *phase.OSUpdateReport
+}
+
+// Title for the phase.
+func (p *UpdateOS) Title() string {
+	return "Update OS packages"
+}
+
+// HostFilterFunc returns true for the hosts with the selected roles and addresses.
+func (p *UpdateOS) HostFilterFunc(h *mkeconfig.Host) bool {
+	return hostSelected(h, p.Roles, p.Addresses)
+}
+
+// Prepare collects the hosts.
+func (p *UpdateOS) Prepare(config interface{}) error {
+	cfg, ok := config.(*mkeconfig.ClusterConfig)
+	if !ok {
+		return errInvalidConfig
+	}
+	p.Config = cfg
+	p.Hosts = p.Config.Spec.Hosts.Filter(p.HostFilterFunc)
+	return nil
+}
+
+// PlannedActions lists the hosts to update.
+func (p *UpdateOS) PlannedActions() []phase.PlannedAction {
+	action := "drain, update OS packages holding back MCR, reboot if required and activate"
+	if p.IncludeMCR {
+		action = "drain, update OS packages including MCR, reboot if required and activate"
+	}
+	actions := make([]phase.PlannedAction, 0, len(p.Hosts))
+	for _, h := range p.Hosts {
+		actions = append(actions, phase.PlannedAction{Host: h.String(), Action: action})
+	}
+	return actions
+}
+
+// Run updates the hosts.
+func (p *UpdateOS) Run(ctx context.Context) error {
+	p.EventProperties = map[string]interface{}{
+		"hosts":       len(p.Hosts),
+		"include_mcr": p.IncludeMCR,
+	}
+	if p.Report == nil {
+		p.Report = &phase.OSUpdateReport{}
+	}
+	r := &rollout{
+		config:      p.Config,
+		concurrency: p.Concurrency,
+		drain:       true,
+		action:      p.updateHost,
+	}
+	return r.run(ctx, p.Hosts)
+}
+
+// updateHost upgrades the packages of the host, reboots it when the OS requires it and
+// adds the outcome to the report. A failed host is added to the report with the error
+// and the packages changed before the failure.
+func (p *UpdateOS) updateHost(_ context.Context, h *mkeconfig.Host) error {
+	update := phase.HostUpdate{Host: h.String(), Role: h.Role}
+	fail := func(err error) error {
+		update.Error = err.Error()
+		p.Report.Add(update)
+		return err
+	}
+
+	log.Infof("%s: updating OS packages", h)
+	packages, err := h.Configurer.UpdatePackages(h, !p.IncludeMCR)
+	update.Packages = packages
+	if err != nil {
+		return fail(fmt.Errorf("failed to update OS packages: %w", err))
+	}
+	log.Infof("%s: %d packages changed", h, len(packages))
+
+	reboot, err := h.Configurer.RebootRequired(h)
+	if err != nil {
+		return fail(fmt.Errorf("failed to check if a reboot is required: %w", err))
+	}
+	if reboot {
+		log.Infof("%s: the OS requires a reboot", h)
+		if err := rebootHost(h); err != nil {
+			return fail(err)
+		}
+		update.Rebooted = true
+	}
+
+	p.Report.Add(update)
+	return nil
+}
@@ -0,0 +1,69 @@
+package phase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/k0sproject/rig/os"
+	"github.com/stretchr/testify/require"
+)
+
+type updateConfigurer struct {
+	mkeconfig.HostConfigurer
+
+	holdMCR bool
+	err     error
+}
+
+func (c *updateConfigurer) UpdatePackages(_ os.Host, holdMCR bool) ([]string, error) {
+	c.holdMCR = holdMCR
+	return []string{"openssl 3.0.2 -> 3.0.3"}, c.err
+}
+
+func (c *updateConfigurer) RebootRequired(os.Host) (bool, error) {
+	return false, nil
+}
+
+func TestUpdateOSHost(t *testing.T) {
+	h := roleHost("10.0.0.2", "worker", "worker")
+	configurer := &updateConfigurer{}
+	h.Configurer = configurer
+
+	p := &UpdateOS{Report: &phase.OSUpdateReport{}}
+	require.NoError(t, p.updateHost(context.Background(), h))
+	require.True(t, configurer.holdMCR)
+	require.Equal(t, []phase.HostUpdate{{Host: h.String(), Role: "worker", Packages: []string{"openssl 3.0.2 -> 3.0.3"}}}, p.Report.Hosts)
+
+	p.IncludeMCR = true
+	require.NoError(t, p.updateHost(context.Background(), h))
+	require.False(t, configurer.holdMCR)
+}
+
+func TestUpdateOSHostFailed(t *testing.T) {
+	h := roleHost("10.0.0.2", "worker", "worker")
+	h.Configurer = &updateConfigurer{err: errors.New("windows update failed: only some of the updates were installed")}
+
+	p := &UpdateOS{Report: &phase.OSUpdateReport{}}
+	require.Error(t, p.updateHost(context.Background(), h))
+	require.Len(t, p.Report.Hosts, 1)
+	require.Equal(t, []string{"openssl 3.0.2 -> 3.0.3"}, p.Report.Hosts[0].Packages)
+	require.Equal(t, "failed to update OS packages: windows update failed: only some of the updates were installed", p.Report.Hosts[0].Error)
+}
+
+func TestUpdateOSSelection(t *testing.T) {
+	config := &mkeconfig.ClusterConfig{
+		Spec: &mkeconfig.ClusterSpec{
+			Hosts: mkeconfig.Hosts{
+				roleHost("10.0.0.1", "manager", "manager"),
+				roleHost("10.0.0.2", "worker", "worker"),
+			},
+		},
+	}
+	p := &UpdateOS{Roles: []string{"worker"}}
+	require.NoError(t, p.Prepare(config))
+	require.Len(t, p.Hosts, 1)
+	require.Equal(t, []phase.PlannedAction{{Host: p.Hosts[0].String(), Action: "drain, update OS packages holding back MCR, reboot if required and activate"}}, p.PlannedActions())
+}
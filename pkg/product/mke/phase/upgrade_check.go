@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -17,6 +18,7 @@ var DisableUpgradeCheck = false
 type UpgradeCheck struct {
 	phase.Analytics
 	phase.BasicPhase
+	phase.ReadOnly
 }
 
 // Title prints the phase title.
@@ -30,7 +32,7 @@ func (p *UpgradeCheck) ShouldRun() bool {
 }
 
 // Run the installer container.
-func (p *UpgradeCheck) Run() (err error) {
+func (p *UpgradeCheck) Run(_ context.Context) (err error) {
 	mkeTag, err := hub.LatestTag("mirantis", "ucp", strings.Contains(p.Config.Spec.MKE.Version, "-"))
 	if err != nil {
 		log.Errorf("failed to check for MKE upgrade: %v", err)
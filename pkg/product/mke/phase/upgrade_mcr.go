@@ -1,18 +1,14 @@
 package phase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
-	"sync"
-	"time"
 
 	"github.com/Mirantis/launchpad/pkg/mcr"
-	"github.com/Mirantis/launchpad/pkg/msr"
 	"github.com/Mirantis/launchpad/pkg/phase"
 	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
-	retry "github.com/avast/retry-go"
-	"github.com/gammazero/workerpool"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -27,6 +23,9 @@ type UpgradeMCR struct {
 
 // HostFilterFunc returns true for hosts that do not have engine installed.
 func (p *UpgradeMCR) HostFilterFunc(h *mkeconfig.Host) bool {
+	if h.Dropped() {
+		return false
+	}
 	if h.Metadata.MCRInstalled {
 		// we just did an install, no need to run an upgrade
 		return false
@@ -59,121 +58,69 @@ func (p *UpgradeMCR) Prepare(config interface{}) error {
 	return nil
 }
 
+// PlannedActions lists the hosts that will get the container runtime upgraded. Hosts
+// without an existing installation are left out as they are handled by InstallMCR.
+func (p *UpgradeMCR) PlannedActions() []phase.PlannedAction {
+	var actions []phase.PlannedAction
+	for _, h := range p.Hosts {
+		if h.Metadata.MCRVersion == "" {
+			continue
+		}
+		actions = append(actions, phase.PlannedAction{Host: h.String(), Action: fmt.Sprintf("upgrade MCR %s (channel %s)", h.Metadata.MCRVersion, p.Config.Spec.MCR.Channel)})
+	}
+	return actions
+}
+
 // Title for the phase.
 func (p *UpgradeMCR) Title() string {
 	return "Upgrade Mirantis Container Runtime on the hosts"
 }
 
 // Run installs the engine on each host.
-func (p *UpgradeMCR) Run() error {
+func (p *UpgradeMCR) Run(ctx context.Context) error {
 	p.EventProperties = map[string]interface{}{
 		"engine_channel": p.Config.Spec.MCR.Channel,
 	}
-	return p.upgradeMCRs()
+	return p.upgradeMCRs(ctx)
 }
 
 var errUnknownRole = errors.New("unknown role")
 
-// Upgrades host docker engines, first managers (one-by-one) and then ~10% rolling update to workers
-// TODO: should we drain?
-func (p *UpgradeMCR) upgradeMCRs() error {
-	var managers mkeconfig.Hosts
-	var workers mkeconfig.Hosts
-	var msrs mkeconfig.Hosts
-	for _, h := range p.Hosts {
-		switch h.Role {
-		case "manager":
-			managers = append(managers, h)
-		case "worker":
-			workers = append(workers, h)
-		case "msr":
-			msrs = append(msrs, h)
-		default:
-			return fmt.Errorf("%s: %w: %s", h, errUnknownRole, h.Role)
-		}
+// upgradeMCRs upgrades the container runtime of the hosts with the same rollout as the
+// reboots, without draining the nodes: the managers one at a time with the swarm
+// managers and MKE healthy before the next one, the MSR replicas one at a time and the
+// workers in batches.
+func (p *UpgradeMCR) upgradeMCRs(ctx context.Context) error {
+	r := &rollout{
+		config:      p.Config,
+		concurrency: p.Concurrency,
+		action: func(_ context.Context, h *mkeconfig.Host) error {
+			return p.upgradeMCR(h)
+		},
 	}
-
-	// Upgrade managers individually
-	for _, h := range managers {
-		err := p.upgradeMCR(h)
-		if err != nil {
-			return fmt.Errorf("upgrade MCR failed. %w", err)
-		}
-	}
-	if p.Config.Spec.MKE.Metadata.Installed {
-		err := p.Config.Spec.CheckMKEHealthLocal(managers)
-		if err != nil {
-			return fmt.Errorf("checkMKEHealthLocal failed. %w", err)
-		}
+	if err := r.run(ctx, p.Hosts); err != nil {
+		return fmt.Errorf("upgrade MCR failed: %w", err)
 	}
+	return nil
+}
 
+// msrReplicaPort returns the HTTPS port of the MSR replicas.
+func msrReplicaPort(config *mkeconfig.ClusterConfig) int {
 	port := 443
-	if p.Config.Spec.MSR != nil {
-		if flagport := p.Config.Spec.MSR.InstallFlags.GetValue("--replica-https-port"); flagport != "" {
+	if config.Spec.MSR != nil {
+		if flagport := config.Spec.MSR.InstallFlags.GetValue("--replica-https-port"); flagport != "" {
 			if fp, err := strconv.Atoi(flagport); err == nil {
 				port = fp
 			}
 		}
 	}
-
-	// Upgrade MSR hosts individually
-	for _, h := range msrs {
-		if h.MSRMetadata.Installed {
-			if err := msr.WaitMSRNodeReady(h, port); err != nil {
-				return fmt.Errorf("%s: check msr node ready state: %w", h, err)
-			}
-		}
-		if err := p.upgradeMCR(h); err != nil {
-			return err
-		}
-		if h.MSRMetadata.Installed {
-			if err := msr.WaitMSRNodeReady(h, port); err != nil {
-				return fmt.Errorf("%s: check msr node ready state: %w", h, err)
-			}
-			err := retry.Do(
-				func() error {
-					if _, err := msr.CollectFacts(h); err != nil {
-						return fmt.Errorf("%s: collect msr facts: %w", h, err)
-					}
-					return nil
-				},
-				retry.DelayType(retry.CombineDelay(retry.FixedDelay, retry.RandomDelay)),
-				retry.MaxJitter(time.Second*2),
-				retry.Delay(time.Second*5),
-				retry.Attempts(3),
-			)
-			if err != nil {
-				return fmt.Errorf("retry count exceeded: %w", err)
-			}
-		}
-	}
-
-	log.Debugf("concurrently upgrading workers in batches of %d", p.Concurrency)
-	pool := workerpool.New(p.Concurrency)
-	mu := sync.Mutex{}
-	installErrors := &phase.Error{}
-	for _, w := range workers {
-		h := w
-		pool.Submit(func() {
-			err := p.upgradeMCR(h)
-			if err != nil {
-				mu.Lock()
-				installErrors.Errors = append(installErrors.Errors, err)
-				mu.Unlock()
-			}
-		})
-	}
-	pool.StopWait()
-	if installErrors.Count() > 0 {
-		return installErrors
-	}
-	return nil
+	return port
 }
 
 func (p *UpgradeMCR) upgradeMCR(h *mkeconfig.Host) error {
 	log.Infof("%s: upgrading container runtime (%s)", h, p.Config.Spec.MCR.Channel)
 	if err := h.Configurer.InstallMCR(h, p.Config.Spec.MCR); err != nil {
-		return fmt.Errorf("%s: failed to install container runtime: %w", h, err)
+		return fmt.Errorf("failed to install container runtime: %w", err)
 	}
 
 	// ensure that MCR is installed and running
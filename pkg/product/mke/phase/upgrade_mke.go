@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/mke"
@@ -23,8 +24,17 @@ func (p *UpgradeMKE) Title() string {
 	return "Upgrade MKE components"
 }
 
+// PlannedActions describes the MKE upgrade when the installed version differs from the configured one.
+func (p *UpgradeMKE) PlannedActions() []phase.PlannedAction {
+	meta := p.Config.Spec.MKE.Metadata
+	if !meta.Installed || meta.InstalledVersion == p.Config.Spec.MKE.Version {
+		return nil
+	}
+	return []phase.PlannedAction{{Host: p.Config.Spec.SwarmLeader().String(), Action: fmt.Sprintf("upgrade MKE %s to %s", meta.InstalledVersion, p.Config.Spec.MKE.Version)}}
+}
+
 // Run the upgrade container.
-func (p *UpgradeMKE) Run() error {
+func (p *UpgradeMKE) Run(_ context.Context) error {
 	leader := p.Config.Spec.SwarmLeader()
 
 	p.EventProperties = map[string]interface{}{
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/constant"
@@ -25,11 +26,17 @@ func (p *UpgradeMSR) Title() string {
 // ShouldRun should return true only when there is an upgrade to be performed.
 func (p *UpgradeMSR) ShouldRun() bool {
 	h := p.Config.Spec.MSRLeader()
-	return p.Config.Spec.ContainsMSR() && h.MSRMetadata != nil && h.MSRMetadata.InstalledVersion != p.Config.Spec.MSR.Version
+	return p.Config.Spec.ContainsMSR() && h != nil && h.MSRMetadata != nil && h.MSRMetadata.InstalledVersion != p.Config.Spec.MSR.Version
+}
+
+// PlannedActions describes the MSR upgrade.
+func (p *UpgradeMSR) PlannedActions() []phase.PlannedAction {
+	h := p.Config.Spec.MSRLeader()
+	return []phase.PlannedAction{{Host: h.String(), Action: fmt.Sprintf("upgrade MSR %s to %s", h.MSRMetadata.InstalledVersion, p.Config.Spec.MSR.Version)}}
 }
 
 // Run the upgrade container.
-func (p *UpgradeMSR) Run() error {
+func (p *UpgradeMSR) Run(_ context.Context) error {
 	h := p.Config.Spec.MSRLeader()
 
 	p.EventProperties = map[string]interface{}{
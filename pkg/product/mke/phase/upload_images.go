@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
@@ -25,9 +26,14 @@ func (p *LoadImages) Title() string {
 	return "Upload images"
 }
 
+// ResourceScopes returns the hosts the images are uploaded to.
+func (p *LoadImages) ResourceScopes() []string {
+	return phase.HostScopes(p.Hosts)
+}
+
 // HostFilterFunc returns true for hosts that have images to be uploaded.
 func (p *LoadImages) HostFilterFunc(h *mkeconfig.Host) bool {
-	if h.ImageDir == "" {
+	if h.Dropped() || h.ImageDir == "" {
 		return false
 	}
 	log.Debugf("%s: listing images in imageDir '%s'", h, h.ImageDir)
@@ -78,7 +84,7 @@ func (p *LoadImages) Prepare(config interface{}) error {
 }
 
 // Run does all the work.
-func (p *LoadImages) Run() error {
+func (p *LoadImages) Run(_ context.Context) error {
 	var totalBytes uint64
 	_ = p.Hosts.Each(func(h *mkeconfig.Host) error {
 		totalBytes += h.Metadata.TotalImageBytes
@@ -1,6 +1,10 @@
 package phase
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"net"
@@ -8,7 +12,9 @@ import (
 
 	"github.com/Mirantis/launchpad/pkg/mke"
 	"github.com/Mirantis/launchpad/pkg/phase"
+	commonconfig "github.com/Mirantis/launchpad/pkg/product/common/config"
 	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	"github.com/Mirantis/launchpad/pkg/swarm"
 	"github.com/hashicorp/go-version"
 	log "github.com/sirupsen/logrus"
 )
@@ -19,6 +25,7 @@ var ErrFactsArentValid = errors.New("validation failed")
 type ValidateFacts struct {
 	phase.Analytics
 	phase.BasicPhase
+	phase.ReadOnly
 	Force bool
 }
 
@@ -28,7 +35,7 @@ func (p *ValidateFacts) Title() string {
 }
 
 // Run validate configuration facts.
-func (p *ValidateFacts) Run() error {
+func (p *ValidateFacts) Run(_ context.Context) error {
 	if p.Config.Spec.MKE.Version == "" {
 		return errors.Join(ErrFactsArentValid, fmt.Errorf("MKE spec is required (spec.mke.version)"))
 	}
@@ -70,6 +77,22 @@ func (p *ValidateFacts) Run() error {
 		}
 	}
 
+	if err := validateRoleChanges(p.Config.Spec.Hosts); err != nil {
+		if p.Force {
+			log.Warnf("%s: continuing anyway because --force given", err.Error())
+		} else {
+			return errors.Join(ErrFactsArentValid, err)
+		}
+	}
+
+	if err := p.validateManagerRemoval(); err != nil {
+		if p.Force {
+			log.Warnf("%s: continuing anyway because --force given, the swarm may lose its quorum and become unmanageable", err.Error())
+		} else {
+			return errors.Join(ErrFactsArentValid, err)
+		}
+	}
+
 	if err := p.validatePodCIDR(); err != nil {
 		return errors.Join(ErrFactsArentValid, err)
 	}
@@ -77,6 +100,57 @@ func (p *ValidateFacts) Run() error {
 	return nil
 }
 
+// ValidateConfig runs the checks of ValidateFacts that do not depend on facts gathered
+// from the hosts, and the certificate and key checks, which only run here and not in
+// ValidateFacts. The returned errors are *commonconfig.FieldError.
+func ValidateConfig(config *mkeconfig.ClusterConfig) []error {
+	p := &ValidateFacts{}
+	p.Config = config
+
+	var errs []error
+	if err := p.validateDataPlane(); err != nil {
+		errs = append(errs, &commonconfig.FieldError{Path: "spec.mke.installFlags", Err: err})
+	}
+	if err := p.validatePodCIDR(); err != nil {
+		errs = append(errs, &commonconfig.FieldError{Path: "spec.mke.installFlags", Err: err})
+	}
+	for _, cert := range p.certificates() {
+		if err := cert.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateManagerRemoval refuses pruning more managers than the swarm tolerates losing
+// before anything is changed, RemoveNodes checks it again when it runs at the end of
+// the apply.
+func (p *ValidateFacts) validateManagerRemoval() error {
+	if !p.Config.Spec.Cluster.Prune {
+		return nil
+	}
+	leader := p.Config.Spec.SwarmLeader()
+	if leader == nil || leader.Metadata == nil || leader.Metadata.SwarmRole != "manager" {
+		return nil
+	}
+	nodes, err := swarm.Nodes(leader)
+	if err != nil {
+		return fmt.Errorf("failed to get swarm nodes: %w", err)
+	}
+	var nodeIDs []string
+	for _, h := range p.Config.Spec.Hosts {
+		if h.Metadata == nil || h.Metadata.SwarmRole == "" {
+			continue
+		}
+		nodeID, err := swarm.NodeID(h)
+		if err != nil {
+			return fmt.Errorf("%s: %w", h, err)
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	return checkManagerQuorum(managerRemovals(nodes, nodeIDs))
+}
+
 func (p *ValidateFacts) populateSan() {
 	mgrs := p.Config.Spec.Managers()
 	for _, h := range mgrs {
@@ -201,6 +275,101 @@ func (p *ValidateFacts) validateDataPlane() error {
 	return nil
 }
 
+var errInvalidCertificate = errors.New("invalid certificate configuration")
+
+// certificateConfig is the set of certificates and the key from the MKE or MSR config.
+type certificateConfig struct {
+	// path is the YAML path of the MKE or MSR config.
+	path           string
+	caCert         string
+	cert           string
+	key            string
+	caCertFromFile bool
+	certFromFile   bool
+	keyFromFile    bool
+}
+
+// field returns the YAML path of the field the value was given in.
+func (c certificateConfig) field(name string, fromFile bool) string {
+	if fromFile {
+		return c.path + "." + name + "Path"
+	}
+	return c.path + "." + name + "Data"
+}
+
+// validate checks that the certificates parse and that the key matches the certificate.
+func (c certificateConfig) validate() *commonconfig.FieldError {
+	if c.caCert != "" {
+		if err := parseCertificates(c.caCert); err != nil {
+			return &commonconfig.FieldError{Path: c.field("caCert", c.caCertFromFile), Err: fmt.Errorf("%w: %w", errInvalidCertificate, err)}
+		}
+	}
+	if c.cert == "" && c.key == "" {
+		return nil
+	}
+	if c.key == "" {
+		return &commonconfig.FieldError{Path: c.field("key", c.keyFromFile), Err: fmt.Errorf("%w: a key is required with the certificate", errInvalidCertificate)}
+	}
+	if c.cert == "" {
+		return &commonconfig.FieldError{Path: c.field("cert", c.certFromFile), Err: fmt.Errorf("%w: a certificate is required with the key", errInvalidCertificate)}
+	}
+	if err := parseCertificates(c.cert); err != nil {
+		return &commonconfig.FieldError{Path: c.field("cert", c.certFromFile), Err: fmt.Errorf("%w: %w", errInvalidCertificate, err)}
+	}
+	if _, err := tls.X509KeyPair([]byte(c.cert), []byte(c.key)); err != nil {
+		return &commonconfig.FieldError{Path: c.field("key", c.keyFromFile), Err: fmt.Errorf("%w: %w", errInvalidCertificate, err)}
+	}
+	return nil
+}
+
+var errNoCertificate = errors.New("no PEM encoded certificate found")
+
+// parseCertificates checks that data contains one or more PEM encoded certificates.
+func parseCertificates(data string) error {
+	rest := []byte(data)
+	found := false
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		found = true
+	}
+	if !found {
+		return errNoCertificate
+	}
+	return nil
+}
+
+func (p *ValidateFacts) certificates() []certificateConfig {
+	mkeCfg := p.Config.Spec.MKE
+	certs := []certificateConfig{{
+		path:           "spec.mke",
+		caCert:         mkeCfg.CACertData,
+		cert:           mkeCfg.CertData,
+		key:            mkeCfg.KeyData,
+		caCertFromFile: mkeCfg.CACertPath != "",
+		certFromFile:   mkeCfg.CertPath != "",
+		keyFromFile:    mkeCfg.KeyPath != "",
+	}}
+	if msrCfg := p.Config.Spec.MSR; msrCfg != nil {
+		certs = append(certs, certificateConfig{
+			path:           "spec.msr",
+			caCert:         msrCfg.CACertData,
+			cert:           msrCfg.CertData,
+			key:            msrCfg.KeyData,
+			caCertFromFile: msrCfg.CACertPath != "",
+			certFromFile:   msrCfg.CertPath != "",
+			keyFromFile:    msrCfg.KeyPath != "",
+		})
+	}
+	return certs
+}
+
 var errInvalidPodCIDR = errors.New("invalid pod CIDR configuration")
 
 // swarmDefaultAddrPool is the Docker Swarm default overlay address pool.
@@ -1,8 +1,17 @@
 package phase
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	commonconfig "github.com/Mirantis/launchpad/pkg/product/common/config"
 	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
@@ -173,7 +182,7 @@ func TestValidateFactsPopulateSan(t *testing.T) {
 			},
 		},
 	}
-	require.NoError(t, phase.Run())
+	require.NoError(t, phase.Run(context.Background()))
 	var sans []string
 
 	for _, v := range phase.Config.Spec.MKE.InstallFlags {
@@ -211,7 +220,7 @@ func TestValidateFactsDontPopulateSan(t *testing.T) {
 			},
 		},
 	}
-	require.NoError(t, phase.Run())
+	require.NoError(t, phase.Run(context.Background()))
 	var sans []string
 
 	for _, v := range phase.Config.Spec.MKE.InstallFlags {
@@ -234,7 +243,7 @@ func TestValidateInvalidMCRConfig(t *testing.T) {
 		},
 	}
 
-	require.Error(t, phase.Run(), "MCR version validated an invalid config")
+	require.Error(t, phase.Run(context.Background()), "MCR version validated an invalid config")
 }
 
 func makePhaseWithPodCIDR(podCIDR string, swarmPools ...string) ValidateFacts {
@@ -350,3 +359,52 @@ func TestValidatePodCIDRMalformedSwarmPool(t *testing.T) {
 	require.ErrorIs(t, err, errInvalidPodCIDR)
 	require.ErrorContains(t, err, "cannot parse Swarm address pool")
 }
+
+// selfSignedCert returns a PEM encoded certificate and key.
+func selfSignedCert(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mke.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestValidateCertificates(t *testing.T) {
+	cert, key := selfSignedCert(t)
+	_, otherKey := selfSignedCert(t)
+
+	p := ValidateFacts{}
+	p.Config = &mkeconfig.ClusterConfig{
+		Spec: &mkeconfig.ClusterSpec{
+			MKE: mkeconfig.MKEConfig{CACertData: cert, CertData: cert, KeyData: key},
+		},
+	}
+	require.Empty(t, ValidateConfig(p.Config))
+
+	p.Config.Spec.MKE.KeyData = otherKey
+	p.Config.Spec.MKE.KeyPath = "key.pem"
+	errs := ValidateConfig(p.Config)
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], errInvalidCertificate)
+	var fieldErr *commonconfig.FieldError
+	require.ErrorAs(t, errs[0], &fieldErr)
+	require.Equal(t, "spec.mke.keyPath", fieldErr.Path)
+
+	p.Config.Spec.MKE.KeyData = key
+	p.Config.Spec.MSR = &mkeconfig.MSRConfig{CACertData: "not a certificate"}
+	errs = ValidateConfig(p.Config)
+	require.Len(t, errs, 1)
+	require.ErrorAs(t, errs[0], &fieldErr)
+	require.Equal(t, "spec.msr.caCertData", fieldErr.Path)
+	require.ErrorIs(t, errs[0], errNoCertificate)
+}
@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"io"
@@ -20,6 +21,10 @@ import (
 type ValidateHosts struct {
 	phase.Analytics
 	phase.BasicPhase
+	phase.ReadOnly
+
+	// DryRun skips the connection test, which writes test files to the hosts.
+	DryRun bool
 }
 
 // Title for the phase.
@@ -28,8 +33,8 @@ func (p *ValidateHosts) Title() string {
 }
 
 // Run collect all the facts from hosts in parallel.
-func (p *ValidateHosts) Run() error {
-	if mcclog.Trace {
+func (p *ValidateHosts) Run(_ context.Context) error {
+	if mcclog.Trace && !p.DryRun {
 		if err := p.validateHostConnection(); err != nil {
 			return p.formatErrors()
 		}
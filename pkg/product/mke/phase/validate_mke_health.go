@@ -1,6 +1,7 @@
 package phase
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -21,6 +22,7 @@ import (
 type ValidateMKEHealth struct {
 	phase.Analytics
 	phase.BasicPhase
+	phase.Repeatable
 }
 
 // Title for the phase.
@@ -31,7 +33,7 @@ func (p *ValidateMKEHealth) Title() string {
 // Run validates the health of MKE is sane before continuing with other
 // launchpad phases, should be used when installing products that depend
 // on MKE, such as MSR.
-func (p *ValidateMKEHealth) Run() error {
+func (p *ValidateMKEHealth) Run(ctx context.Context) error {
 	// Issue a health check to the MKE local address managers until we receive an 'ok' status
 	managers := p.Config.Spec.Managers()
 
@@ -72,7 +74,7 @@ func (p *ValidateMKEHealth) Run() error {
 				}
 				return nil
 			},
-			retry.Attempts(retries), retry.Delay(delay),
+			retry.Attempts(retries), retry.Delay(delay), retry.Context(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("%w: failed to validate MKE health: %w", errValidationFailed, err)
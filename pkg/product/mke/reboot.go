@@ -0,0 +1,58 @@
+package mke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	"github.com/Mirantis/launchpad/pkg/product"
+	common "github.com/Mirantis/launchpad/pkg/product/common/phase"
+	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
+	mke "github.com/Mirantis/launchpad/pkg/product/mke/phase"
+)
+
+var errInvalidHostSelection = errors.New("invalid host selection")
+
+// Reboot reboots the hosts of the cluster one manager at a time and the workers in
+// batches, draining their swarm nodes while they are down.
+func (p *MKE) Reboot(ctx context.Context, opts product.RebootOptions) error {
+	if err := p.checkHostSelection(opts.Roles, opts.Hosts); err != nil {
+		return err
+	}
+
+	phaseManager := phase.NewManager(&p.ClusterConfig)
+	phaseManager.AddPhases(
+		&mke.OverrideHostSudo{},
+		&common.Connect{},
+		&mke.DetectOS{},
+		&mke.GatherFacts{},
+		&mke.RebootHosts{Roles: opts.Roles, Addresses: opts.Hosts, Concurrency: opts.Concurrency},
+		&common.Disconnect{},
+	)
+
+	if err := phaseManager.Run(ctx); err != nil {
+		return fmt.Errorf("failed to reboot hosts: %w", err)
+	}
+	return nil
+}
+
+// checkHostSelection returns an error when a role or an address doesn't match any host.
+func (p *MKE) checkHostSelection(roles, addresses []string) error {
+	for _, role := range roles {
+		if p.ClusterConfig.Spec.Hosts.Find(func(h *mkeconfig.Host) bool { return h.Role == role }) == nil {
+			return fmt.Errorf("%w: no hosts with the role %s", errInvalidHostSelection, role)
+		}
+	}
+	for _, address := range addresses {
+		h := p.ClusterConfig.Spec.Hosts.Find(func(h *mkeconfig.Host) bool { return h.Address() == address })
+		if h == nil {
+			return fmt.Errorf("%w: host %s not found in configuration", errInvalidHostSelection, address)
+		}
+		if len(roles) > 0 && !slices.Contains(roles, h.Role) {
+			return fmt.Errorf("%w: host %s has the role %s", errInvalidHostSelection, address, h.Role)
+		}
+	}
+	return nil
+}
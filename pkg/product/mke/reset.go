@@ -1,16 +1,21 @@
 package mke
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Mirantis/launchpad/pkg/phase"
+	"github.com/Mirantis/launchpad/pkg/product"
 	common "github.com/Mirantis/launchpad/pkg/product/common/phase"
 	mke "github.com/Mirantis/launchpad/pkg/product/mke/phase"
 )
 
 // Reset uninstalls a Docker Enterprise cluster.
-func (p *MKE) Reset() error {
+func (p *MKE) Reset(ctx context.Context, opts product.ResetOptions) error {
 	phaseManager := phase.NewManager(&p.ClusterConfig)
+	phaseManager.Only = opts.Phases
+	phaseManager.Skip = opts.SkipPhases
+	phaseManager.AllowUnsafeSelection = opts.AllowUnsafeSelection
 
 	phaseManager.AddPhases(
 		&mke.OverrideHostSudo{},
@@ -31,7 +36,7 @@ func (p *MKE) Reset() error {
 		&common.Disconnect{},
 	)
 
-	if err := phaseManager.Run(); err != nil {
+	if err := phaseManager.Run(ctx); err != nil {
 		return fmt.Errorf("reset failed: %w", err)
 	}
 	return nil
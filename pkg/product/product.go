@@ -1,9 +1,73 @@
 package product
 
+import (
+	"context"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+)
+
+// ApplyOptions are the options for the Apply operation.
+type ApplyOptions struct {
+	// DisableCleanup disables the cleanup of failed phases.
+	DisableCleanup bool
+	// Force allows continuing in some situations where prerequisite checks fail.
+	Force bool
+	// Concurrency is the number of simultaneous worker node upgrades.
+	Concurrency int
+	// ForceUpgrade runs the upgrades even when they do not look necessary.
+	ForceUpgrade bool
+	// Resume skips the phases that completed during a previous failed run.
+	Resume bool
+	// Phases, when set, limits the run to the phases with the listed identifiers.
+	Phases []string
+	// SkipPhases lists the identifiers of phases that are not run.
+	SkipPhases []string
+	// AllowUnsafeSelection allows running a phase selection that leaves out phases the selected phases require.
+	AllowUnsafeSelection bool
+	// KeepGoing drops the hosts a phase fails on and carries on with the rest.
+	KeepGoing bool
+}
+
+// ResetOptions are the options for the Reset operation.
+type ResetOptions struct {
+	// AllowUnsafeSelection allows running a phase selection that leaves out phases the selected phases require.
+	AllowUnsafeSelection bool
+	// Phases, when set, limits the run to the phases with the listed identifiers.
+	Phases []string
+	// SkipPhases lists the identifiers of phases that are not run.
+	SkipPhases []string
+}
+
+// RebootOptions are the options for the Reboot operation.
+type RebootOptions struct {
+	// Roles, when set, limits the reboot to the hosts with the listed roles.
+	Roles []string
+	// Hosts, when set, limits the reboot to the hosts with the listed addresses.
+	Hosts []string
+	// Concurrency is the number of worker nodes rebooted at a time.
+	Concurrency int
+}
+
+// OSUpdateOptions are the options for the OSUpdate operation.
+type OSUpdateOptions struct {
+	// Roles, when set, limits the update to the hosts with the listed roles.
+	Roles []string
+	// Hosts, when set, limits the update to the hosts with the listed addresses.
+	Hosts []string
+	// Concurrency is the number of worker nodes updated at a time.
+	Concurrency int
+	// IncludeMCR upgrades the MCR packages along with the rest, they are held back by default.
+	IncludeMCR bool
+}
+
 // Product is an interface that represents a product that launchpad can manage.
 type Product interface {
-	Apply(disableCleanup, force bool, concurrency int, forceUpgrade bool) error
-	Reset() error
+	Apply(ctx context.Context, opts ApplyOptions) error
+	Plan(ctx context.Context, opts ApplyOptions) (*phase.Plan, error)
+	Diff(ctx context.Context) (*phase.DriftReport, error)
+	Reset(ctx context.Context, opts ResetOptions) error
+	Reboot(ctx context.Context, opts RebootOptions) error
+	OSUpdate(ctx context.Context, opts OSUpdateOptions) (*phase.OSUpdateReport, error)
 	Describe(reportName string) error
 	ClientConfig() error
 	Exec(target []string, interactive, first, all, parallel bool, role, os, cmd string) error
@@ -0,0 +1,110 @@
+package reconcile
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	"gopkg.in/yaml.v2"
+)
+
+var errOutsideLimits = errors.New("the differences are outside the auto-apply limits")
+
+// Limits decide which differences between the configuration and the cluster are
+// applied automatically.
+type Limits struct {
+	// Roles are the roles of the hosts whose differences can be applied.
+	Roles []string
+	// MaxHosts is the largest number of hosts with differences that are applied at
+	// once, 0 for no limit.
+	MaxHosts int
+	// ClusterChanges allows applying the differences that don't belong to a single
+	// host, such as the MKE version.
+	ClusterChanges bool
+}
+
+// Check returns an error that lists the differences that are outside the limits.
+func (l Limits) Check(report *phase.DriftReport) error {
+	var problems []string
+	hosts := make(map[string]struct{})
+	roles := make(map[string]struct{})
+	for _, d := range report.Drift {
+		if d.Host == "" {
+			if !l.ClusterChanges {
+				problems = append(problems, fmt.Sprintf("%s is not specific to a host", d.Item))
+			}
+			continue
+		}
+		hosts[d.Host] = struct{}{}
+		if !l.allowsRole(d.Role) {
+			roles[d.Role] = struct{}{}
+		}
+	}
+	for _, role := range sortedKeys(roles) {
+		problems = append(problems, fmt.Sprintf("%s hosts have differences", role))
+	}
+	if l.MaxHosts > 0 && len(hosts) > l.MaxHosts {
+		problems = append(problems, fmt.Sprintf("%d hosts have differences, the limit is %d", len(hosts), l.MaxHosts))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", errOutsideLimits, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// Fingerprint returns a checksum of the parts of the configuration that auto-apply is
+// not allowed to change: the spec.hosts entries whose role is not allowed and, unless
+// ClusterChanges is set, everything outside spec.hosts. Apply applies the whole
+// configuration, so the configuration can only be auto-applied when its fingerprint
+// matches the one of a configuration the cluster was in sync with.
+func (l Limits) Fingerprint(data []byte) (string, error) {
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("failed to unmarshal configuration: %w", err)
+	}
+
+	var hosts []interface{}
+	spec, _ := config["spec"].(map[interface{}]interface{})
+	if spec != nil {
+		entries, _ := spec["hosts"].([]interface{})
+		for _, entry := range entries {
+			host, _ := entry.(map[interface{}]interface{})
+			role, _ := host["role"].(string)
+			if !l.allowsRole(role) {
+				hosts = append(hosts, entry)
+			}
+		}
+		delete(spec, "hosts")
+	}
+
+	fixed := map[string]interface{}{"hosts": hosts}
+	if !l.ClusterChanges {
+		fixed["config"] = config
+	}
+	out, err := yaml.Marshal(fixed)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(out)), nil
+}
+
+func (l Limits) allowsRole(role string) bool {
+	for _, r := range l.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
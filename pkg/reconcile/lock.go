@@ -0,0 +1,100 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/Mirantis/launchpad/pkg/constant"
+	"github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+)
+
+var errLocked = errors.New("another reconcile or apply is running")
+
+// DefaultLockPath returns the lock file location for the named cluster under the local
+// launchpad state directory. Both reconcile and apply take the lock, so an apply can't
+// run while a reconcile may apply the configuration.
+func DefaultLockPath(clusterName string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, constant.StateBaseDir, "cluster", clusterName, "reconcile.lock"), nil
+}
+
+// Lock is a lock file that holds the process id of its owner.
+type Lock struct {
+	path string
+}
+
+// AcquireLock creates the lock file. A lock file left behind by a process that is no
+// longer running is replaced.
+func AcquireLock(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file %s: %w", path, err)
+			}
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", path, err)
+		}
+
+		pid, running := lockOwner(path)
+		if running {
+			return nil, fmt.Errorf("%w: %s is held by process %d", errLocked, path, pid)
+		}
+		log.Warnf("removing the lock file %s of process %d that is no longer running", path, pid)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lock file %s: %w", path, err)
+		}
+	}
+	return nil, fmt.Errorf("%w: failed to acquire %s", errLocked, path)
+}
+
+// Release removes the lock file.
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+// lockOwner returns the process id in the lock file and whether the process is running.
+// A lock file that can't be parsed is treated as stale.
+func lockOwner(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess fails on windows when the process doesn't exist
+		return pid, true
+	}
+	return pid, process.Signal(syscall.Signal(0)) == nil
+}
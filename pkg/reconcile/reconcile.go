@@ -0,0 +1,337 @@
+// Package reconcile keeps a cluster in line with its configuration by comparing them
+// periodically and optionally applying the configuration.
+package reconcile
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	"github.com/Mirantis/launchpad/pkg/product"
+	log "github.com/sirupsen/logrus"
+)
+
+// The states of a reconcile run.
+const (
+	StateStarting = "starting"
+	StateInSync   = "in sync"
+	StateDrift    = "drift"
+	StateBlocked  = "blocked"
+	StateApplied  = "applied"
+	StateFailed   = "failed"
+)
+
+var (
+	errNoConfig    = errors.New("no configuration files found")
+	errStdinConfig = errors.New("the configuration can't be read from stdin, it is loaded again for each run")
+)
+
+// Loader loads the product from the configuration files.
+type Loader func(paths []string) (product.Product, error)
+
+// Status is the result of the last reconcile run.
+type Status struct {
+	Cluster             string               `json:"cluster,omitempty"`
+	State               string               `json:"state"`
+	ConfigFiles         []string             `json:"configFiles,omitempty"`
+	ConfigChecksum      string               `json:"configChecksum,omitempty"`
+	LastRun             time.Time            `json:"lastRun,omitzero"`
+	LastRunDuration     float64              `json:"lastRunDuration,omitempty"`
+	NextRun             time.Time            `json:"nextRun,omitzero"`
+	LastApply           time.Time            `json:"lastApply,omitzero"`
+	Drift               []phase.Drift        `json:"drift,omitempty"`
+	Plan                []phase.PlannedPhase `json:"plan,omitempty"`
+	Blocked             string               `json:"blocked,omitempty"`
+	Error               string               `json:"error,omitempty"`
+	ConsecutiveFailures int                  `json:"consecutiveFailures"`
+}
+
+// Reconciler compares the cluster to the configuration on an interval and, when
+// AutoApply is set, applies the configuration when the differences are within the
+// limits.
+type Reconciler struct {
+	// Paths are the configuration files, layered in order like the --config files of
+	// the other commands. A directory stands for its .yaml and .yml files in the order
+	// of their names.
+	Paths []string
+	// Load loads the product from the configuration files.
+	Load Loader
+	// Render returns the merged configuration of the files, which tells the parts of
+	// the configuration that changed since the cluster was last in sync.
+	Render func(paths []string) ([]byte, error)
+	// Interval is the time between the runs. A run also starts when the
+	// configuration files change, they are checked every PollInterval.
+	Interval     time.Duration
+	PollInterval time.Duration
+	// MaxBackoff is the longest time between the runs after failures. The
+	// interval is doubled after each failed run up to MaxBackoff.
+	MaxBackoff time.Duration
+
+	AutoApply    bool
+	Limits       Limits
+	ApplyOptions product.ApplyOptions
+
+	mu     sync.Mutex
+	status Status
+	// synced is the fingerprint of the configuration the cluster was last in sync with.
+	synced string
+}
+
+// Status returns the result of the last run.
+func (r *Reconciler) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+func (r *Reconciler) update(fn func(s *Status)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.status)
+}
+
+// Run runs the reconcile loop until the context is cancelled.
+func (r *Reconciler) Run(ctx context.Context) error {
+	r.update(func(s *Status) { s.State = StateStarting })
+
+	for {
+		files, checksum, err := r.ConfigFiles()
+		if err == nil {
+			err = r.reconcile(ctx, files, checksum)
+		}
+		if ctx.Err() != nil {
+			return nil //nolint:nilerr // cancelled runs are not failures
+		}
+
+		status := r.Status()
+		if err != nil {
+			log.Errorf("reconcile failed (%d in a row): %s", status.ConsecutiveFailures+1, err)
+			r.update(func(s *Status) {
+				s.State = StateFailed
+				s.Error = err.Error()
+				s.ConsecutiveFailures++
+			})
+		} else {
+			r.update(func(s *Status) {
+				s.Error = ""
+				s.ConsecutiveFailures = 0
+			})
+		}
+
+		delay := r.delay()
+		log.Infof("next reconcile in %s", delay)
+		r.update(func(s *Status) { s.NextRun = time.Now().Add(delay) })
+		if !r.wait(ctx, delay, checksum) {
+			return nil
+		}
+	}
+}
+
+// delay returns the time to the next run, which grows after consecutive failures.
+func (r *Reconciler) delay() time.Duration {
+	delay := r.Interval
+	for i := 0; i < r.Status().ConsecutiveFailures; i++ {
+		delay *= 2
+		if r.MaxBackoff > 0 && delay >= r.MaxBackoff {
+			return r.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// wait waits for the delay or until the configuration changes. It returns false when
+// the context was cancelled.
+func (r *Reconciler) wait(ctx context.Context, delay time.Duration, checksum string) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var poll <-chan time.Time
+	if r.PollInterval > 0 {
+		ticker := time.NewTicker(r.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		case <-poll:
+			if _, current, err := r.ConfigFiles(); err == nil && current != checksum {
+				log.Infof("the configuration has changed")
+				return true
+			}
+		}
+	}
+}
+
+// reconcile runs the diff and, if needed and allowed, the apply.
+func (r *Reconciler) reconcile(ctx context.Context, files []string, checksum string) error {
+	start := time.Now()
+	defer func() {
+		r.update(func(s *Status) { s.LastRunDuration = time.Since(start).Seconds() })
+	}()
+
+	p, err := r.Load(files)
+	if err != nil {
+		return fmt.Errorf("failed to load the configuration: %w", err)
+	}
+	data, err := r.Render(files)
+	if err != nil {
+		return fmt.Errorf("failed to load the configuration: %w", err)
+	}
+	fingerprint, err := r.Limits.Fingerprint(data)
+	if err != nil {
+		return err
+	}
+	r.update(func(s *Status) {
+		s.Cluster = p.ClusterName()
+		s.ConfigFiles = files
+		s.ConfigChecksum = checksum
+		s.LastRun = start
+	})
+
+	log.Infof("comparing the cluster to the configuration")
+	report, err := p.Diff(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to diff cluster: %w", err)
+	}
+	r.update(func(s *Status) {
+		s.Drift = report.Drift
+		s.Plan = nil
+		s.Blocked = ""
+	})
+	if report.Empty() {
+		log.Infof("the cluster matches the configuration")
+		r.synced = fingerprint
+		r.update(func(s *Status) { s.State = StateInSync })
+		return nil
+	}
+	log.Warnf("found %d differences between the configuration and the cluster", len(report.Drift))
+
+	// the differences apply leaves as they are would have it run on every interval
+	applicable := report.Applicable()
+	if applicable.Empty() {
+		r.synced = fingerprint
+	}
+
+	blocked := ""
+	switch {
+	case !r.AutoApply:
+		blocked = "auto-apply is disabled"
+	case applicable.Empty():
+		blocked = "apply does not change the differences: " + strings.Join(report.NotAppliedReasons(), ", ")
+	case r.synced == "":
+		// the apply would apply the whole configuration, and without a configuration
+		// the cluster was in sync with there is nothing to tell its changes from
+		blocked = "the cluster has not been in sync with the configuration since reconcile started"
+	case r.synced != fingerprint:
+		blocked = "the configuration has changed outside the hosts the limits allow to change"
+	default:
+		if err := r.Limits.Check(applicable); err != nil {
+			blocked = err.Error()
+		}
+	}
+
+	// the facts gathered by the diff are kept in the product, so it is loaded again
+	// for the plan and the apply
+	if p, err = r.Load(files); err != nil {
+		return fmt.Errorf("failed to load the configuration: %w", err)
+	}
+
+	if blocked != "" {
+		log.Warnf("not applying the configuration: %s", blocked)
+		plan, err := p.Plan(ctx, r.ApplyOptions)
+		if err != nil {
+			return fmt.Errorf("failed to plan cluster apply: %w", err)
+		}
+		r.update(func(s *Status) {
+			s.State = StateDrift
+			if r.AutoApply {
+				s.State = StateBlocked
+				s.Blocked = blocked
+			}
+			s.Plan = plan.Phases
+		})
+		return nil
+	}
+
+	log.Infof("applying the configuration")
+	if err := p.Apply(ctx, r.ApplyOptions); err != nil {
+		return fmt.Errorf("failed to apply cluster: %w", err)
+	}
+	r.synced = fingerprint
+	r.update(func(s *Status) {
+		s.State = StateApplied
+		s.LastApply = time.Now()
+	})
+	return nil
+}
+
+// ConfigFiles returns the configuration files and a checksum of their contents.
+func (r *Reconciler) ConfigFiles() ([]string, string, error) {
+	if len(r.Paths) == 0 {
+		return nil, "", errNoConfig
+	}
+	var files []string
+	for _, path := range r.Paths {
+		pathFiles, err := configFiles(path)
+		if err != nil {
+			return nil, "", err
+		}
+		files = append(files, pathFiles...)
+	}
+
+	hash := sha256.New()
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read configuration: %w", err)
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", f, len(data))
+		hash.Write(data)
+	}
+	return files, fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// configFiles returns the path, or the .yaml and .yml files in it in the order of their
+// names when it is a directory.
+func configFiles(path string) ([]string, error) {
+	if path == "-" {
+		return nil, errStdinConfig
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration directory: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w in %s", errNoConfig, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
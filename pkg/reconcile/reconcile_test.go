@@ -0,0 +1,311 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mirantis/launchpad/pkg/phase"
+	"github.com/Mirantis/launchpad/pkg/product"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+type fakeProduct struct {
+	drift   []phase.Drift
+	diffErr error
+	applied int
+	planned int
+}
+
+func (p *fakeProduct) Apply(context.Context, product.ApplyOptions) error {
+	p.applied++
+	return nil
+}
+
+func (p *fakeProduct) Plan(context.Context, product.ApplyOptions) (*phase.Plan, error) {
+	p.planned++
+	return &phase.Plan{Phases: []phase.PlannedPhase{{Title: "Join workers"}}}, nil
+}
+
+func (p *fakeProduct) Diff(context.Context) (*phase.DriftReport, error) {
+	if p.diffErr != nil {
+		return nil, p.diffErr
+	}
+	return &phase.DriftReport{Drift: p.drift}, nil
+}
+
+func (p *fakeProduct) Reset(context.Context, product.ResetOptions) error   { return nil }
+func (p *fakeProduct) Reboot(context.Context, product.RebootOptions) error { return nil }
+func (p *fakeProduct) Describe(string) error                               { return nil }
+func (p *fakeProduct) ClientConfig() error                                 { return nil }
+func (p *fakeProduct) ClusterName() string                                 { return "test" }
+
+func (p *fakeProduct) OSUpdate(context.Context, product.OSUpdateOptions) (*phase.OSUpdateReport, error) {
+	return &phase.OSUpdateReport{}, nil
+}
+
+func (p *fakeProduct) Exec([]string, bool, bool, bool, bool, string, string, string) error {
+	return nil
+}
+
+func testReconciler(t *testing.T, p *fakeProduct) *Reconciler {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "launchpad.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0o600))
+	return &Reconciler{
+		Paths:    []string{path},
+		Load:     func([]string) (product.Product, error) { return p, nil },
+		Render:   func(paths []string) ([]byte, error) { return os.ReadFile(paths[0]) },
+		Interval: time.Minute,
+		Limits:   Limits{Roles: []string{"worker"}},
+	}
+}
+
+const testConfig = `apiVersion: test
+spec:
+  hosts:
+    - role: manager
+      ssh:
+        address: m1
+    - role: worker
+      ssh:
+        address: w1
+  mke:
+    version: 3.7.5
+`
+
+// syncedReconcile runs the reconciler with the cluster in sync with the configuration
+// and then with the drift.
+func syncedReconcile(t *testing.T, r *Reconciler, p *fakeProduct, drift []phase.Drift) {
+	t.Helper()
+	p.drift = nil
+	files, checksum, err := r.ConfigFiles()
+	require.NoError(t, err)
+	require.NoError(t, r.reconcile(context.Background(), files, checksum))
+	require.Equal(t, StateInSync, r.Status().State)
+	p.drift = drift
+}
+
+func TestReconcile(t *testing.T) {
+	workerDrift := []phase.Drift{{Host: "w1", Role: "worker", Item: "swarm node", Config: "joined as worker", Cluster: "not joined"}}
+	managerDrift := []phase.Drift{{Host: "m1", Role: "manager", Item: "swarm role", Config: "manager", Cluster: "worker"}}
+	unknownNode := phase.Drift{Host: "10.0.0.9 (w9)", Role: "worker", Item: "swarm node", Cluster: "not in configuration", NotApplied: "spec.cluster.prune is not enabled"}
+
+	tests := []struct {
+		name      string
+		drift     []phase.Drift
+		autoApply bool
+		state     string
+		applied   int
+		planned   int
+		blocked   string
+	}{
+		{name: "in sync", state: StateInSync},
+		{name: "report only", drift: workerDrift, state: StateDrift, planned: 1},
+		{name: "within limits", drift: workerDrift, autoApply: true, state: StateApplied, applied: 1},
+		{name: "outside limits", drift: managerDrift, autoApply: true, state: StateBlocked, planned: 1, blocked: "manager hosts have differences"},
+		{name: "not applied", drift: []phase.Drift{unknownNode}, autoApply: true, state: StateBlocked, planned: 1, blocked: "apply does not change the differences: spec.cluster.prune is not enabled"},
+		{name: "partly applied", drift: append([]phase.Drift{unknownNode}, workerDrift...), autoApply: true, state: StateApplied, applied: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &fakeProduct{}
+			r := testReconciler(t, p)
+			r.AutoApply = tc.autoApply
+			syncedReconcile(t, r, p, tc.drift)
+
+			files, checksum, err := r.ConfigFiles()
+			require.NoError(t, err)
+			require.NoError(t, r.reconcile(context.Background(), files, checksum))
+
+			status := r.Status()
+			require.Equal(t, tc.state, status.State)
+			require.Equal(t, "test", status.Cluster)
+			require.Equal(t, tc.drift, status.Drift)
+			require.Equal(t, tc.applied, p.applied)
+			require.Equal(t, tc.planned, p.planned)
+			require.Contains(t, status.Blocked, tc.blocked)
+		})
+	}
+}
+
+func TestReconcileConfigChanges(t *testing.T) {
+	workerDrift := []phase.Drift{{Host: "w2", Role: "worker", Item: "swarm node", Config: "joined as worker", Cluster: "not joined"}}
+
+	tests := []struct {
+		name           string
+		config         string
+		clusterChanges bool
+		blocked        string
+	}{
+		{name: "worker added", config: strings.Replace(testConfig, "address: w1\n", "address: w1\n    - role: worker\n      ssh:\n        address: w2\n", 1)},
+		{name: "manager changed", config: strings.Replace(testConfig, "address: m1", "address: m2", 1), blocked: "the configuration has changed outside the hosts the limits allow to change"},
+		{name: "cluster changed", config: strings.Replace(testConfig, "3.7.5", "3.7.6", 1), blocked: "the configuration has changed outside the hosts the limits allow to change"},
+		{name: "cluster changes allowed", config: strings.Replace(testConfig, "3.7.5", "3.7.6", 1), clusterChanges: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &fakeProduct{}
+			r := testReconciler(t, p)
+			r.AutoApply = true
+			r.Limits.ClusterChanges = tc.clusterChanges
+			syncedReconcile(t, r, p, workerDrift)
+
+			require.NoError(t, os.WriteFile(r.Paths[0], []byte(tc.config), 0o600))
+			files, checksum, err := r.ConfigFiles()
+			require.NoError(t, err)
+			require.NoError(t, r.reconcile(context.Background(), files, checksum))
+
+			status := r.Status()
+			if tc.blocked == "" {
+				require.Equal(t, StateApplied, status.State)
+				require.Equal(t, 1, p.applied)
+				return
+			}
+			require.Equal(t, StateBlocked, status.State)
+			require.Equal(t, tc.blocked, status.Blocked)
+			require.Zero(t, p.applied)
+		})
+	}
+}
+
+func TestReconcileNotSynced(t *testing.T) {
+	p := &fakeProduct{drift: []phase.Drift{{Host: "w1", Role: "worker", Item: "MCR version", Config: "25.0.1", Cluster: "23.0.9"}}}
+	r := testReconciler(t, p)
+	r.AutoApply = true
+
+	files, checksum, err := r.ConfigFiles()
+	require.NoError(t, err)
+	require.NoError(t, r.reconcile(context.Background(), files, checksum))
+
+	status := r.Status()
+	require.Equal(t, StateBlocked, status.State)
+	require.Contains(t, status.Blocked, "has not been in sync")
+	require.Zero(t, p.applied)
+}
+
+func TestReconcileBackoff(t *testing.T) {
+	p := &fakeProduct{diffErr: errTest}
+	r := testReconciler(t, p)
+	r.PollInterval = 0
+	r.MaxBackoff = 3 * time.Minute
+
+	require.Equal(t, time.Minute, r.delay())
+	r.status.ConsecutiveFailures = 1
+	require.Equal(t, 2*time.Minute, r.delay())
+	r.status.ConsecutiveFailures = 5
+	require.Equal(t, 3*time.Minute, r.delay())
+
+	r.status.ConsecutiveFailures = 0
+	r.Interval = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Run(ctx) }()
+	require.Eventually(t, func() bool { return r.Status().ConsecutiveFailures == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, StateFailed, r.Status().State)
+	require.Contains(t, r.Status().Error, "test error")
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestConfigFilesDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20-workers.yml", "10-cluster.yaml", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600))
+	}
+	r := &Reconciler{Paths: []string{dir}}
+	files, checksum, err := r.ConfigFiles()
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "10-cluster.yaml"), filepath.Join(dir, "20-workers.yml")}, files)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20-workers.yml"), []byte("changed"), 0o600))
+	_, changed, err := r.ConfigFiles()
+	require.NoError(t, err)
+	require.NotEqual(t, checksum, changed)
+
+	_, _, err = (&Reconciler{Paths: []string{t.TempDir()}}).ConfigFiles()
+	require.ErrorIs(t, err, errNoConfig)
+
+	// the directories and the files are layered in the order they are given
+	overlay := filepath.Join(t.TempDir(), "overlay.yaml")
+	require.NoError(t, os.WriteFile(overlay, []byte("overlay"), 0o600))
+	files, _, err = (&Reconciler{Paths: []string{dir, overlay}}).ConfigFiles()
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "10-cluster.yaml"), filepath.Join(dir, "20-workers.yml"), overlay}, files)
+
+	_, _, err = (&Reconciler{Paths: []string{"-"}}).ConfigFiles()
+	require.ErrorIs(t, err, errStdinConfig)
+}
+
+func TestLimits(t *testing.T) {
+	report := &phase.DriftReport{Drift: []phase.Drift{
+		{Host: "w1", Role: "worker", Item: "mcr version"},
+		{Host: "w1", Role: "worker", Item: "mcrConfig.debug"},
+		{Host: "w2", Role: "worker", Item: "mcr version"},
+	}}
+	require.NoError(t, Limits{Roles: []string{"worker"}}.Check(report))
+	require.NoError(t, Limits{Roles: []string{"worker"}, MaxHosts: 2}.Check(report))
+	require.ErrorContains(t, Limits{Roles: []string{"worker"}, MaxHosts: 1}.Check(report), "2 hosts have differences, the limit is 1")
+	require.ErrorContains(t, Limits{Roles: []string{"msr"}}.Check(report), "worker hosts have differences")
+
+	report.Add(phase.Drift{Item: "mke version", Config: "3.8.1", Cluster: "3.7.15"})
+	err := Limits{Roles: []string{"worker"}}.Check(report)
+	require.ErrorIs(t, err, errOutsideLimits)
+	require.ErrorContains(t, err, "mke version is not specific to a host")
+	require.NoError(t, Limits{Roles: []string{"worker"}, ClusterChanges: true}.Check(report))
+}
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cluster", "reconcile.lock")
+	lock, err := AcquireLock(path)
+	require.NoError(t, err)
+
+	_, err = AcquireLock(path)
+	require.ErrorIs(t, err, errLocked)
+
+	require.NoError(t, lock.Release())
+	lock, err = AcquireLock(path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+
+	// a lock of a process that is not running is replaced
+	require.NoError(t, os.WriteFile(path, []byte(strconv.Itoa(1<<22+12345)), 0o600))
+	lock, err = AcquireLock(path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestHandler(t *testing.T) {
+	r := &Reconciler{}
+	r.update(func(s *Status) {
+		s.State = StateDrift
+		s.Drift = []phase.Drift{{Host: "w1", Item: "mcr version"}}
+	})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.Equal(t, StateDrift, status.State)
+	require.Len(t, status.Drift, 1)
+
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	r.update(func(s *Status) { s.State = StateFailed })
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,53 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Handler returns the HTTP handler of the status endpoint. /status returns the status of
+// the last run as JSON, /healthz returns 503 when the last run failed.
+func (r *Reconciler) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(r.Status()); err != nil {
+			log.Debugf("failed to write status: %s", err)
+		}
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		if r.Status().State == StateFailed {
+			http.Error(w, "the last reconcile failed", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// Serve serves the status endpoint on the listener until the context is cancelled.
+func (r *Reconciler) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{Handler: r.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx) //nolint:contextcheck // the parent context is already cancelled
+	}()
+
+	log.Infof("serving the reconcile status on http://%s/status", listener.Addr())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("status endpoint failed: %w", err)
+	}
+	return nil
+}
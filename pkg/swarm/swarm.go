@@ -1,7 +1,11 @@
 package swarm
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"strings"
 
 	mkeconfig "github.com/Mirantis/launchpad/pkg/product/mke/config"
 	log "github.com/sirupsen/logrus"
@@ -31,6 +35,24 @@ func NodeID(h *mkeconfig.Host) (string, error) {
 	return out, nil
 }
 
+// LocalRole returns the swarm role of the node of the host, manager or worker, or an
+// empty string when the host is not part of a swarm.
+func LocalRole(h *mkeconfig.Host) (string, error) {
+	out, err := h.ExecOutput(h.Configurer.DockerCommandf(`info --format "{{.Swarm.NodeID}} {{.Swarm.ControlAvailable}}"`))
+	if err != nil {
+		return "", fmt.Errorf("failed to get host's swarm role: %w", err)
+	}
+	fields := strings.Fields(out)
+	switch {
+	case len(fields) != 2:
+		return "", nil
+	case fields[1] == "true":
+		return "manager", nil
+	default:
+		return "worker", nil
+	}
+}
+
 // ClusterID digs the swarm cluster id from swarm leader host.
 func ClusterID(h *mkeconfig.Host) string {
 	output, err := h.ExecOutput(h.Configurer.DockerCommandf(`info --format "{{ .Swarm.Cluster.ID}}"`))
@@ -41,3 +63,94 @@ func ClusterID(h *mkeconfig.Host) string {
 
 	return output
 }
+
+// Node is the part of the docker node inspect output launchpad uses.
+type Node struct {
+	ID   string `json:"ID"`
+	Spec struct {
+		Role         string            `json:"Role"`
+		Availability string            `json:"Availability"`
+		Labels       map[string]string `json:"Labels"`
+	} `json:"Spec"`
+	Description struct {
+		Hostname string `json:"Hostname"`
+		Platform struct {
+			OS string `json:"OS"`
+		} `json:"Platform"`
+		Engine struct {
+			EngineVersion string `json:"EngineVersion"`
+		} `json:"Engine"`
+	} `json:"Description"`
+	Status struct {
+		State string `json:"State"`
+		Addr  string `json:"Addr"`
+	} `json:"Status"`
+	ManagerStatus *struct {
+		Leader       bool   `json:"Leader"`
+		Reachability string `json:"Reachability"`
+		Addr         string `json:"Addr"`
+	} `json:"ManagerStatus"`
+}
+
+// Address returns the address of the node. The status address of a manager can be
+// 0.0.0.0, so the address of its manager status is used instead.
+func (n Node) Address() string {
+	if n.ManagerStatus != nil {
+		if host, _, err := net.SplitHostPort(n.ManagerStatus.Addr); err == nil {
+			return host
+		}
+	}
+	return n.Status.Addr
+}
+
+// Nodes inspects all the nodes of the swarm through a manager.
+func Nodes(manager *mkeconfig.Host) ([]Node, error) {
+	ids, err := manager.ExecOutput(manager.Configurer.DockerCommandf("node ls -q"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list the swarm nodes: %w", manager, err)
+	}
+	output, err := manager.ExecOutput(manager.Configurer.DockerCommandf("node inspect %s", strings.Join(strings.Fields(ids), " ")))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to inspect the swarm nodes: %w", manager, err)
+	}
+	var nodes []Node
+	if err := json.Unmarshal([]byte(output), &nodes); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse the swarm nodes: %w", manager, err)
+	}
+	return nodes, nil
+}
+
+var errManagersUnhealthy = errors.New("swarm managers are not healthy")
+
+// CheckManagers returns an error unless every manager of the swarm is reachable and
+// one of them is the raft leader.
+func CheckManagers(manager *mkeconfig.Host) error {
+	nodes, err := Nodes(manager)
+	if err != nil {
+		return err
+	}
+	return checkManagers(nodes)
+}
+
+func checkManagers(nodes []Node) error {
+	leader := false
+	var unreachable []string
+	for _, n := range nodes {
+		if n.ManagerStatus == nil {
+			continue
+		}
+		if n.ManagerStatus.Leader {
+			leader = true
+		}
+		if n.ManagerStatus.Reachability != "reachable" {
+			unreachable = append(unreachable, fmt.Sprintf("%s (%s)", n.Description.Hostname, n.ManagerStatus.Reachability))
+		}
+	}
+	if len(unreachable) > 0 {
+		return fmt.Errorf("%w: managers not reachable: %s", errManagersUnhealthy, strings.Join(unreachable, ", "))
+	}
+	if !leader {
+		return fmt.Errorf("%w: no raft leader", errManagersUnhealthy)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package swarm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckManagers(t *testing.T) {
+	var nodes []Node
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"ID": "a", "Description": {"Hostname": "manager-1"}, "ManagerStatus": {"Leader": true, "Reachability": "reachable", "Addr": "10.0.0.1:2377"}},
+		{"ID": "b", "Description": {"Hostname": "manager-2"}, "ManagerStatus": {"Reachability": "reachable", "Addr": "10.0.0.2:2377"}},
+		{"ID": "c", "Description": {"Hostname": "worker-1"}, "Status": {"Addr": "10.0.0.3"}}
+	]`), &nodes))
+	require.NoError(t, checkManagers(nodes))
+	require.Equal(t, "10.0.0.2", nodes[1].Address())
+	require.Equal(t, "10.0.0.3", nodes[2].Address())
+
+	nodes[1].ManagerStatus.Reachability = "unreachable"
+	require.ErrorIs(t, checkManagers(nodes), errManagersUnhealthy)
+	require.ErrorContains(t, checkManagers(nodes), "manager-2 (unreachable)")
+
+	nodes[1].ManagerStatus.Reachability = "reachable"
+	nodes[0].ManagerStatus.Leader = false
+	require.ErrorContains(t, checkManagers(nodes), "no raft leader")
+}
@@ -0,0 +1,119 @@
+// Package tracing records OpenTelemetry spans for launchpad runs. Tracing is disabled
+// unless Init is called with an OTLP endpoint or an output file.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	mcclog "github.com/Mirantis/launchpad/pkg/log"
+	"github.com/Mirantis/launchpad/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Mirantis/launchpad"
+
+// Options configure where the spans are exported to.
+type Options struct {
+	// Endpoint is the URL of an OTLP/HTTP collector, for example http://localhost:4318.
+	Endpoint string
+	// Writer, when set, receives the spans as JSON, one span per line.
+	Writer io.Writer
+}
+
+var errNoExporter = errors.New("no trace exporter configured")
+
+// Init sets up the span exporters. The returned function flushes the spans that have
+// not been exported yet and must be called before exiting.
+func Init(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	var providerOpts []sdktrace.TracerProviderOption
+
+	if opts.Endpoint != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	if opts.Writer != nil {
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(opts.Writer))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create file trace exporter: %w", err)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(exporter))
+	}
+
+	if len(providerOpts) == 0 {
+		return nil, errNoExporter
+	}
+
+	res := resource.NewSchemaless(
+		semconv.ServiceName("launchpad"),
+		semconv.ServiceVersion(version.Version),
+	)
+	provider := sdktrace.NewTracerProvider(append(providerOpts, sdktrace.WithResource(res))...)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		if err := provider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to flush traces: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// Start starts a span. The span must be finished with End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) { //nolint:ireturn
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...)) //nolint:spancheck
+}
+
+// End records the error, if any, to the span and finishes it. The registered secrets
+// are redacted from the error.
+func End(span trace.Span, err error) {
+	if err != nil {
+		msg := mcclog.Redact(err.Error())
+		span.RecordError(errors.New(msg)) //nolint:err113 // the redacted copy of err
+		span.SetStatus(codes.Error, msg)
+	}
+	span.End()
+}
+
+// Attributes converts a property map, such as the analytics properties of a phase, to
+// span attributes, with the registered secrets redacted from the strings.
+func Attributes(props map[string]interface{}) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(props))
+	for k, v := range props {
+		switch val := v.(type) {
+		case string:
+			attrs = append(attrs, attribute.String(k, mcclog.Redact(val)))
+		case bool:
+			attrs = append(attrs, attribute.Bool(k, val))
+		case int:
+			attrs = append(attrs, attribute.Int(k, val))
+		case int64:
+			attrs = append(attrs, attribute.Int64(k, val))
+		case float64:
+			attrs = append(attrs, attribute.Float64(k, val))
+		case []string:
+			redacted := make([]string, len(val))
+			for i, s := range val {
+				redacted[i] = mcclog.Redact(s)
+			}
+			attrs = append(attrs, attribute.StringSlice(k, redacted))
+		default:
+			attrs = append(attrs, attribute.String(k, mcclog.Redact(fmt.Sprint(val))))
+		}
+	}
+	return attrs
+}
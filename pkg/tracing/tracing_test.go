@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	mcclog "github.com/Mirantis/launchpad/pkg/log"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type exportedSpan struct {
+	Name        string
+	SpanContext struct{ SpanID string }
+	Parent      struct{ SpanID string }
+	Status      struct{ Code string }
+	Attributes  []struct{ Key string }
+}
+
+func TestInitFile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	shutdown, err := Init(context.Background(), Options{Writer: buf})
+	require.NoError(t, err)
+
+	ctx, run := Start(context.Background(), "apply")
+	_, phase := Start(ctx, "phase", Attributes(map[string]interface{}{"phase": "InstallMCR", "hosts": 3})...)
+	End(phase, errors.New("boom"))
+	End(run, nil)
+	require.NoError(t, shutdown(context.Background()))
+
+	var spans []exportedSpan
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var s exportedSpan
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &s))
+		spans = append(spans, s)
+	}
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "phase", spans[0].Name)
+	require.Equal(t, "Error", spans[0].Status.Code)
+	require.Len(t, spans[0].Attributes, 2)
+	require.Equal(t, "apply", spans[1].Name)
+	require.Equal(t, spans[1].SpanContext.SpanID, spans[0].Parent.SpanID)
+}
+
+func TestInitNoExporter(t *testing.T) {
+	_, err := Init(context.Background(), Options{})
+	require.ErrorIs(t, err, errNoExporter)
+}
+
+func TestAttributes(t *testing.T) {
+	attrs := Attributes(map[string]interface{}{
+		"name":  "x",
+		"count": 2,
+		"ok":    true,
+		"other": struct{}{},
+	})
+	require.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("name", "x"),
+		attribute.Int("count", 2),
+		attribute.Bool("ok", true),
+		attribute.String("other", "{}"),
+	}, attrs)
+}
+
+func TestEndRedacts(t *testing.T) {
+	mcclog.RegisterSecret("span-s3cr3t")
+	buf := &bytes.Buffer{}
+	shutdown, err := Init(context.Background(), Options{Writer: buf})
+	require.NoError(t, err)
+
+	_, span := Start(context.Background(), "phase", Attributes(map[string]interface{}{"command": "login -p span-s3cr3t"})...)
+	End(span, errors.New("login -p span-s3cr3t failed"))
+	require.NoError(t, shutdown(context.Background()))
+
+	require.NotContains(t, buf.String(), "span-s3cr3t")
+	require.Contains(t, buf.String(), "[REDACTED]")
+}
@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/Mirantis/launchpad/pkg/constant"
 	"github.com/Mirantis/launchpad/pkg/mke"
+	lpproduct "github.com/Mirantis/launchpad/pkg/product"
 	"github.com/Mirantis/launchpad/test"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/mitchellh/go-homedir"
@@ -84,7 +86,7 @@ func TestMain(m *testing.M) {
 	sp.Setup(t, options)
 
 	// Do Launchpad Apply as pre-requisite to the tests
-	err := sp.Product.Apply(true, true, 3, true)
+	err := sp.Product.Apply(context.Background(), lpproduct.ApplyOptions{DisableCleanup: true, Force: true, Concurrency: 3, ForceUpgrade: true})
 	assert.NoError(t, err)
 
 	// Run tests in order
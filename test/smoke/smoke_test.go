@@ -1,6 +1,7 @@
 package smoke_test
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/Mirantis/launchpad/pkg/config"
+	lpproduct "github.com/Mirantis/launchpad/pkg/product"
 	"github.com/Mirantis/launchpad/test"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -156,7 +158,7 @@ func runSmokeTest(t *testing.T, cfg smokeConfig) {
 	product, err := config.ProductFromYAML([]byte(mkeClusterConfig))
 	assert.NoError(t, err)
 
-	err = product.Apply(true, true, 3, true)
+	err = product.Apply(context.Background(), lpproduct.ApplyOptions{DisableCleanup: true, Force: true, Concurrency: 3, ForceUpgrade: true})
 	assert.NoError(t, err)
 
 	// Reset is best-effort: the mirantis/ucp uninstall-ucp container has an
@@ -165,7 +167,7 @@ func runSmokeTest(t *testing.T, cfg smokeConfig) {
 	// Infrastructure is destroyed unconditionally by defer terraform.Destroy
 	// above, so a Reset failure does not leave orphaned AWS resources.
 	// Log the failure but do not fail the test on Reset errors.
-	if err = product.Reset(); err != nil {
+	if err = product.Reset(context.Background(), lpproduct.ResetOptions{}); err != nil {
 		t.Logf("WARN: product.Reset() failed (non-fatal): %v", err)
 	}
 }
@@ -29,10 +29,12 @@ package smoke_test
 // running 3.8 that need to reach 3.9).
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/Mirantis/launchpad/pkg/config"
+	lpproduct "github.com/Mirantis/launchpad/pkg/product"
 	"github.com/Mirantis/launchpad/test"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -121,7 +123,7 @@ func runUpgradeTest(t *testing.T, cfg upgradeConfig) {
 	baseProduct, err := config.ProductFromYAML([]byte(baseYAML))
 	require.NoError(t, err, "parse base launchpad YAML")
 
-	err = baseProduct.Apply(true, true, 3, true)
+	err = baseProduct.Apply(context.Background(), lpproduct.ApplyOptions{DisableCleanup: true, Force: true, Concurrency: 3, ForceUpgrade: true})
 	require.NoError(t, err, "base install Apply()")
 
 	// ── Step 2: build upgrade YAML ────────────────────────────────────────────
@@ -137,12 +139,12 @@ func runUpgradeTest(t *testing.T, cfg upgradeConfig) {
 	upgradeProduct, err := config.ProductFromYAML([]byte(upgradeYAML))
 	require.NoError(t, err, "parse upgrade launchpad YAML")
 
-	err = upgradeProduct.Apply(true, true, 3, true)
+	err = upgradeProduct.Apply(context.Background(), lpproduct.ApplyOptions{DisableCleanup: true, Force: true, Concurrency: 3, ForceUpgrade: true})
 	assert.NoError(t, err, "upgrade Apply()")
 
 	// ── Step 4: reset (best-effort) ───────────────────────────────────────────
 	// See smoke_test.go for rationale on non-fatal Reset().
-	if err = upgradeProduct.Reset(); err != nil {
+	if err = upgradeProduct.Reset(context.Background(), lpproduct.ResetOptions{}); err != nil {
 		t.Logf("WARN: product.Reset() failed (non-fatal): %v", err)
 	}
 }